@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/cassiot"
+	"github.com/scylladb/gocqlx/v2"
+)
+
+// Copies the readings of the Cassandra device layout into the day layout of 0006_daily_readings.up.cql,
+// device by device. The copy overwrites its own rows, so it can be run again after a failure or to
+// catch up with the readings stored meanwhile, before switching CASSANDRA_READINGS_LAYOUT to day.
+// -from legacy copies the readings of the sensor_readings table of 0001_data.up.cql, stored before
+// 0007_reading_message_id.up.cql, into the layout of -to.
+func main() {
+	from := flag.String("from", string(cassiot.LayoutDevice), "layout to copy the readings from, or legacy")
+	to := flag.String("to", string(cassiot.LayoutDay), "layout to copy the readings to")
+	batchSize := flag.Int("batch", 100, "readings written per batch")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	src, dst := cassiot.Layout(*from), cassiot.Layout(*to)
+	if !(src.Valid() || src == cassiot.LayoutLegacy) || !dst.Valid() {
+		log.Fatalf("[Cassandra Migrate] Unknown layout, expected %s or %s", cassiot.LayoutDevice, cassiot.LayoutDay)
+	}
+
+	session, err := repository.NewCassandraSession(repository.FromEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer session.Close()
+
+	var devices, readings int
+	progress := func(deviceID string, copied int) {
+		devices++
+		readings += copied
+		log.Printf("[Cassandra Migrate] Copied %d readings of device %s", copied, deviceID)
+	}
+	if src == cassiot.LayoutLegacy {
+		err = cassiot.CopyLegacyReadings(ctx, gocqlx.NewSession(session), dst, *batchSize, progress)
+	} else {
+		err = cassiot.CopyReadings(ctx, gocqlx.NewSession(session), src, dst, *batchSize, progress)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("[Cassandra Migrate] Copied %d readings of %d devices from the %s layout to the %s layout", readings, devices, src, dst)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/controller/dlq"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+const usage = `Inspect and replay the dead letter topic (IOT_DLQ_TOPIC) to IOT_TOPIC.
+
+Usage:
+  dlq list   [filters] [-payload]
+  dlq replay [filters] [-all] [-transform cmd] [-topic name]
+
+Filters:
+  -error     case insensitive substring of the error
+  -device    device id of the payload
+  -since     RFC3339 time the message was dead lettered at or after
+  -until     RFC3339 time the message was dead lettered before
+  -select    comma separated partition:offset of the dead letter topic, e.g. 0:12,0:15
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	cmd, args := os.Args[1], os.Args[2:]
+
+	var err error
+	switch cmd {
+	case "list":
+		err = runList(ctx, args)
+	case "replay":
+		err = runReplay(ctx, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	filter := filterFlags(fs)
+	withPayload := fs.Bool("payload", false, "print the payload of each message")
+	fs.Parse(args)
+
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+
+	ctrl, cleanup, err := initController(ctx, "")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	msgs, err := ctrl.List(ctx, f)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "POSITION\tDEAD LETTERED AT\tSOURCE\tATTEMPTS\tDEVICE\tERROR")
+	for _, m := range msgs {
+		fmt.Fprintf(w, "%d:%d\t%s\t%s[%d]@%d\t%d\t%s\t%s\n",
+			m.ID.Partition, m.ID.Offset, m.Timestamp.Format(time.RFC3339),
+			m.SourceTopic, m.SourcePartition, m.SourceOffset, m.Attempts, m.DeviceID, m.Error)
+		if *withPayload {
+			fmt.Fprintf(w, "\t%s\n", m.Value)
+		}
+	}
+	w.Flush()
+	log.Printf("%d dead lettered messages", len(msgs))
+
+	return nil
+}
+
+func runReplay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	filter := filterFlags(fs)
+	all := fs.Bool("all", false, "replay every message, required when no filter is given")
+	transformCmd := fs.String("transform", "", "shell command fixing up each payload from stdin to stdout, an empty output skips the message")
+	topic := fs.String("topic", os.Getenv("IOT_TOPIC"), "topic to replay to")
+	fs.Parse(args)
+
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+	if f.IsEmpty() && !*all {
+		return fmt.Errorf("no filter given, use -all to replay every message")
+	}
+	if *topic == "" {
+		return fmt.Errorf("no topic to replay to, set IOT_TOPIC or -topic")
+	}
+
+	var transform dlq.Transform
+	if *transformCmd != "" {
+		transform = commandTransform(*transformCmd)
+	}
+
+	ctrl, cleanup, err := initController(ctx, *topic)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	res, err := ctrl.Replay(ctx, f, transform)
+	log.Printf("Replayed %d messages to %s, skipped %d", res.Replayed, *topic, res.Skipped)
+	return err
+}
+
+// filterFlags registers the filter flags and returns a func building the filter once parsed
+func filterFlags(fs *flag.FlagSet) func() (dlq.Filter, error) {
+	errSubstr := fs.String("error", "", "case insensitive substring of the error")
+	deviceID := fs.String("device", "", "device id of the payload")
+	since := fs.String("since", "", "RFC3339 time the message was dead lettered at or after")
+	until := fs.String("until", "", "RFC3339 time the message was dead lettered before")
+	selected := fs.String("select", "", "comma separated partition:offset of the dead letter topic")
+
+	return func() (dlq.Filter, error) {
+		f := dlq.Filter{
+			Error:    *errSubstr,
+			DeviceID: *deviceID,
+		}
+
+		var err error
+		if *since != "" {
+			if f.Since, err = time.Parse(time.RFC3339, *since); err != nil {
+				return dlq.Filter{}, fmt.Errorf("invalid -since: %w", err)
+			}
+		}
+		if *until != "" {
+			if f.Until, err = time.Parse(time.RFC3339, *until); err != nil {
+				return dlq.Filter{}, fmt.Errorf("invalid -until: %w", err)
+			}
+		}
+		if *selected != "" {
+			if f.Positions, err = parsePositions(*selected); err != nil {
+				return dlq.Filter{}, err
+			}
+		}
+
+		return f, nil
+	}
+}
+
+func parsePositions(v string) ([]dlq.Position, error) {
+	var res []dlq.Position
+	for _, s := range strings.Split(v, ",") {
+		partition, offset, ok := strings.Cut(strings.TrimSpace(s), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid -select %q, expected partition:offset", s)
+		}
+		p, err := strconv.ParseInt(partition, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -select partition %q: %w", partition, err)
+		}
+		o, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -select offset %q: %w", offset, err)
+		}
+		res = append(res, dlq.Position{Partition: int32(p), Offset: o})
+	}
+	return res, nil
+}
+
+// initController connects to kafka, the producer is only needed to replay to targetTopic
+func initController(ctx context.Context, targetTopic string) (dlq.Controller, func(), error) {
+	kafkaCfg, err := kafka.ClientConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := kafka.NewTopicReader(ctx, os.Getenv("IOT_DLQ_TOPIC"), kafkaCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var producer *kafka.SyncProducer
+	if targetTopic != "" {
+		if producer, err = kafka.NewSyncProducer(ctx, kafkaCfg); err != nil {
+			reader.Close()
+			return nil, nil, err
+		}
+	}
+
+	cleanup := func() {
+		if producer != nil {
+			producer.Close()
+		}
+		reader.Close()
+	}
+
+	return dlq.New(reader, producer, targetTopic), cleanup, nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/nhan1603/IoTsystem/api/internal/controller/dlq"
+)
+
+// commandTransform pipes each payload through a shell command, e.g. `jq -c '.floor = 1'`
+func commandTransform(command string) dlq.Transform {
+	return func(ctx context.Context, payload []byte) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("transform %q failed: %w: %s", command, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return bytes.TrimSpace(stdout.Bytes()), nil
+	}
+}
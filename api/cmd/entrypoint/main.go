@@ -2,13 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
 	"github.com/nhan1603/IoTsystem/api/internal/appconfig/iam"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/alert"
 	"github.com/nhan1603/IoTsystem/api/internal/controller/auth"
 	"github.com/nhan1603/IoTsystem/api/internal/controller/iot"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/topology"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/env"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/obsmetrics"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/telemetry"
 	"github.com/nhan1603/IoTsystem/api/internal/repository"
 	"github.com/nhan1603/IoTsystem/api/internal/repository/generator"
 )
@@ -46,7 +57,7 @@ func main() {
 	}
 
 	// Initial router
-	rtr, err := initRouter(ctx, repo)
+	rtr, err := initRouter(ctx, repo, promMetrics)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -61,11 +72,136 @@ func main() {
 func initRouter(
 	ctx context.Context,
 	repo repository.Registry,
+	promMetrics *obsmetrics.Metrics,
 ) (router, error) {
+	kafkaCfg, err := kafka.ClientConfigFromEnv()
+	if err != nil {
+		return router{}, err
+	}
+
+	if err := provisionTopics(ctx, kafkaCfg); err != nil {
+		return router{}, err
+	}
+
+	deadLetter, err := initDeadLetter(ctx, kafkaCfg)
+	if err != nil {
+		return router{}, err
+	}
+
+	var offsetStore kafka.OffsetStore
+	if env.GetwithDefault("KAFKA_OFFSET_STORE", "kafka") == iot.OffsetStoreDB {
+		if repo.Offset() == nil {
+			return router{}, errors.New("KAFKA_OFFSET_STORE=db requires the postgres or memory backend")
+		}
+		offsetStore = repo.Offset()
+	}
+
+	lagInterval, err := time.ParseDuration(env.GetwithDefault("KAFKA_LAG_INTERVAL", "15s"))
+	if err != nil {
+		return router{}, fmt.Errorf("invalid KAFKA_LAG_INTERVAL: %w", err)
+	}
+	lagTracker := kafka.NewLagTracker(lagInterval, func(l kafka.PartitionLag) {
+		promMetrics.SetKafkaConsumerLag(iot.ConsumerGroup, l.Topic, l.Partition, l.Lag)
+	})
+	onRebalance := func(e kafka.RebalanceEvent) {
+		promMetrics.RecordKafkaRebalance(e.GroupID)
+	}
+
+	retryPolicy, retryProducer, err := initRetry(ctx, kafkaCfg)
+	if err != nil {
+		return router{}, err
+	}
+
+	iotCtrl, err := iot.New(repo, promMetrics, deadLetter)
+	if err != nil {
+		return router{}, err
+	}
 
 	return router{
-		ctx:      ctx,
-		authCtrl: auth.New(repo, iam.ConfigFromContext(ctx)),
-		iotCtrol: iot.New(repo),
+		ctx:           ctx,
+		kafkaCfg:      kafkaCfg,
+		authCtrl:      auth.New(repo, iam.ConfigFromContext(ctx)),
+		iotCtrol:      iotCtrl,
+		deadLetter:    deadLetter,
+		offsetStore:   offsetStore,
+		lagTracker:    lagTracker,
+		onRebalance:   onRebalance,
+		logger:        telemetry.NewLogger(slog.LevelInfo),
+		runID:         telemetry.NewRunID(),
+		retryPolicy:   retryPolicy,
+		retryProducer: retryProducer,
+		topologyCtrl:  topology.New(repo),
+		alertCtrl:     alert.New(repo),
 	}, nil
 }
+
+// provisionTopics creates the missing topics of KAFKA_TOPICS_FILE, the topics are left as is when it is unset
+func provisionTopics(ctx context.Context, kafkaCfg kafka.ClientConfig) error {
+	path := os.Getenv("KAFKA_TOPICS_FILE")
+	if path == "" {
+		return nil
+	}
+
+	specs, err := kafka.LoadTopicSpecs(path)
+	if err != nil {
+		return err
+	}
+
+	admin, err := kafka.NewTopicAdmin(ctx, kafkaCfg)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	_, err = admin.Provision(ctx, specs)
+	return err
+}
+
+// initDeadLetter creates the dead letter publisher, poison messages are only logged when IOT_DLQ_TOPIC is unset
+func initDeadLetter(ctx context.Context, kafkaCfg kafka.ClientConfig) (kafka.DeadLetterPublisher, error) {
+	topic := os.Getenv("IOT_DLQ_TOPIC")
+	if topic == "" {
+		log.Println("IOT_DLQ_TOPIC is not set, poison messages will be dropped")
+		return nil, nil
+	}
+
+	producer, err := kafka.NewSyncProducer(ctx, kafkaCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return kafka.NewDeadLetterProducer(producer, topic)
+}
+
+// initRetry reads the retry policy, the producer of the retry topics is only created when RETRY_TOPICS is set
+func initRetry(ctx context.Context, kafkaCfg kafka.ClientConfig) (kafka.RetryPolicy, *kafka.SyncProducer, error) {
+	policy := kafka.DefaultRetryPolicy()
+	var err error
+	if policy.InPlaceRetries, err = strconv.Atoi(env.GetwithDefault("RETRY_IN_PLACE", strconv.Itoa(policy.InPlaceRetries))); err != nil {
+		return policy, nil, fmt.Errorf("invalid RETRY_IN_PLACE: %w", err)
+	}
+	if policy.InitialInterval, err = time.ParseDuration(env.GetwithDefault("RETRY_INITIAL_INTERVAL", policy.InitialInterval.String())); err != nil {
+		return policy, nil, fmt.Errorf("invalid RETRY_INITIAL_INTERVAL: %w", err)
+	}
+	if policy.MaxInterval, err = time.ParseDuration(env.GetwithDefault("RETRY_MAX_INTERVAL", policy.MaxInterval.String())); err != nil {
+		return policy, nil, fmt.Errorf("invalid RETRY_MAX_INTERVAL: %w", err)
+	}
+	if policy.MaxBisectAttempts, err = strconv.Atoi(env.GetwithDefault("RETRY_MAX_BISECT_ATTEMPTS", strconv.Itoa(policy.MaxBisectAttempts))); err != nil {
+		return policy, nil, fmt.Errorf("invalid RETRY_MAX_BISECT_ATTEMPTS: %w", err)
+	}
+	if policy.Tiers, err = kafka.ParseRetryTiers(os.Getenv("RETRY_TOPICS")); err != nil {
+		return policy, nil, fmt.Errorf("invalid RETRY_TOPICS: %w", err)
+	}
+	if len(policy.Tiers) == 0 {
+		return policy, nil, nil
+	}
+	if policy.MaxAttempts, err = strconv.Atoi(env.GetwithDefault("RETRY_MAX_ATTEMPTS", strconv.Itoa(len(policy.Tiers)+1))); err != nil {
+		return policy, nil, fmt.Errorf("invalid RETRY_MAX_ATTEMPTS: %w", err)
+	}
+
+	producer, err := kafka.NewSyncProducer(ctx, kafkaCfg)
+	if err != nil {
+		return policy, nil, err
+	}
+	return policy, producer, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
@@ -11,41 +12,123 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/nhan1603/IoTsystem/api/internal/appconfig/iam"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/alert"
 	"github.com/nhan1603/IoTsystem/api/internal/controller/auth"
 	"github.com/nhan1603/IoTsystem/api/internal/controller/iot"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/topology"
+	adminHandler "github.com/nhan1603/IoTsystem/api/internal/handler/rest/authenticated/v1/admin"
+	alertHandler "github.com/nhan1603/IoTsystem/api/internal/handler/rest/authenticated/v1/alert"
+	"github.com/nhan1603/IoTsystem/api/internal/handler/rest/authenticated/v1/operation"
+	topologyHandler "github.com/nhan1603/IoTsystem/api/internal/handler/rest/authenticated/v1/topology"
 	authHandler "github.com/nhan1603/IoTsystem/api/internal/handler/rest/public/v1/auth"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/env"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/obsmetrics"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/runner"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type router struct {
-	ctx      context.Context
-	authCtrl auth.Controller
-	iotCtrol iot.Controller
+	ctx           context.Context
+	kafkaCfg      kafka.ClientConfig
+	authCtrl      auth.Controller
+	iotCtrol      iot.Controller
+	topologyCtrl  topology.Controller
+	alertCtrl     alert.Controller
+	deadLetter    kafka.DeadLetterPublisher
+	offsetStore   kafka.OffsetStore
+	lagTracker    *kafka.LagTracker
+	onRebalance   func(kafka.RebalanceEvent)
+	logger        *slog.Logger
+	runID         string
+	retryPolicy   kafka.RetryPolicy
+	retryProducer *kafka.SyncProducer
 }
 
 func (rtr router) initKafkaConsumer() {
 	batchSize, _ := strconv.Atoi(env.GetwithDefault("BATCH_SIZE", "100"))
 	batchTimeout, _ := time.ParseDuration(env.GetwithDefault("BATCH_TIMEOUT", "5s"))
+	claimWorkers, _ := strconv.Atoi(env.GetwithDefault("CLAIM_WORKERS", "1"))
+	consumerOpts := []kafka.ConsumerOption{
+		kafka.WithLagTracker(rtr.lagTracker),
+		kafka.WithRebalanceObserver(rtr.onRebalance),
+		kafka.WithTelemetry(rtr.logger, rtr.runID),
+		kafka.WithClaimWorkers(claimWorkers),
+	}
+	if env.GetwithDefault("BATCH_ADAPTIVE", "false") == "true" {
+		sizer, err := initBatchSizer(batchSize)
+		if err != nil {
+			log.Printf("Error when init adaptive batch size, %v", err)
+			return
+		}
+		consumerOpts = append(consumerOpts, kafka.WithAdaptiveBatchSize(sizer))
+	} else {
+		obsmetrics.BatchSize.Set(float64(batchSize))
+	}
+	if rtr.deadLetter != nil {
+		consumerOpts = append(consumerOpts, kafka.WithDeadLetter(rtr.deadLetter))
+	}
+	if rtr.retryProducer != nil {
+		consumerOpts = append(consumerOpts, kafka.WithRetryPolicy(rtr.retryPolicy, kafka.NewRetryProducer(rtr.retryProducer)))
+	} else {
+		consumerOpts = append(consumerOpts, kafka.WithRetryPolicy(rtr.retryPolicy, nil))
+	}
+	if rtr.offsetStore != nil {
+		consumerOpts = append(consumerOpts, kafka.WithOffsetStore(rtr.offsetStore))
+	}
 	// Inital consumer kafka
 	consumer, err := kafka.NewBatchConsumer(
 		rtr.ctx,
 		os.Getenv("IOT_TOPIC"),
-		os.Getenv("KAFKA_BROKER"),
+		rtr.kafkaCfg,
 		rtr.iotCtrol.HandleBatch,
-		"iot",
+		iot.ConsumerGroup,
 		batchSize,
 		batchTimeout,
+		consumerOpts...,
 	)
 	if err != nil {
 		log.Printf("Error when init consumer, %v", err)
 		return
 	}
 
+	services := []func(context.Context) error{consumer.Consume}
+	if rtr.retryProducer != nil {
+		retryConsumer, err := kafka.NewRetryConsumer(
+			rtr.ctx,
+			rtr.kafkaCfg,
+			iot.RetryConsumerGroup,
+			rtr.retryPolicy.Tiers,
+			rtr.retryProducer,
+		)
+		if err != nil {
+			log.Printf("Error when init retry consumer, %v", err)
+			return
+		}
+		services = append(services, retryConsumer.Consume)
+	}
+
 	log.Printf("Kafka consumer start successfully\n")
-	go runner.ExecParallel(rtr.ctx, consumer.Consume)
+	go runner.ExecParallel(rtr.ctx, services...)
+}
+
+// initBatchSizer creates the adaptive batch sizer starting from BATCH_SIZE, the current size is exported as the batch size gauge
+func initBatchSizer(initial int) (*kafka.AdaptiveBatchSizer, error) {
+	minSize, _ := strconv.Atoi(env.GetwithDefault("BATCH_MIN_SIZE", "10"))
+	maxSize, _ := strconv.Atoi(env.GetwithDefault("BATCH_MAX_SIZE", "5000"))
+	target, err := time.ParseDuration(env.GetwithDefault("BATCH_TARGET_LATENCY", "500ms"))
+	if err != nil {
+		return nil, err
+	}
+
+	return kafka.NewAdaptiveBatchSizer(kafka.AdaptiveBatchConfig{
+		Initial:       initial,
+		Min:           minSize,
+		Max:           maxSize,
+		TargetLatency: target,
+	}, func(size int) {
+		obsmetrics.BatchSize.Set(float64(size))
+	})
 }
 
 func (rtr router) routes(r chi.Router) {
@@ -60,6 +143,51 @@ func (rtr router) authenticated(r chi.Router) {
 		r.Use(iam.AuthenticateUserMiddleware(rtr.ctx))
 		prefix = prefix + "/v1"
 
+		r.Group(func(r chi.Router) {
+			operationH := operation.New(rtr.iotCtrol)
+			r.Get(prefix+"/readings", operationH.GetReadings())
+			r.Get(prefix+"/readings/latest", operationH.GetLatestReadings())
+			r.Get(prefix+"/readings/aggregate", operationH.GetReadingAggregates())
+			r.Get(prefix+"/devices/{id}/readings", operationH.GetDeviceReadings())
+
+			r.Get(prefix+"/devices", operationH.GetDevices())
+			r.Post(prefix+"/devices", operationH.CreateDevice())
+			r.Get(prefix+"/devices/{id}", operationH.GetDevice())
+			r.Put(prefix+"/devices/{id}", operationH.UpdateDevice())
+			r.Post(prefix+"/devices/{id}/deactivate", operationH.DeactivateDevice())
+			r.Post(prefix+"/devices/{id}/reactivate", operationH.ReactivateDevice())
+		})
+
+		r.Group(func(r chi.Router) {
+			topologyH := topologyHandler.New(rtr.topologyCtrl)
+			r.Get(prefix+"/floors", topologyH.GetFloors())
+			r.Post(prefix+"/floors", topologyH.CreateFloor())
+			r.Get(prefix+"/floors/{floorID}", topologyH.GetFloor())
+			r.Put(prefix+"/floors/{floorID}", topologyH.UpdateFloor())
+
+			r.Get(prefix+"/floors/{floorID}/zones", topologyH.GetZones())
+			r.Post(prefix+"/floors/{floorID}/zones", topologyH.CreateZone())
+			r.Get(prefix+"/floors/{floorID}/zones/{zoneID}", topologyH.GetZone())
+			r.Put(prefix+"/floors/{floorID}/zones/{zoneID}", topologyH.UpdateZone())
+		})
+
+		r.Group(func(r chi.Router) {
+			alertH := alertHandler.New(rtr.alertCtrl)
+			r.Get(prefix+"/alert-rules", alertH.GetRules())
+			r.Post(prefix+"/alert-rules", alertH.CreateRule())
+			r.Get(prefix+"/alert-rules/{id}", alertH.GetRule())
+			r.Put(prefix+"/alert-rules/{id}", alertH.UpdateRule())
+
+			r.Get(prefix+"/alerts", alertH.GetAlerts())
+			r.Get(prefix+"/alerts/{id}", alertH.GetAlert())
+			r.Post(prefix+"/alerts/{id}/acknowledge", alertH.AcknowledgeAlert())
+			r.Post(prefix+"/alerts/{id}/resolve", alertH.ResolveAlert())
+		})
+
+		r.Group(func(r chi.Router) {
+			adminH := adminHandler.New(rtr.lagTracker)
+			r.Get(prefix+"/admin/consumer-lag", adminH.GetConsumerLag())
+		})
 	})
 }
 
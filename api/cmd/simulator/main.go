@@ -2,17 +2,28 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/nhan1603/IoTsystem/api/internal/controller/simulator"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/env"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/obsmetrics"
 	"github.com/nhan1603/IoTsystem/api/internal/repository"
 )
 
 func main() {
 	ctx := context.Background()
 
+	// Initialize Prometheus metrics
+	promMetrics := obsmetrics.NewMetrics("iotsimulator")
+	if err := promMetrics.StartMetricsServer(ctx, env.GetwithDefault("METRICS_ADDR", ":9092")); err != nil {
+		log.Printf("Failed to start metrics server: %v", err)
+	}
+
 	// Initial DB connection
 	cfg := repository.FromEnv()
 	repo, cleanup, err := repository.NewFromConfig(ctx, cfg)
@@ -24,7 +35,7 @@ func main() {
 	log.Println("Connected to database successfully")
 
 	// Initial producer kafka
-	producer, err := kafka.NewSyncProducer(ctx, os.Getenv("KAFKA_BROKER"))
+	send, closeProducer, err := initSender(ctx, promMetrics)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -34,11 +45,105 @@ func main() {
 	// Initial Simulate
 	ctrl := simulator.New(
 		repo,
-		producer,
+		send,
 		os.Getenv("IOT_TOPIC"),
 	)
 	ctrl.Simulate(ctx)
 
 	log.Println("[Simulator] Simulation completed, cleaning up...")
-	producer.Close()
+	if err := closeProducer(); err != nil {
+		log.Printf("[Simulator] Closing producer failed: %v", err)
+	}
+}
+
+// initSender creates the producer picked by PRODUCER_MODE, sync by default, both record the produced messages and errors
+func initSender(ctx context.Context, promMetrics *obsmetrics.Metrics) (simulator.Sender, func() error, error) {
+	kafkaCfg, err := kafka.ClientConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	partitioner, err := partitionerFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch mode := env.GetwithDefault("PRODUCER_MODE", "sync"); mode {
+	case "sync":
+		producer, err := kafka.NewSyncProducer(ctx, kafkaCfg, partitioner)
+		if err != nil {
+			return nil, nil, err
+		}
+		send := func(ctx context.Context, topic string, payload []byte, opt kafka.ProducerMessageOption) error {
+			if _, _, err := producer.SendMessage(ctx, topic, payload, opt); err != nil {
+				promMetrics.RecordKafkaProducerError()
+				return err
+			}
+			promMetrics.RecordKafkaMessageProduced()
+			return nil
+		}
+		return send, producer.Close, nil
+
+	case "async":
+		asyncCfg, err := asyncProducerConfigFromEnv()
+		if err != nil {
+			return nil, nil, err
+		}
+		producer, err := kafka.NewAsyncProducer(ctx, kafkaCfg, asyncCfg, func(r kafka.DeliveryReport) {
+			if r.Err != nil {
+				log.Printf("[Simulator] Error delivering message to %s: %v", r.Topic, r.Err)
+				promMetrics.RecordKafkaProducerError()
+				return
+			}
+			promMetrics.RecordKafkaMessageProduced()
+		}, partitioner)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("[Simulator] Async producer with %+v", asyncCfg)
+		return producer.SendMessage, producer.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("invalid PRODUCER_MODE %q, expected sync or async", mode)
+	}
+}
+
+// partitionerFromEnv reads PRODUCER_PARTITIONER, hash by default. The consistent one groups the devices by zone
+// and the explicit one reads the key=partition list of PRODUCER_PARTITION_ASSIGNMENTS.
+func partitionerFromEnv() (kafka.ProducerOption, error) {
+	strategy := env.GetwithDefault("PRODUCER_PARTITIONER", kafka.PartitionerHash)
+	switch strategy {
+	case kafka.PartitionerHash, kafka.PartitionerConsistentHash:
+		return kafka.WithPartitioner(strategy, nil), nil
+	case kafka.PartitionerExplicit:
+		assignments, err := kafka.ParsePartitionAssignments(os.Getenv("PRODUCER_PARTITION_ASSIGNMENTS"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PRODUCER_PARTITION_ASSIGNMENTS: %w", err)
+		}
+		return kafka.WithPartitioner(strategy, assignments), nil
+	default:
+		return nil, fmt.Errorf("invalid PRODUCER_PARTITIONER %q, expected hash, consistent or explicit", strategy)
+	}
+}
+
+func asyncProducerConfigFromEnv() (kafka.AsyncProducerConfig, error) {
+	linger, err := time.ParseDuration(env.GetwithDefault("PRODUCER_LINGER", "10ms"))
+	if err != nil {
+		return kafka.AsyncProducerConfig{}, fmt.Errorf("invalid PRODUCER_LINGER: %w", err)
+	}
+	batchBytes, err := strconv.Atoi(env.GetwithDefault("PRODUCER_BATCH_BYTES", "65536"))
+	if err != nil {
+		return kafka.AsyncProducerConfig{}, fmt.Errorf("invalid PRODUCER_BATCH_BYTES: %w", err)
+	}
+	idempotent, err := strconv.ParseBool(env.GetwithDefault("PRODUCER_IDEMPOTENT", "false"))
+	if err != nil {
+		return kafka.AsyncProducerConfig{}, fmt.Errorf("invalid PRODUCER_IDEMPOTENT: %w", err)
+	}
+
+	return kafka.AsyncProducerConfig{
+		Linger:      linger,
+		BatchBytes:  batchBytes,
+		Compression: env.GetwithDefault("PRODUCER_COMPRESSION", "lz4"),
+		Idempotent:  idempotent,
+		Acks:        os.Getenv("PRODUCER_ACKS"),
+	}, nil
 }
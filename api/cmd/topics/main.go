@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/env"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+// Provisions the topics of KAFKA_TOPICS_FILE, with -check it only reports the missing and drifted topics and
+// exits with 1 when the cluster doesn't match the specs.
+func main() {
+	check := flag.Bool("check", false, "only compare the topics with their specs")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	specs, err := kafka.LoadTopicSpecs(env.GetwithDefault("KAFKA_TOPICS_FILE", "data/kafka/topics.yaml"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	kafkaCfg, err := kafka.ClientConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	admin, err := kafka.NewTopicAdmin(ctx, kafkaCfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer admin.Close()
+
+	if !*check {
+		if _, err := admin.Provision(ctx, specs); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	report, err := admin.Check(ctx, specs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, name := range report.Missing {
+		log.Printf("[Kafka Topics] Topic %s is missing", name)
+	}
+	if !report.InSync() {
+		admin.Close()
+		os.Exit(1)
+	}
+	log.Println("[Kafka Topics] Every topic matches its spec")
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_alerts_device_id;
+DROP INDEX IF EXISTS idx_alerts_status;
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS alert_rules;
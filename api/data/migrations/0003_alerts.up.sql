@@ -0,0 +1,35 @@
+-- Threshold rules evaluated against every ingested batch.
+-- An empty device_id / device_type and a NULL floor_id / zone_id match any device.
+CREATE TABLE alert_rules (
+    id BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    device_id VARCHAR(255) NOT NULL DEFAULT '',
+    device_type VARCHAR(50) NOT NULL DEFAULT '',
+    floor_id INTEGER REFERENCES floors(id),
+    zone_id INTEGER REFERENCES zones(id),
+    metric VARCHAR(20) NOT NULL,
+    operator VARCHAR(5) NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE alerts (
+    id BIGINT PRIMARY KEY,
+    rule_id BIGINT NOT NULL REFERENCES alert_rules(id),
+    device_id VARCHAR(255) NOT NULL,
+    metric VARCHAR(20) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    triggered_at TIMESTAMP NOT NULL,
+    acknowledged_at TIMESTAMP,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_alerts_status ON alerts(status);
+CREATE INDEX idx_alerts_device_id ON alerts(device_id);
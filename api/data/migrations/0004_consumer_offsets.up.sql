@@ -0,0 +1,10 @@
+-- Kafka offsets written in the same transaction as the readings they were consumed from,
+-- the consumer resumes from them on partition assignment.
+CREATE TABLE consumer_offsets (
+    group_id VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    partition INTEGER NOT NULL,
+    next_offset BIGINT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, topic, partition)
+);
@@ -0,0 +1,8 @@
+-- Readings of a device sharing a timestamp are allowed since the up migration, only the first stored is kept
+DELETE FROM sensor_readings a
+    USING sensor_readings b
+    WHERE a.device_id = b.device_id AND a."timestamp" = b."timestamp" AND a.id > b.id;
+
+ALTER TABLE sensor_readings DROP CONSTRAINT IF EXISTS uq_message_ts;
+ALTER TABLE sensor_readings ADD CONSTRAINT uq_device_ts UNIQUE (device_id, "timestamp");
+ALTER TABLE sensor_readings DROP COLUMN IF EXISTS message_id;
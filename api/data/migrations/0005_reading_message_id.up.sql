@@ -0,0 +1,9 @@
+-- Readings are deduplicated on the id of the message they came from instead of (device_id, timestamp),
+-- so distinct readings of a device sharing a timestamp are all kept with their original timestamp.
+ALTER TABLE sensor_readings ADD COLUMN message_id VARCHAR(255);
+UPDATE sensor_readings SET message_id = 'legacy:' || id WHERE message_id IS NULL;
+ALTER TABLE sensor_readings ALTER COLUMN message_id SET NOT NULL;
+
+ALTER TABLE sensor_readings DROP CONSTRAINT uq_device_ts;
+-- Unique constraints of a hypertable must include its time column, redeliveries keep the timestamp
+ALTER TABLE sensor_readings ADD CONSTRAINT uq_message_ts UNIQUE (message_id, "timestamp");
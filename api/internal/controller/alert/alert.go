@@ -0,0 +1,71 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+)
+
+// GetAlerts retrieves the alerts matching the filters, newest first
+func (i impl) GetAlerts(ctx context.Context, input model.GetAlertsInput) ([]model.Alert, error) {
+	alerts, err := i.repo.Alert().GetAlerts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// GetAlert retrieves an alert by id
+func (i impl) GetAlert(ctx context.Context, id int64) (model.Alert, error) {
+	a, err := i.repo.Alert().GetAlert(ctx, id)
+	if err != nil {
+		if errors.Is(err, alert.ErrNotFound) {
+			return model.Alert{}, ErrAlertNotFound
+		}
+		return model.Alert{}, fmt.Errorf("failed to get alert %d: %w", id, err)
+	}
+	return a, nil
+}
+
+// AcknowledgeAlert marks an open alert as being looked at
+func (i impl) AcknowledgeAlert(ctx context.Context, id int64) (model.Alert, error) {
+	return i.transition(ctx, id, model.AlertStatusAcknowledged)
+}
+
+// ResolveAlert closes an open or acknowledged alert by hand
+func (i impl) ResolveAlert(ctx context.Context, id int64) (model.Alert, error) {
+	return i.transition(ctx, id, model.AlertStatusResolved)
+}
+
+// transition moves the alert to status: open -> acknowledged -> resolved, or open -> resolved
+func (i impl) transition(ctx context.Context, id int64, status model.AlertStatus) (model.Alert, error) {
+	a, err := i.GetAlert(ctx, id)
+	if err != nil {
+		return model.Alert{}, err
+	}
+
+	now := time.Now()
+	switch {
+	case status == model.AlertStatusAcknowledged && a.Status == model.AlertStatusOpen:
+		a.AcknowledgedAt = now
+	case status == model.AlertStatusResolved && a.Status != model.AlertStatusResolved:
+		a.ResolvedAt = now
+	default:
+		return model.Alert{}, ErrInvalidTransition
+	}
+	a.Status = status
+
+	if err := i.repo.Alert().UpdateAlertStatus(ctx, a); err != nil {
+		if errors.Is(err, alert.ErrNotFound) {
+			return model.Alert{}, ErrAlertNotFound
+		}
+		return model.Alert{}, fmt.Errorf("failed to update alert %d to %s: %w", id, status, err)
+	}
+	a.UpdatedAt = now
+
+	return a, nil
+}
@@ -0,0 +1,16 @@
+package alert
+
+import "errors"
+
+var (
+	// ErrRuleNotFound means the alert rule does not exist
+	ErrRuleNotFound = errors.New("alert rule not found")
+	// ErrAlertNotFound means the alert does not exist
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrInvalidTransition means the alert can't move to the requested status from its current one
+	ErrInvalidTransition = errors.New("invalid alert status transition")
+	// ErrFloorNotFound means the floor of the rule scope does not exist
+	ErrFloorNotFound = errors.New("floor not found")
+	// ErrZoneNotFound means the zone of the rule scope does not exist on its floor
+	ErrZoneNotFound = errors.New("zone not found")
+)
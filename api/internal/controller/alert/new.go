@@ -0,0 +1,31 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+)
+
+// Controller represents the specification of this pkg
+type Controller interface {
+	GetRules(ctx context.Context) ([]model.AlertRule, error)
+	GetRule(ctx context.Context, id int64) (model.AlertRule, error)
+	CreateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error)
+	UpdateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error)
+	GetAlerts(ctx context.Context, input model.GetAlertsInput) ([]model.Alert, error)
+	GetAlert(ctx context.Context, id int64) (model.Alert, error)
+	AcknowledgeAlert(ctx context.Context, id int64) (model.Alert, error)
+	ResolveAlert(ctx context.Context, id int64) (model.Alert, error)
+}
+
+// New initializes a new Controller instance and returns it
+func New(repo repository.Registry) Controller {
+	return impl{
+		repo: repo,
+	}
+}
+
+type impl struct {
+	repo repository.Registry
+}
@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/generator"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+)
+
+// GetRules retrieves all alert rules, enabled or not
+func (i impl) GetRules(ctx context.Context) ([]model.AlertRule, error) {
+	rules, err := i.repo.Alert().GetRules(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule retrieves an alert rule by id
+func (i impl) GetRule(ctx context.Context, id int64) (model.AlertRule, error) {
+	rule, err := i.repo.Alert().GetRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, alert.ErrNotFound) {
+			return model.AlertRule{}, ErrRuleNotFound
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to get alert rule %d: %w", id, err)
+	}
+	return rule, nil
+}
+
+// CreateRule adds an alert rule after checking its floor and zone exist
+func (i impl) CreateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	if err := i.checkScope(ctx, rule); err != nil {
+		return model.AlertRule{}, err
+	}
+
+	var err error
+	if rule.ID, err = generator.AlertRuleIDSNF.Generate(); err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to generate alert rule id: %w", err)
+	}
+
+	created, err := i.repo.Alert().CreateRule(ctx, rule)
+	if err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to create alert rule %s: %w", rule.Name, err)
+	}
+
+	log.Printf("[CreateRule] created alert rule %d: %s %s %.2f for %v", created.ID, created.Metric, created.Operator, created.Threshold, created.Duration)
+	return created, nil
+}
+
+// UpdateRule overwrites an alert rule after checking its floor and zone exist
+func (i impl) UpdateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	if err := i.checkScope(ctx, rule); err != nil {
+		return model.AlertRule{}, err
+	}
+
+	updated, err := i.repo.Alert().UpdateRule(ctx, rule)
+	if err != nil {
+		if errors.Is(err, alert.ErrNotFound) {
+			return model.AlertRule{}, ErrRuleNotFound
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to update alert rule %d: %w", rule.ID, err)
+	}
+	return updated, nil
+}
+
+// checkScope checks the floor and zone of the rule, when set, exist
+func (i impl) checkScope(ctx context.Context, rule model.AlertRule) error {
+	if rule.Floor == 0 {
+		return nil
+	}
+
+	if _, err := i.repo.Topology().GetFloor(ctx, rule.Floor); err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return ErrFloorNotFound
+		}
+		return fmt.Errorf("failed to check floor: %w", err)
+	}
+
+	if rule.Zone == 0 {
+		return nil
+	}
+
+	if _, err := i.repo.Topology().GetZone(ctx, rule.Floor, rule.Zone); err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return ErrZoneNotFound
+		}
+		return fmt.Errorf("failed to check zone: %w", err)
+	}
+
+	return nil
+}
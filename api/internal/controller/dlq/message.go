@@ -0,0 +1,122 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+// Message is a dead lettered message with its dead letter headers decoded
+type Message struct {
+	kafka.ConsumerMessage
+	// DeviceID is read from the payload, empty when it can't be decoded
+	DeviceID        string
+	Error           string
+	SourceTopic     string
+	SourcePartition int32
+	SourceOffset    int64
+	Attempts        int
+}
+
+// Position identifies a message of the dead letter topic
+type Position struct {
+	Partition int32
+	Offset    int64
+}
+
+// Filter selects dead lettered messages, zero fields match everything
+type Filter struct {
+	// Error is matched as a case insensitive substring of the error header
+	Error    string
+	DeviceID string
+	// Since and Until bound the time the message was dead lettered, Until is exclusive
+	Since time.Time
+	Until time.Time
+	// Positions restricts the selection to these messages of the dead letter topic
+	Positions []Position
+}
+
+// IsEmpty tells if the filter selects every message
+func (f Filter) IsEmpty() bool {
+	return f.Error == "" && f.DeviceID == "" && f.Since.IsZero() && f.Until.IsZero() && len(f.Positions) == 0
+}
+
+// Matches tells if the message is selected by the filter
+func (f Filter) Matches(m Message) bool {
+	if f.Error != "" && !strings.Contains(strings.ToLower(m.Error), strings.ToLower(f.Error)) {
+		return false
+	}
+	if f.DeviceID != "" && m.DeviceID != f.DeviceID {
+		return false
+	}
+	if !f.Since.IsZero() && m.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !m.Timestamp.Before(f.Until) {
+		return false
+	}
+	if len(f.Positions) > 0 {
+		for _, p := range f.Positions {
+			if p.Partition == m.ID.Partition && p.Offset == m.ID.Offset {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// List returns the dead lettered messages selected by the filter
+func (i impl) List(ctx context.Context, filter Filter) ([]Message, error) {
+	var msgs []Message
+	if err := i.read(ctx, filter, func(m Message) error {
+		msgs = append(msgs, m)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// read calls fn for every dead lettered message selected by the filter
+func (i impl) read(ctx context.Context, filter Filter, fn func(m Message) error) error {
+	if err := i.reader.ReadAll(ctx, func(cm kafka.ConsumerMessage) error {
+		m := toMessage(cm)
+		if !filter.Matches(m) {
+			return nil
+		}
+		return fn(m)
+	}); err != nil {
+		return fmt.Errorf("failed to read dead letters: %w", err)
+	}
+	return nil
+}
+
+func toMessage(cm kafka.ConsumerMessage) Message {
+	m := Message{
+		ConsumerMessage: cm,
+		Error:           cm.Header(kafka.HeaderDLQError),
+		SourceTopic:     cm.Header(kafka.HeaderDLQSourceTopic),
+	}
+
+	// Headers are written by kafka.DeadLetterProducer, anything unparsable is left zero
+	if v, err := strconv.ParseInt(cm.Header(kafka.HeaderDLQSourcePartition), 10, 32); err == nil {
+		m.SourcePartition = int32(v)
+	}
+	m.SourceOffset, _ = strconv.ParseInt(cm.Header(kafka.HeaderDLQSourceOffset), 10, 64)
+	m.Attempts, _ = strconv.Atoi(cm.Header(kafka.HeaderDLQAttempts))
+
+	var payload struct {
+		DeviceID string `json:"device_id"`
+	}
+	if err := json.Unmarshal(cm.Value, &payload); err == nil {
+		m.DeviceID = payload.DeviceID
+	}
+
+	return m
+}
@@ -0,0 +1,80 @@
+package dlq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatches(t *testing.T) {
+	msg := toMessage(kafka.ConsumerMessage{
+		ID:    kafka.ConsumerMessageID{Topic: "iot.sensor.dlq", Partition: 0, Offset: 12},
+		Value: []byte(`{"device_id":"TEMP_001","floor":"1"}`),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(kafka.HeaderDLQError), Value: []byte("json: cannot unmarshal string into Go struct field")},
+			{Key: []byte(kafka.HeaderDLQSourceTopic), Value: []byte("iot.sensor")},
+			{Key: []byte(kafka.HeaderDLQSourcePartition), Value: []byte("3")},
+			{Key: []byte(kafka.HeaderDLQSourceOffset), Value: []byte("42")},
+			{Key: []byte(kafka.HeaderDLQAttempts), Value: []byte("1")},
+		},
+		Timestamp: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	})
+
+	tcs := map[string]struct {
+		givenFilter Filter
+		expMatch    bool
+	}{
+		"empty":              {givenFilter: Filter{}, expMatch: true},
+		"error_substring":    {givenFilter: Filter{Error: "Cannot Unmarshal"}, expMatch: true},
+		"other_error":        {givenFilter: Filter{Error: "timeout"}, expMatch: false},
+		"device":             {givenFilter: Filter{DeviceID: "TEMP_001"}, expMatch: true},
+		"other_device":       {givenFilter: Filter{DeviceID: "CO2_001"}, expMatch: false},
+		"within_time":        {givenFilter: Filter{Since: msg.Timestamp, Until: msg.Timestamp.Add(time.Minute)}, expMatch: true},
+		"until_is_exclusive": {givenFilter: Filter{Until: msg.Timestamp}, expMatch: false},
+		"selected":           {givenFilter: Filter{Positions: []Position{{Partition: 1, Offset: 12}, {Partition: 0, Offset: 12}}}, expMatch: true},
+		"not_selected":       {givenFilter: Filter{Positions: []Position{{Partition: 1, Offset: 12}}}, expMatch: false},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			f := tc.givenFilter
+
+			// When:
+			match := f.Matches(msg)
+
+			// Then:
+			require.Equal(t, tc.expMatch, match)
+		})
+	}
+
+	// The dead letter headers are decoded
+	require.Equal(t, "iot.sensor", msg.SourceTopic)
+	require.Equal(t, int32(3), msg.SourcePartition)
+	require.Equal(t, int64(42), msg.SourceOffset)
+	require.Equal(t, 1, msg.Attempts)
+}
+
+func TestOriginalHeaders(t *testing.T) {
+	// Given: a dead letter of a message retried once, with a repeated header
+	headers := []sarama.RecordHeader{
+		{Key: []byte("baggage"), Value: []byte("a=1")},
+		{Key: []byte(kafka.HeaderMessageID), Value: []byte("iot.sensor/1/3")},
+		{Key: []byte(kafka.HeaderRetryAttempt), Value: []byte("1")},
+		{Key: []byte("baggage"), Value: []byte("b=2")},
+		{Key: []byte(kafka.HeaderDLQError), Value: []byte("db down")},
+	}
+
+	// When:
+	res := originalHeaders(headers)
+
+	// Then: the others are kept as they were
+	require.Equal(t, []sarama.RecordHeader{
+		{Key: []byte("baggage"), Value: []byte("a=1")},
+		{Key: []byte(kafka.HeaderMessageID), Value: []byte("iot.sensor/1/3")},
+		{Key: []byte("baggage"), Value: []byte("b=2")},
+	}, res)
+}
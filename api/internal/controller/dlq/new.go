@@ -0,0 +1,32 @@
+package dlq
+
+import (
+	"context"
+
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+// Controller represents the specification of this pkg
+type Controller interface {
+	List(ctx context.Context, filter Filter) ([]Message, error)
+	Replay(ctx context.Context, filter Filter, transform Transform) (ReplayResult, error)
+}
+
+// New initializes a new Controller instance reading the dead letter topic and replaying to targetTopic
+func New(
+	reader *kafka.TopicReader,
+	producer *kafka.SyncProducer,
+	targetTopic string,
+) Controller {
+	return impl{
+		reader:      reader,
+		producer:    producer,
+		targetTopic: targetTopic,
+	}
+}
+
+type impl struct {
+	reader      *kafka.TopicReader
+	producer    *kafka.SyncProducer
+	targetTopic string
+}
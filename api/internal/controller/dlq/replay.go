@@ -0,0 +1,63 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/IBM/sarama"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+// Transform fixes up the payload of a message before it is replayed,
+// returning an empty payload skips the message
+type Transform func(ctx context.Context, payload []byte) ([]byte, error)
+
+// ReplayResult summarizes a replay
+type ReplayResult struct {
+	Replayed int
+	Skipped  int
+}
+
+// Replay re-publishes the dead lettered messages selected by the filter to the target topic with their
+// original key and headers. It stops at the first message which fails to be transformed or published.
+func (i impl) Replay(ctx context.Context, filter Filter, transform Transform) (ReplayResult, error) {
+	var res ReplayResult
+	err := i.read(ctx, filter, func(m Message) error {
+		payload := m.Value
+		if transform != nil {
+			var err error
+			if payload, err = transform(ctx, payload); err != nil {
+				return fmt.Errorf("failed to transform %d:%d: %w", m.ID.Partition, m.ID.Offset, err)
+			}
+		}
+		if len(payload) == 0 {
+			log.Printf("[Replay] Skipping %d:%d, empty payload", m.ID.Partition, m.ID.Offset)
+			res.Skipped++
+			return nil
+		}
+
+		if _, _, err := i.producer.SendMessage(ctx, i.targetTopic, payload, kafka.ProducerMessageOption{
+			Key:           m.ID.Key,
+			RecordHeaders: originalHeaders(m.Headers),
+		}); err != nil {
+			return fmt.Errorf("failed to replay %d:%d: %w", m.ID.Partition, m.ID.Offset, err)
+		}
+		res.Replayed++
+		return nil
+	})
+	return res, err
+}
+
+// originalHeaders strips the dead letter and retry headers, a replayed message starts over its retries
+// and failing again gets fresh ones. kafka.HeaderMessageID is kept so the replayed message is deduplicated
+// with its earlier copies.
+func originalHeaders(headers []sarama.RecordHeader) []sarama.RecordHeader {
+	res := make([]sarama.RecordHeader, 0, len(headers))
+	for _, h := range headers {
+		if !kafka.IsDeadLetterHeader(string(h.Key)) && !kafka.IsRetryHeader(string(h.Key)) {
+			res = append(res, h)
+		}
+	}
+	return res
+}
@@ -0,0 +1,223 @@
+package iot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/generator"
+)
+
+type breachKey struct {
+	ruleID   int64
+	deviceID string
+}
+
+// breachChanges are the breaches a batch started, by their start, and the ones it ended, with a zero start
+type breachChanges map[breachKey]time.Time
+
+// alertEvaluator checks the readings of every batch against the enabled alert rules.
+// It remembers since when each (rule, device) is breaching, a batch only returns its changes to
+// be applied once it is committed, and the active alerts of its devices are loaded from the
+// repository every batch, so a rolled back batch leaves no stale state. The enabled rules are
+// loaded again once they are older than rulesRefresh.
+type alertEvaluator struct {
+	rulesRefresh time.Duration
+
+	mu       sync.Mutex
+	breaches map[breachKey]time.Time
+
+	rulesMu  sync.Mutex
+	rules    []model.AlertRule
+	loadedAt time.Time
+}
+
+func newAlertEvaluator(rulesRefresh time.Duration) *alertEvaluator {
+	return &alertEvaluator{
+		rulesRefresh: rulesRefresh,
+		breaches:     map[breachKey]time.Time{},
+	}
+}
+
+// evaluateAlerts raises and resolves the alerts for the readings and persists them
+// with repo, returns the number of raised alerts and the breach changes to apply once committed
+func (c *impl) evaluateAlerts(ctx context.Context, repo repository.Registry, readings []model.SensorReading) (int, breachChanges, error) {
+	rules, err := c.alertEval.enabledRules(ctx, repo)
+	if err != nil {
+		return 0, nil, err
+	}
+	deviceIDs := matchedDeviceIDs(rules, readings)
+	if len(deviceIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	active, err := repo.Alert().GetAlerts(ctx, model.GetAlertsInput{
+		Statuses:  []model.AlertStatus{model.AlertStatusOpen, model.AlertStatusAcknowledged},
+		DeviceIDs: deviceIDs,
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get active alerts: %w", err)
+	}
+
+	opened, resolved, changes := c.alertEval.evaluate(rules, active, readings, time.Now())
+
+	for i := range opened {
+		if opened[i].ID, err = generator.AlertIDSNF.Generate(); err != nil {
+			return 0, nil, fmt.Errorf("failed to generate alert id: %w", err)
+		}
+	}
+	if err := repo.Alert().CreateAlerts(ctx, opened); err != nil {
+		return 0, nil, fmt.Errorf("failed to create alerts: %w", err)
+	}
+	for _, a := range resolved {
+		if err := repo.Alert().UpdateAlertStatus(ctx, a); err != nil {
+			return 0, nil, fmt.Errorf("failed to resolve alert %d: %w", a.ID, err)
+		}
+	}
+
+	for _, a := range opened {
+		log.Printf("[evaluateAlerts] rule %d raised alert %d for device %s, %s=%.2f", a.RuleID, a.ID, a.DeviceID, a.Metric, a.Value)
+	}
+	return len(opened), changes, nil
+}
+
+// enabledRules returns the enabled alert rules, loading them with repo when the cached ones are too old.
+// A rule created, updated or disabled meanwhile is only evaluated as such after the refresh.
+func (e *alertEvaluator) enabledRules(ctx context.Context, repo repository.Registry) ([]model.AlertRule, error) {
+	e.rulesMu.Lock()
+	defer e.rulesMu.Unlock()
+
+	if !e.loadedAt.IsZero() && time.Since(e.loadedAt) < e.rulesRefresh {
+		return e.rules, nil
+	}
+	rules, err := repo.Alert().GetRules(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules: %w", err)
+	}
+	e.rules, e.loadedAt = rules, time.Now()
+	return rules, nil
+}
+
+// matchedDeviceIDs returns the devices of the readings matched by at least one rule
+func matchedDeviceIDs(rules []model.AlertRule, readings []model.SensorReading) []string {
+	var deviceIDs []string
+	seen := map[string]bool{}
+	for _, r := range readings {
+		if seen[r.DeviceID] {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.Matches(r) {
+				seen[r.DeviceID] = true
+				deviceIDs = append(deviceIDs, r.DeviceID)
+				break
+			}
+		}
+	}
+	return deviceIDs
+}
+
+// apply records the breach changes of a committed batch
+func (e *alertEvaluator) apply(changes breachChanges) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for key, since := range changes {
+		if since.IsZero() {
+			delete(e.breaches, key)
+			continue
+		}
+		e.breaches[key] = since
+	}
+}
+
+// evaluate walks the readings in time order. A (rule, device) raises an alert once it
+// has breached for the rule duration and has no active alert, and resolves its active
+// alert on the first reading back to normal. An alert raised and resolved within the
+// same batch is returned in opened with the resolved status. The breaches are left as they
+// are, the ones the readings start or end are returned in changes.
+func (e *alertEvaluator) evaluate(rules []model.AlertRule, active []model.Alert, readings []model.SensorReading, now time.Time) (opened, resolved []model.Alert, changes breachChanges) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	changes = breachChanges{}
+	breachedSince := func(key breachKey) (time.Time, bool) {
+		if since, ok := changes[key]; ok {
+			return since, !since.IsZero()
+		}
+		since, ok := e.breaches[key]
+		return since, ok
+	}
+
+	sorted := make([]model.SensorReading, len(readings))
+	copy(sorted, readings)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// index into opened for new alerts, -1 for alerts that were already persisted
+	activeIdx := make(map[breachKey]int, len(active))
+	persisted := make(map[breachKey]model.Alert, len(active))
+	for _, a := range active {
+		key := breachKey{ruleID: a.RuleID, deviceID: a.DeviceID}
+		activeIdx[key] = -1
+		persisted[key] = a
+	}
+
+	for _, r := range sorted {
+		for _, rule := range rules {
+			if !rule.Matches(r) {
+				continue
+			}
+			key := breachKey{ruleID: rule.ID, deviceID: r.DeviceID}
+			value := rule.Metric.Value(r)
+
+			if rule.Breached(value) {
+				since, ok := breachedSince(key)
+				if !ok {
+					since = r.Timestamp
+					changes[key] = since
+				}
+				if _, isActive := activeIdx[key]; isActive || r.Timestamp.Sub(since) < rule.Duration {
+					continue
+				}
+				opened = append(opened, model.Alert{
+					RuleID:      rule.ID,
+					DeviceID:    r.DeviceID,
+					Metric:      rule.Metric,
+					Value:       value,
+					Threshold:   rule.Threshold,
+					Status:      model.AlertStatusOpen,
+					TriggeredAt: since,
+					CreatedAt:   now,
+					UpdatedAt:   now,
+				})
+				activeIdx[key] = len(opened) - 1
+				continue
+			}
+
+			if _, ok := breachedSince(key); ok {
+				changes[key] = time.Time{}
+			}
+			idx, isActive := activeIdx[key]
+			if !isActive {
+				continue
+			}
+			if idx >= 0 {
+				opened[idx].Status = model.AlertStatusResolved
+				opened[idx].ResolvedAt = r.Timestamp
+			} else {
+				a := persisted[key]
+				a.Status = model.AlertStatusResolved
+				a.ResolvedAt = r.Timestamp
+				resolved = append(resolved, a)
+			}
+			delete(activeIdx, key)
+		}
+	}
+
+	return opened, resolved, changes
+}
@@ -0,0 +1,191 @@
+package iot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertEvaluator(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := base.Add(time.Hour)
+	co2Rule := model.AlertRule{
+		ID:        1,
+		Floor:     1,
+		Zone:      2,
+		Metric:    model.AlertMetricCO2,
+		Operator:  model.AlertOperatorGT,
+		Threshold: 1000,
+		Duration:  5 * time.Minute,
+		Enabled:   true,
+	}
+	reading := func(deviceID string, zone int, co2 float64, after time.Duration) model.SensorReading {
+		return model.SensorReading{DeviceID: deviceID, Floor: 1, Zone: zone, CO2: co2, Timestamp: base.Add(after)}
+	}
+
+	tcs := map[string]struct {
+		givenActive   []model.Alert
+		givenReadings []model.SensorReading
+		expOpened     []model.Alert
+		expResolved   []model.Alert
+	}{
+		"breach_shorter_than_duration": {
+			givenReadings: []model.SensorReading{
+				reading("CO2_001", 2, 1200, 0),
+				reading("CO2_001", 2, 1300, 4*time.Minute),
+			},
+		},
+		"breach_for_duration_raises_once": {
+			givenReadings: []model.SensorReading{
+				reading("CO2_001", 2, 1300, 6*time.Minute),
+				reading("CO2_001", 2, 1200, 0),
+				reading("CO2_001", 2, 1250, 5*time.Minute),
+			},
+			expOpened: []model.Alert{
+				{
+					RuleID: 1, DeviceID: "CO2_001", Metric: model.AlertMetricCO2, Value: 1250, Threshold: 1000,
+					Status: model.AlertStatusOpen, TriggeredAt: base, CreatedAt: now, UpdatedAt: now,
+				},
+			},
+		},
+		"recovery_resets_breach": {
+			givenReadings: []model.SensorReading{
+				reading("CO2_001", 2, 1200, 0),
+				reading("CO2_001", 2, 800, 3*time.Minute),
+				reading("CO2_001", 2, 1200, 6*time.Minute),
+			},
+		},
+		"out_of_scope": {
+			givenReadings: []model.SensorReading{
+				reading("CO2_002", 3, 1200, 0),
+				reading("CO2_002", 3, 1200, 10*time.Minute),
+			},
+		},
+		"recovery_resolves_active_alert": {
+			givenActive: []model.Alert{
+				{ID: 9, RuleID: 1, DeviceID: "CO2_001", Status: model.AlertStatusAcknowledged, TriggeredAt: base},
+			},
+			givenReadings: []model.SensorReading{
+				reading("CO2_001", 2, 1200, 10*time.Minute),
+				reading("CO2_001", 2, 900, 11*time.Minute),
+			},
+			expResolved: []model.Alert{
+				{
+					ID: 9, RuleID: 1, DeviceID: "CO2_001", Status: model.AlertStatusResolved,
+					TriggeredAt: base, ResolvedAt: base.Add(11 * time.Minute),
+				},
+			},
+		},
+		"raised_and_resolved_in_batch": {
+			givenReadings: []model.SensorReading{
+				reading("CO2_001", 2, 1200, 0),
+				reading("CO2_001", 2, 1200, 5*time.Minute),
+				reading("CO2_001", 2, 900, 7*time.Minute),
+			},
+			expOpened: []model.Alert{
+				{
+					RuleID: 1, DeviceID: "CO2_001", Metric: model.AlertMetricCO2, Value: 1200, Threshold: 1000,
+					Status: model.AlertStatusResolved, TriggeredAt: base, ResolvedAt: base.Add(7 * time.Minute),
+					CreatedAt: now, UpdatedAt: now,
+				},
+			},
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			e := newAlertEvaluator(time.Minute)
+
+			// When:
+			opened, resolved, _ := e.evaluate([]model.AlertRule{co2Rule}, tc.givenActive, tc.givenReadings, now)
+
+			// Then:
+			require.Equal(t, tc.expOpened, opened)
+			require.Equal(t, tc.expResolved, resolved)
+		})
+	}
+}
+
+func TestAlertEvaluatorAcrossBatches(t *testing.T) {
+	// Given:
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	rule := model.AlertRule{
+		ID: 1, Metric: model.AlertMetricTemperature, Operator: model.AlertOperatorGTE,
+		Threshold: 30, Duration: time.Minute, Enabled: true,
+	}
+	tcs := map[string]struct {
+		givenCommitted bool
+		expOpened      int
+	}{
+		"first_batch_committed": {
+			givenCommitted: true,
+			expOpened:      1,
+		},
+		"first_batch_rolled_back": {},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			e := newAlertEvaluator(time.Minute)
+
+			// When:
+			opened1, _, changes := e.evaluate([]model.AlertRule{rule}, nil, []model.SensorReading{
+				{DeviceID: "TEMP_001", Temperature: 30, Timestamp: base},
+			}, base)
+			if tc.givenCommitted {
+				e.apply(changes)
+			}
+			opened2, _, _ := e.evaluate([]model.AlertRule{rule}, nil, []model.SensorReading{
+				{DeviceID: "TEMP_001", Temperature: 31, Timestamp: base.Add(time.Minute)},
+			}, base)
+
+			// Then: the breach of a rolled back batch is started again by the next one
+			require.Empty(t, opened1)
+			require.Len(t, opened2, tc.expOpened)
+			if tc.expOpened > 0 {
+				require.Equal(t, base, opened2[0].TriggeredAt)
+			}
+		})
+	}
+}
+
+func TestAlertEvaluatorRulesRefresh(t *testing.T) {
+	ctx := context.Background()
+	tcs := map[string]struct {
+		givenRefresh time.Duration
+		expRules     int
+	}{
+		"cached": {
+			givenRefresh: time.Minute,
+			expRules:     1,
+		},
+		"refreshed": {
+			expRules: 2,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given: a rule is created once the rules were loaded
+			repo, _, err := repository.NewFromConfig(ctx, repository.Config{Backend: repository.BackendMemory})
+			require.NoError(t, err)
+			e := newAlertEvaluator(tc.givenRefresh)
+			_, err = repo.Alert().CreateRule(ctx, model.AlertRule{ID: 1, Name: "hot", Enabled: true})
+			require.NoError(t, err)
+			_, err = e.enabledRules(ctx, repo)
+			require.NoError(t, err)
+			_, err = repo.Alert().CreateRule(ctx, model.AlertRule{ID: 2, Name: "humid", Enabled: true})
+			require.NoError(t, err)
+
+			// When:
+			rules, err := e.enabledRules(ctx, repo)
+
+			// Then:
+			require.NoError(t, err)
+			require.Len(t, rules, tc.expRules)
+		})
+	}
+}
@@ -2,15 +2,16 @@ package iot
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"time"
 
 	"github.com/nhan1603/IoTsystem/api/internal/model"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
-	"github.com/nhan1603/IoTsystem/api/internal/pkg/obsmetrics"
-	"github.com/nhan1603/IoTsystem/api/internal/repository"
 )
 
 // BenchmarkMetrics tracks performance metrics
@@ -22,6 +23,7 @@ type BenchmarkMetrics struct {
 	LastProcessed    time.Time
 	EndToEndLatency  time.Duration
 	BatchCount       int64
+	BatchMessages    int64
 	TotalLatency     time.Duration
 }
 
@@ -33,76 +35,77 @@ func (c *impl) Stop() {
 	log.Println("IoT consumer stopped")
 }
 
-// handleBatch processes a batch of messages from Kafka
+// HandleBatch passes a batch of messages from Kafka through the ingestion pipeline
 func (c *impl) HandleBatch(ctx context.Context, msgs []kafka.ConsumerMessage) error {
-	var readings []model.SensorReading
-	start := time.Now()
-	obsmetrics.BatchSize.Set(float64(len(msgs)))
-
-	for _, msg := range msgs {
-		var iotMsg model.IoTDataMessage
-		if err := json.Unmarshal(msg.Value, &iotMsg); err != nil {
-			log.Printf("Error unmarshaling message: %v", err)
-			c.incrementFailedRecords()
-			continue
+	b, err := c.pipeline.run(ctx, c.repo, msgs)
+	if err != nil {
+		log.Printf("Error processing batch: %v", err)
+		if unreachable(ctx, err) {
+			return fmt.Errorf("failed to process batch: %w: %w", kafka.ErrBatchFailed, err)
 		}
-		readings = append(readings, model.SensorReading{
-			DeviceID:    iotMsg.DeviceID,
-			DeviceName:  iotMsg.DeviceName,
-			DeviceType:  iotMsg.DeviceType,
-			Location:    iotMsg.Location,
-			Floor:       iotMsg.Floor,
-			Zone:        iotMsg.Zone,
-			Temperature: iotMsg.Temperature,
-			Humidity:    iotMsg.Humidity,
-			CO2:         iotMsg.CO2,
-			Timestamp:   iotMsg.Timestamp,
-			CreatedAt:   start,
-		})
-
-		// Record sensor readings
-		obsmetrics.SensorReadings.WithLabelValues(iotMsg.DeviceID, "temperature").Set(iotMsg.Temperature)
-		obsmetrics.SensorReadings.WithLabelValues(iotMsg.DeviceID, "humidity").Set(iotMsg.Humidity)
-		obsmetrics.SensorReadings.WithLabelValues(iotMsg.DeviceID, "co2").Set(iotMsg.CO2)
-
-		obsmetrics.MessagesProcessed.WithLabelValues(iotMsg.DeviceID, "success").Inc()
+		return fmt.Errorf("failed to process batch: %w", err)
 	}
 
-	if len(readings) == 0 {
+	readings := len(b.Accepted())
+	if readings == 0 {
 		log.Println("No valid readings to process")
 		return nil
 	}
 
-	// Insert readings using transaction
-	err := c.repo.DoInTx(ctx, func(txRepo repository.Registry) error {
-		return txRepo.IoT().BatchInsertReadings(ctx, readings)
-	})
-
-	if err != nil {
-		log.Printf("Error processing batch: %v", err)
-		return fmt.Errorf("failed to process batch: %w", err)
-	}
+	log.Printf("[HandleBatch] Processed batch of %d records in %v", readings, time.Since(b.ReceivedAt))
+	return nil
+}
 
-	// Record metrics only after commit
-	latency := time.Since(start)
-	endToEndLatency := time.Since(readings[0].CreatedAt)
-	c.updateMetrics(len(readings), latency, endToEndLatency)
-	c.SaveMetrics(ctx)
+// unreachable tells whether the batch failed because the database timed out or couldn't be reached,
+// which no record of the batch is the cause of
+func unreachable(ctx context.Context, err error) bool {
+	var netErr net.Error
+	return ctx.Err() != nil || errors.As(err, &netErr) || errors.Is(err, driver.ErrBadConn)
+}
 
-	obsmetrics.ProcessingLatency.WithLabelValues("batch").Observe(time.Since(start).Seconds())
+// messageID returns the producer assigned id of the message, or else the id carried by its
+// header, the Kafka position of its first copy, which is the same across redeliveries and
+// the copies republished by the retry topics and the dead letter replay
+func messageID(msg model.IoTDataMessage, cm kafka.ConsumerMessage) string {
+	if msg.MessageID != "" {
+		return msg.MessageID
+	}
+	return cm.MessageID()
+}
 
-	log.Printf("[HandleBatch] Processed batch of %d records in %v", len(readings), latency)
-	return nil
+// nextOffsets returns the offset of the next message to consume of every partition in the batch
+func nextOffsets(msgs []kafka.ConsumerMessage) []model.ConsumerOffset {
+	var offsets []model.ConsumerOffset
+	idx := make(map[kafka.ConsumerMessageID]int)
+	for _, msg := range msgs {
+		key := kafka.ConsumerMessageID{Topic: msg.ID.Topic, Partition: msg.ID.Partition}
+		i, ok := idx[key]
+		if !ok {
+			idx[key] = len(offsets)
+			offsets = append(offsets, model.ConsumerOffset{
+				GroupID:   ConsumerGroup,
+				Topic:     msg.ID.Topic,
+				Partition: msg.ID.Partition,
+				Offset:    msg.ID.Offset + 1,
+			})
+			continue
+		}
+		if next := msg.ID.Offset + 1; next > offsets[i].Offset {
+			offsets[i].Offset = next
+		}
+	}
+	return offsets
 }
 
-// updateMetrics updates performance metrics
-func (c *impl) updateMetrics(recordCount int, latency, endToEndLatency time.Duration) {
+// updateMetrics updates performance metrics with a batch of batchLen messages
+func (c *impl) updateMetrics(recordCount, batchLen int, latency, endToEndLatency time.Duration) {
 	c.metricsMutex.Lock()
 	defer c.metricsMutex.Unlock()
 
 	c.metrics.ProcessedRecords += int64(recordCount)
 	c.metrics.TotalRecords += int64(recordCount)
 	c.metrics.BatchCount++
+	c.metrics.BatchMessages += int64(batchLen)
 	c.metrics.TotalLatency += latency
 	c.metrics.EndToEndLatency += endToEndLatency
 	c.metrics.LastProcessed = time.Now()
@@ -156,11 +159,20 @@ func (c *impl) GetMetrics() model.BenchmarkMetrics {
 		AverageLatency:   avgLatency,
 		EndToEndLatency:  avgE2ELatency,
 		Throughput:       throughput,
-		BatchSize:        c.batchSize,
+		BatchSize:        c.averageBatchSize(),
 		DatabaseType:     "PostgreSQL",
 	}
 }
 
+// averageBatchSize is the average length of the batches, which the adaptive batch sizer
+// moves away from BATCH_SIZE, or BATCH_SIZE before the first batch. The caller holds metricsMutex.
+func (c *impl) averageBatchSize() int {
+	if c.metrics.BatchCount == 0 {
+		return c.batchSize
+	}
+	return int(math.Round(float64(c.metrics.BatchMessages) / float64(c.metrics.BatchCount)))
+}
+
 // SaveMetrics saves the current metrics to the database
 func (c *impl) SaveMetrics(ctx context.Context) error {
 	metrics := c.GetMetrics()
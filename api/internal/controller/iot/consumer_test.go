@@ -0,0 +1,157 @@
+package iot
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextOffsets(t *testing.T) {
+	msg := func(partition int32, offset int64) kafka.ConsumerMessage {
+		return kafka.ConsumerMessage{ID: kafka.ConsumerMessageID{Topic: "iot.sensor", Partition: partition, Offset: offset, Key: "k"}}
+	}
+
+	// Given: a batch spanning two partitions, bisection may hand them out of order
+	msgs := []kafka.ConsumerMessage{msg(1, 10), msg(0, 4), msg(1, 12), msg(1, 11), msg(0, 5)}
+
+	// When:
+	offsets := nextOffsets(msgs)
+
+	// Then:
+	require.Equal(t, []model.ConsumerOffset{
+		{GroupID: ConsumerGroup, Topic: "iot.sensor", Partition: 1, Offset: 13},
+		{GroupID: ConsumerGroup, Topic: "iot.sensor", Partition: 0, Offset: 6},
+	}, offsets)
+}
+
+func TestUnreachable(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	tcs := map[string]struct {
+		givenCtx context.Context
+		givenErr error
+		exp      bool
+	}{
+		"constraint_violation": {
+			givenCtx: context.Background(),
+			givenErr: errors.New("pq: insert or update on table violates foreign key constraint"),
+		},
+		"timed_out": {
+			givenCtx: expired,
+			givenErr: context.DeadlineExceeded,
+			exp:      true,
+		},
+		"connection_refused": {
+			givenCtx: context.Background(),
+			givenErr: fmt.Errorf("persist stage readings: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}),
+			exp:      true,
+		},
+		"bad_connection": {
+			givenCtx: context.Background(),
+			givenErr: fmt.Errorf("persist stage readings: %w", driver.ErrBadConn),
+			exp:      true,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			got := unreachable(tc.givenCtx, tc.givenErr)
+
+			// Then:
+			require.Equal(t, tc.exp, got)
+		})
+	}
+}
+
+func TestMessageID(t *testing.T) {
+	id := kafka.ConsumerMessageID{Topic: "iot.sensor", Partition: 1, Offset: 42}
+
+	tcs := map[string]struct {
+		givenData model.IoTDataMessage
+		givenMsg  kafka.ConsumerMessage
+		expID     string
+	}{
+		"producer_id": {
+			givenData: model.IoTDataMessage{MessageID: "m1"},
+			givenMsg:  kafka.ConsumerMessage{ID: id, Headers: []sarama.RecordHeader{{Key: []byte(kafka.HeaderMessageID), Value: []byte("iot.sensor/1/7")}}},
+			expID:     "m1",
+		},
+		"first_copy": {
+			givenMsg: kafka.ConsumerMessage{ID: id},
+			expID:    "iot.sensor/1/42",
+		},
+		"republished_copy": {
+			givenMsg: kafka.ConsumerMessage{ID: id, Headers: []sarama.RecordHeader{{Key: []byte(kafka.HeaderMessageID), Value: []byte("iot.sensor/1/7")}}},
+			expID:    "iot.sensor/1/7",
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			got := messageID(tc.givenData, tc.givenMsg)
+
+			// Then:
+			require.Equal(t, tc.expID, got)
+		})
+	}
+}
+
+func TestOrderingChecker(t *testing.T) {
+	at := func(deviceID string, sec int) model.SensorReading {
+		return model.SensorReading{DeviceID: deviceID, Timestamp: time.Unix(int64(sec), 0)}
+	}
+
+	// Given:
+	checker := newOrderingChecker()
+	require.Zero(t, checker.check([]model.SensorReading{at("TEMP_001", 10), at("HUM_001", 5), at("TEMP_001", 10)}))
+
+	// When: TEMP_001@8 arrives after TEMP_001@10, HUM_001 moves forward
+	violations := checker.check([]model.SensorReading{at("TEMP_001", 8), at("HUM_001", 6), at("TEMP_001", 11), at("TEMP_001", 9)})
+
+	// Then: the late readings don't move the newest time back
+	require.Equal(t, 2, violations)
+	require.Equal(t, time.Unix(11, 0), checker.newest["TEMP_001"])
+	require.Equal(t, time.Unix(6, 0), checker.newest["HUM_001"])
+}
+
+func TestGetMetricsBatchSize(t *testing.T) {
+	tcs := map[string]struct {
+		givenBatches []int
+		expSize      int
+	}{
+		"before_the_first_batch": {
+			expSize: 100,
+		},
+		"average_of_the_batches": {
+			givenBatches: []int{40, 70, 75},
+			expSize:      62,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given: BATCH_SIZE is 100 and the adaptive sizer shrank the batches
+			c := &impl{batchSize: 100, metrics: &BenchmarkMetrics{}}
+			for _, n := range tc.givenBatches {
+				c.updateMetrics(n, n, time.Millisecond, time.Millisecond)
+			}
+
+			// When:
+			got := c.GetMetrics()
+
+			// Then:
+			require.Equal(t, tc.expSize, got.BatchSize)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+)
+
+// GetDevice retrieves a device, active or not, by its device id
+func (c *impl) GetDevice(ctx context.Context, deviceID string) (model.IoTDevice, error) {
+	device, err := c.repo.IoT().GetDevice(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrNotFound) {
+			return model.IoTDevice{}, ErrDeviceNotFound
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	return device, nil
+}
+
+// CreateDevice registers a new device after checking its floor and zone exist
+func (c *impl) CreateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	if err := c.checkPlacement(ctx, device.Floor, device.Zone); err != nil {
+		return model.IoTDevice{}, err
+	}
+
+	created, err := c.repo.IoT().CreateDevice(ctx, device)
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrAlreadyExists) {
+			return model.IoTDevice{}, ErrDeviceAlreadyExists
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to create device %s: %w", device.DeviceID, err)
+	}
+
+	log.Printf("[CreateDevice] registered device %s on floor %d zone %d", created.DeviceID, created.Floor, created.Zone)
+	return created, nil
+}
+
+// UpdateDevice updates a device after checking its floor and zone exist
+func (c *impl) UpdateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	if err := c.checkPlacement(ctx, device.Floor, device.Zone); err != nil {
+		return model.IoTDevice{}, err
+	}
+
+	updated, err := c.repo.IoT().UpdateDevice(ctx, device)
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrNotFound) {
+			return model.IoTDevice{}, ErrDeviceNotFound
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to update device %s: %w", device.DeviceID, err)
+	}
+	return updated, nil
+}
+
+// DeactivateDevice stops a device from being listed and simulated, its readings are kept
+func (c *impl) DeactivateDevice(ctx context.Context, deviceID string) error {
+	return c.setDeviceActive(ctx, deviceID, false)
+}
+
+// ReactivateDevice brings a deactivated device back
+func (c *impl) ReactivateDevice(ctx context.Context, deviceID string) error {
+	return c.setDeviceActive(ctx, deviceID, true)
+}
+
+func (c *impl) setDeviceActive(ctx context.Context, deviceID string, active bool) error {
+	if err := c.repo.IoT().SetDeviceActive(ctx, deviceID, active); err != nil {
+		if errors.Is(err, iotsystem.ErrNotFound) {
+			return ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to set device %s active=%t: %w", deviceID, active, err)
+	}
+	return nil
+}
+
+// checkPlacement checks the floor exists and the zone belongs to it
+func (c *impl) checkPlacement(ctx context.Context, floorID, zoneID int) error {
+	if _, err := c.repo.Topology().GetFloor(ctx, floorID); err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return ErrFloorNotFound
+		}
+		return fmt.Errorf("failed to check floor: %w", err)
+	}
+
+	if _, err := c.repo.Topology().GetZone(ctx, floorID, zoneID); err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return ErrZoneNotFound
+		}
+		return fmt.Errorf("failed to check zone: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package iot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDevice(t *testing.T) {
+	tcs := map[string]struct {
+		givenDevice model.IoTDevice
+		expErr      error
+	}{
+		"registered": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_100", Name: "Temperature Sensor 100", Type: "temperature", Floor: 2, Zone: 4},
+		},
+		"device_id_taken": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_001", Name: "Temperature Sensor 1", Type: "temperature", Floor: 1, Zone: 1},
+			expErr:      ErrDeviceAlreadyExists,
+		},
+		"missing_floor": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_100", Floor: 9, Zone: 1},
+			expErr:      ErrFloorNotFound,
+		},
+		"zone_of_another_floor": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_100", Floor: 1, Zone: 3},
+			expErr:      ErrZoneNotFound,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			c := newDeviceController(t)
+
+			// When:
+			created, err := c.CreateDevice(context.Background(), tc.givenDevice)
+
+			// Then:
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, created.IsActive)
+			got, err := c.GetDevice(context.Background(), tc.givenDevice.DeviceID)
+			require.NoError(t, err)
+			require.Equal(t, created, got)
+		})
+	}
+}
+
+func TestUpdateDevice(t *testing.T) {
+	tcs := map[string]struct {
+		givenDevice model.IoTDevice
+		expErr      error
+	}{
+		"moved": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_001", Name: "Lab Temperature", Type: "temperature", Floor: 2, Zone: 3},
+		},
+		"missing_device": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_404", Floor: 1, Zone: 1},
+			expErr:      ErrDeviceNotFound,
+		},
+		"zone_of_another_floor": {
+			givenDevice: model.IoTDevice{DeviceID: "TEMP_001", Floor: 2, Zone: 1},
+			expErr:      ErrZoneNotFound,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			c := newDeviceController(t)
+
+			// When:
+			updated, err := c.UpdateDevice(context.Background(), tc.givenDevice)
+
+			// Then:
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.givenDevice.Name, updated.Name)
+			require.Equal(t, tc.givenDevice.Floor, updated.Floor)
+			require.Equal(t, tc.givenDevice.Zone, updated.Zone)
+		})
+	}
+}
+
+func TestDeactivateDevice(t *testing.T) {
+	ctx := context.Background()
+
+	// Given:
+	c := newDeviceController(t)
+
+	// When:
+	require.NoError(t, c.DeactivateDevice(ctx, "TEMP_001"))
+
+	// Then: it is kept but no longer listed
+	got, err := c.GetDevice(ctx, "TEMP_001")
+	require.NoError(t, err)
+	require.False(t, got.IsActive)
+	require.NotContains(t, deviceIDs(t, c), "TEMP_001")
+
+	// When:
+	require.NoError(t, c.ReactivateDevice(ctx, "TEMP_001"))
+
+	// Then:
+	require.Contains(t, deviceIDs(t, c), "TEMP_001")
+	require.ErrorIs(t, c.DeactivateDevice(ctx, "TEMP_404"), ErrDeviceNotFound)
+	require.ErrorIs(t, c.ReactivateDevice(ctx, "TEMP_404"), ErrDeviceNotFound)
+}
+
+// newDeviceController returns a controller over the sample building of the seed migration
+func newDeviceController(t *testing.T) *impl {
+	repo, cleanup, err := repository.NewFromConfig(context.Background(), repository.Config{
+		Backend:    repository.BackendMemory,
+		MemorySeed: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	return &impl{repo: repo}
+}
+
+func deviceIDs(t *testing.T, c *impl) []string {
+	devices, err := c.GetDevices(context.Background())
+	require.NoError(t, err)
+	ids := make([]string, len(devices))
+	for i, d := range devices {
+		ids[i] = d.DeviceID
+	}
+	return ids
+}
@@ -0,0 +1,18 @@
+package iot
+
+import "errors"
+
+var (
+	// ErrInvalidCursor means the given pagination cursor is malformed
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrFilterRequired means the readings of every device are too many to be listed together
+	ErrFilterRequired = errors.New("filter required")
+	// ErrDeviceNotFound means the device is not registered
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrDeviceAlreadyExists means a device with the same device id is already registered
+	ErrDeviceAlreadyExists = errors.New("device already exists")
+	// ErrFloorNotFound means the floor of the device does not exist
+	ErrFloorNotFound = errors.New("floor not found")
+	// ErrZoneNotFound means the zone of the device does not exist on its floor
+	ErrZoneNotFound = errors.New("zone not found")
+)
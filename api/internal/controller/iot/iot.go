@@ -2,8 +2,10 @@ package iot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -11,18 +13,36 @@ import (
 	"github.com/nhan1603/IoTsystem/api/internal/model"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/env"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/obsmetrics"
 	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+)
+
+const (
+	// ConsumerGroup is the Kafka consumer group ingesting the readings
+	ConsumerGroup = "iot"
+	// RetryConsumerGroup is the Kafka consumer group feeding the retry topics back to the readings topic
+	RetryConsumerGroup = "iot.retry"
+	// OffsetStoreDB stores the consumed offsets in the readings transaction instead of only in Kafka
+	OffsetStoreDB = "db"
 )
 
 // Controller represents the specification of this pkg
 type Controller interface {
 	GetDevices(ctx context.Context) ([]model.IoTDevice, error)
+	GetDevice(ctx context.Context, deviceID string) (model.IoTDevice, error)
+	CreateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error)
+	UpdateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error)
+	DeactivateDevice(ctx context.Context, deviceID string) error
+	ReactivateDevice(ctx context.Context, deviceID string) error
+	CountReadings(ctx context.Context, input model.GetReadingsInput) (int64, error)
+	AggregateReadings(ctx context.Context, input model.AggregateReadingsInput) ([]model.ReadingAggregate, error)
 	GetBenchmarkMetrics(ctx context.Context, limit int) ([]model.BenchmarkMetrics, error)
 	GetLatestReadings(ctx context.Context) ([]model.SensorReading, error)
 	GetMetrics() model.BenchmarkMetrics
-	GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, error)
-	GetReadingsByDevice(ctx context.Context, deviceID string, limit int) ([]model.SensorReading, error)
-	GetReadingsByTimeRange(ctx context.Context, startTime time.Time, endTime time.Time, limit int) ([]model.SensorReading, error)
+	GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, string, error)
+	GetReadingsByDevice(ctx context.Context, deviceID string, input model.GetReadingsInput) ([]model.SensorReading, string, error)
+	GetReadingsByTimeRange(ctx context.Context, startTime time.Time, endTime time.Time, limit int) ([]model.SensorReading, string, error)
 	HandleBatch(ctx context.Context, msgs []kafka.ConsumerMessage) error
 	SaveBenchmarkMetrics(ctx context.Context, metrics model.BenchmarkMetrics) error
 	SaveMetrics(ctx context.Context) error
@@ -31,6 +51,12 @@ type Controller interface {
 // impl handles IoT data operations
 type impl struct {
 	repo         repository.Registry
+	promMetrics  *obsmetrics.Metrics
+	alertEval    *alertEvaluator
+	ordering     *orderingChecker
+	pipeline     *pipeline
+	deadLetter   kafka.DeadLetterPublisher
+	offsetStore  bool
 	batchSize    int
 	metrics      *BenchmarkMetrics
 	metricsMutex sync.RWMutex
@@ -38,17 +64,34 @@ type impl struct {
 	wg           sync.WaitGroup
 }
 
-// New creates a new IoT controller
-func New(repo repository.Registry) *impl {
+// New creates a new IoT controller, messages that can't be decoded are only logged when deadLetter is nil.
+// The batches go through the pipeline stages listed in IOT_PIPELINE_STAGES, DefaultPipelineStages otherwise.
+func New(repo repository.Registry, promMetrics *obsmetrics.Metrics, deadLetter kafka.DeadLetterPublisher) (*impl, error) {
 	batchSize, _ := strconv.Atoi(env.GetwithDefault("BATCH_SIZE", "100"))
-	return &impl{
-		repo:      repo,
-		batchSize: batchSize,
+	rulesRefresh, _ := time.ParseDuration(env.GetwithDefault("ALERT_RULES_REFRESH", "30s"))
+	c := &impl{
+		repo:        repo,
+		promMetrics: promMetrics,
+		alertEval:   newAlertEvaluator(rulesRefresh),
+		ordering:    newOrderingChecker(),
+		deadLetter:  deadLetter,
+		offsetStore: env.GetwithDefault("KAFKA_OFFSET_STORE", "kafka") == OffsetStoreDB,
+		batchSize:   batchSize,
 		metrics: &BenchmarkMetrics{
 			StartTime: time.Now(),
 		},
 		stopChan: make(chan struct{}),
 	}
+
+	stages := DefaultPipelineStages
+	if v := os.Getenv("IOT_PIPELINE_STAGES"); v != "" {
+		stages = ParsePipelineStages(v)
+	}
+	var err error
+	if c.pipeline, err = newPipeline(c, stages); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // GetDevices retrieves all IoT devices
@@ -60,13 +103,50 @@ func (c *impl) GetDevices(ctx context.Context) ([]model.IoTDevice, error) {
 	return devices, nil
 }
 
-// GetReadings retrieves sensor readings with filters
-func (c *impl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, error) {
-	readings, err := c.repo.IoT().GetReadings(ctx, input)
+// GetReadings retrieves a page of sensor readings with filters and the cursor of the next page
+func (c *impl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, string, error) {
+	input.Limit = readingsLimit(input.Limit)
+	readings, nextCursor, err := c.repo.IoT().GetReadings(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get readings: %w", err)
+		if errors.Is(err, iotsystem.ErrInvalidCursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		if errors.Is(err, iotsystem.ErrTooManyDevices) {
+			return nil, "", ErrFilterRequired
+		}
+		return nil, "", fmt.Errorf("failed to get readings: %w", err)
 	}
-	return readings, nil
+	return readings, nextCursor, nil
+}
+
+// readingsLimit defaults the page size of the readings and caps it, the repository reads every matching
+// reading without a limit
+func readingsLimit(limit int) int {
+	if limit <= 0 {
+		return model.PaginationDefaultLimit
+	}
+	return min(limit, model.PaginationMaxLimit)
+}
+
+// CountReadings counts the sensor readings matching the filters
+func (c *impl) CountReadings(ctx context.Context, input model.GetReadingsInput) (int64, error) {
+	count, err := c.repo.IoT().CountReadings(ctx, input)
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrTooManyDevices) {
+			return 0, ErrFilterRequired
+		}
+		return 0, fmt.Errorf("failed to count readings: %w", err)
+	}
+	return count, nil
+}
+
+// AggregateReadings retrieves the avg/min/max of the readings per time bucket and group
+func (c *impl) AggregateReadings(ctx context.Context, input model.AggregateReadingsInput) ([]model.ReadingAggregate, error) {
+	aggs, err := c.repo.IoT().AggregateReadings(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate readings: %w", err)
+	}
+	return aggs, nil
 }
 
 // GetLatestReadings retrieves the latest reading for each device
@@ -78,31 +158,35 @@ func (c *impl) GetLatestReadings(ctx context.Context) ([]model.SensorReading, er
 	return readings, nil
 }
 
-// GetReadingsByDevice retrieves readings for a specific device
-func (c *impl) GetReadingsByDevice(ctx context.Context, deviceID string, limit int) ([]model.SensorReading, error) {
-	input := model.GetReadingsInput{
-		DeviceID: deviceID,
-		Limit:    limit,
-	}
-	readings, err := c.repo.IoT().GetReadings(ctx, input)
+// GetReadingsByDevice retrieves readings for a specific device, the device in input is overridden
+func (c *impl) GetReadingsByDevice(ctx context.Context, deviceID string, input model.GetReadingsInput) ([]model.SensorReading, string, error) {
+	input.DeviceID = deviceID
+	input.Limit = readingsLimit(input.Limit)
+	readings, nextCursor, err := c.repo.IoT().GetReadings(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get readings for device %s: %w", deviceID, err)
+		if errors.Is(err, iotsystem.ErrInvalidCursor) {
+			return nil, "", ErrInvalidCursor
+		}
+		return nil, "", fmt.Errorf("failed to get readings for device %s: %w", deviceID, err)
 	}
-	return readings, nil
+	return readings, nextCursor, nil
 }
 
 // GetReadingsByTimeRange retrieves readings within a time range
-func (c *impl) GetReadingsByTimeRange(ctx context.Context, startTime, endTime time.Time, limit int) ([]model.SensorReading, error) {
+func (c *impl) GetReadingsByTimeRange(ctx context.Context, startTime, endTime time.Time, limit int) ([]model.SensorReading, string, error) {
 	input := model.GetReadingsInput{
 		StartTime: startTime,
 		EndTime:   endTime,
-		Limit:     limit,
+		Limit:     readingsLimit(limit),
 	}
-	readings, err := c.repo.IoT().GetReadings(ctx, input)
+	readings, nextCursor, err := c.repo.IoT().GetReadings(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get readings by time range: %w", err)
+		if errors.Is(err, iotsystem.ErrTooManyDevices) {
+			return nil, "", ErrFilterRequired
+		}
+		return nil, "", fmt.Errorf("failed to get readings by time range: %w", err)
 	}
-	return readings, nil
+	return readings, nextCursor, nil
 }
 
 // GetBenchmarkMetrics retrieves benchmark performance metrics
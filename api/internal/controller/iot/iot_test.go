@@ -0,0 +1,40 @@
+package iot
+
+import (
+	"testing"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingsLimit(t *testing.T) {
+	tcs := map[string]struct {
+		given int
+		exp   int
+	}{
+		"unset": {
+			exp: model.PaginationDefaultLimit,
+		},
+		"negative": {
+			given: -1,
+			exp:   model.PaginationDefaultLimit,
+		},
+		"within_bounds": {
+			given: 50,
+			exp:   50,
+		},
+		"too_large": {
+			given: model.PaginationMaxLimit + 1,
+			exp:   model.PaginationMaxLimit,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			got := readingsLimit(tc.given)
+
+			// Then:
+			require.Equal(t, tc.exp, got)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package iot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// orderingChecker remembers the newest reading time of every device to spot the readings
+// consumed out of order. Retried and replayed messages are counted as well.
+type orderingChecker struct {
+	mu     sync.Mutex
+	newest map[string]time.Time
+}
+
+func newOrderingChecker() *orderingChecker {
+	return &orderingChecker{
+		newest: map[string]time.Time{},
+	}
+}
+
+// check returns the number of readings older than a reading of the same device consumed before them
+func (o *orderingChecker) check(readings []model.SensorReading) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var violations int
+	for _, r := range readings {
+		newest, ok := o.newest[r.DeviceID]
+		if ok && r.Timestamp.Before(newest) {
+			violations++
+			continue
+		}
+		o.newest[r.DeviceID] = r.Timestamp
+	}
+	return violations
+}
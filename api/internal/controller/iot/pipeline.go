@@ -0,0 +1,260 @@
+package iot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+)
+
+// Phase orders the stages of the ingestion pipeline
+type Phase int
+
+// Phases of the ingestion pipeline, in run order
+const (
+	// PhaseDecode turns the messages into readings
+	PhaseDecode Phase = iota
+	// PhaseValidate rejects the readings which can't be ingested
+	PhaseValidate
+	// PhaseEnrich adds data from outside the message to the readings
+	PhaseEnrich
+	// PhaseDerive computes data from the readings
+	PhaseDerive
+	// PhasePersist writes the batch, its stages share a transaction and failing one rolls the batch back
+	PhasePersist
+	// PhasePublish sends the batch out of the process once it is committed, an error fails the batch
+	// to have it retried. What it publishes may be published twice, the offsets stored by the persist
+	// stages must not move past what it has yet to publish.
+	PhasePublish
+	// PhaseNotify runs once the batch is committed, its errors are only logged
+	PhaseNotify
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseDecode:
+		return "decode"
+	case PhaseValidate:
+		return "validate"
+	case PhaseEnrich:
+		return "enrich"
+	case PhaseDerive:
+		return "derive"
+	case PhasePersist:
+		return "persist"
+	case PhasePublish:
+		return "publish"
+	case PhaseNotify:
+		return "notify"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// Stage is a step of the ingestion pipeline. An error fails the whole batch to have it retried,
+// a record which can't be ingested is rejected instead.
+type Stage interface {
+	Name() string
+	Phase() Phase
+	Process(ctx context.Context, b *Batch) error
+}
+
+// RecordError is why a stage rejected a record, retrying won't help
+type RecordError struct {
+	Stage string
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return e.Stage + ": " + e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Record is the result of the pipeline for a message
+type Record struct {
+	Msg     kafka.ConsumerMessage
+	Data    model.IoTDataMessage
+	Reading model.SensorReading
+	Err     *RecordError // the next stages skip a rejected record
+}
+
+// Reject flags the record as not ingestible
+func (r *Record) Reject(stage string, err error) {
+	r.Err = &RecordError{Stage: stage, Err: err}
+}
+
+// Batch is the state shared by the stages for a batch of messages
+type Batch struct {
+	Records    []*Record
+	ReceivedAt time.Time
+	// Repo is the transaction's registry during PhasePersist
+	Repo repository.Registry
+	// AlertsRaised is the number of alerts raised by the readings
+	AlertsRaised int
+
+	onCommit []func()
+}
+
+// OnCommit has f run once the persist stages are committed, before the publish stages.
+// It is dropped when they are rolled back, for the state a persist stage may only keep then.
+func (b *Batch) OnCommit(f func()) {
+	b.onCommit = append(b.onCommit, f)
+}
+
+// Accepted returns the records not rejected so far
+func (b *Batch) Accepted() []*Record {
+	var res []*Record
+	for _, r := range b.Records {
+		if r.Err == nil {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Rejected returns the rejected records
+func (b *Batch) Rejected() []*Record {
+	var res []*Record
+	for _, r := range b.Records {
+		if r.Err != nil {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// Readings returns the readings of the accepted records
+func (b *Batch) Readings() []model.SensorReading {
+	var res []model.SensorReading
+	for _, r := range b.Records {
+		if r.Err == nil {
+			res = append(res, r.Reading)
+		}
+	}
+	return res
+}
+
+// DefaultPipelineStages are the stages run when IOT_PIPELINE_STAGES is unset
+var DefaultPipelineStages = []string{
+	stageJSON, stageRequired, stageReadings, stageAlerts, stageOffsets, stageDeadLetter, stageMetrics, stageOrdering,
+}
+
+// ParsePipelineStages parses a comma separated list of stage names
+func ParsePipelineStages(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// pipeline runs its stages phase by phase, the stages of a phase in their configured order
+type pipeline struct {
+	stages []Stage
+}
+
+// newPipeline creates the stages registered under the names
+func newPipeline(c *impl, names []string) (*pipeline, error) {
+	var stages []Stage
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		newStage, ok := stageRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pipeline stage %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("pipeline stage %q is listed twice", name)
+		}
+		seen[name] = true
+		stages = append(stages, newStage(c))
+	}
+	sort.SliceStable(stages, func(i, j int) bool { return stages[i].Phase() < stages[j].Phase() })
+
+	if len(stages) == 0 || stages[0].Phase() != PhaseDecode {
+		return nil, fmt.Errorf("the pipeline needs a %s stage", PhaseDecode)
+	}
+	return &pipeline{stages: stages}, nil
+}
+
+// run passes the messages through the stages, the persist ones within a transaction of repo and the
+// publish ones once it is committed. The batch is returned with the error of the stage failing it.
+func (p *pipeline) run(ctx context.Context, repo repository.Registry, msgs []kafka.ConsumerMessage) (*Batch, error) {
+	b := &Batch{
+		Records:    make([]*Record, len(msgs)),
+		ReceivedAt: time.Now(),
+		Repo:       repo,
+	}
+	for i, msg := range msgs {
+		b.Records[i] = &Record{Msg: msg}
+	}
+
+	var persist, publish, notify []Stage
+	for _, s := range p.stages {
+		switch s.Phase() {
+		case PhasePersist:
+			persist = append(persist, s)
+			continue
+		case PhasePublish:
+			publish = append(publish, s)
+			continue
+		case PhaseNotify:
+			notify = append(notify, s)
+			continue
+		}
+		if err := process(ctx, s, b); err != nil {
+			return b, err
+		}
+	}
+
+	if len(persist) > 0 {
+		err := repo.DoInTx(ctx, func(txCtx context.Context, txRepo repository.Registry) error {
+			b.Repo = txRepo
+			for _, s := range persist {
+				if err := process(txCtx, s, b); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		b.Repo = repo
+		if err != nil {
+			b.onCommit = nil
+			return b, err
+		}
+		for _, f := range b.onCommit {
+			f()
+		}
+	}
+
+	for _, s := range publish {
+		if err := process(ctx, s, b); err != nil {
+			// what the batch publishes doesn't depend on one of its records being processed
+			return b, fmt.Errorf("%w: %w", kafka.ErrBatchFailed, err)
+		}
+	}
+
+	for _, s := range notify {
+		if err := process(ctx, s, b); err != nil {
+			log.Printf("[Pipeline] %v", err)
+		}
+	}
+	return b, nil
+}
+
+func process(ctx context.Context, s Stage, b *Batch) error {
+	if err := s.Process(ctx, b); err != nil {
+		return fmt.Errorf("%s stage %s: %w", s.Phase(), s.Name(), err)
+	}
+	return nil
+}
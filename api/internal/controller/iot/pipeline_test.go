@@ -0,0 +1,227 @@
+package iot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPipeline(t *testing.T) {
+	tcs := map[string]struct {
+		givenStages []string
+		expStages   []string
+		expErr      string
+	}{
+		"default": {
+			givenStages: DefaultPipelineStages,
+			expStages:   DefaultPipelineStages,
+		},
+		"sorted_by_phase": {
+			givenStages: ParsePipelineStages("ordering, readings,required,json"),
+			expStages:   []string{"json", "required", "readings", "ordering"},
+		},
+		"unknown": {
+			givenStages: []string{"json", "geo"},
+			expErr:      `unknown pipeline stage "geo"`,
+		},
+		"twice": {
+			givenStages: []string{"json", "json"},
+			expErr:      `pipeline stage "json" is listed twice`,
+		},
+		"no_decode": {
+			givenStages: []string{"readings"},
+			expErr:      "the pipeline needs a decode stage",
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			p, err := newPipeline(&impl{}, tc.givenStages)
+
+			// Then:
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, s := range p.stages {
+				names = append(names, s.Name())
+			}
+			require.Equal(t, tc.expStages, names)
+		})
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	msg := func(offset int64, payload string) kafka.ConsumerMessage {
+		return kafka.ConsumerMessage{ID: kafka.ConsumerMessageID{Topic: "iot.sensor", Offset: offset}, Value: []byte(payload)}
+	}
+	c := &impl{metrics: &BenchmarkMetrics{}}
+	var notified []string
+	notify := newStage("notify", PhaseNotify, func(_ context.Context, b *Batch) error {
+		for _, r := range b.Readings() {
+			notified = append(notified, r.DeviceID)
+		}
+		return errors.New("only logged")
+	})
+
+	// Given:
+	p := &pipeline{stages: []Stage{stageRegistry[stageJSON](c), stageRegistry[stageRequired](c), notify}}
+	msgs := []kafka.ConsumerMessage{
+		msg(0, `{"device_id":"TEMP_001","timestamp":"2025-01-01T10:00:00Z","temperature":21.5}`),
+		msg(1, `not json`),
+		msg(2, `{"timestamp":"2025-01-01T10:00:00Z"}`),
+		msg(3, `{"device_id":"HUM_001","timestamp":"2025-01-01T10:00:01Z","message_id":"m-3"}`),
+	}
+
+	// When:
+	b, err := p.run(context.Background(), nil, msgs)
+
+	// Then: rejected records skip the next stages
+	require.NoError(t, err)
+	require.Equal(t, []string{"TEMP_001", "HUM_001"}, notified)
+	require.Len(t, b.Rejected(), 2)
+	require.Equal(t, stageJSON, b.Records[1].Err.Stage)
+	require.Equal(t, stageRequired, b.Records[2].Err.Stage)
+	require.EqualError(t, b.Records[2].Err, "required: device_id is required")
+	require.Equal(t, "iot.sensor/0/0", b.Records[0].Reading.MessageID)
+	require.Equal(t, "m-3", b.Records[3].Reading.MessageID)
+	require.Equal(t, b.ReceivedAt, b.Records[3].Reading.CreatedAt)
+	require.Equal(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), b.Records[0].Reading.Timestamp)
+	require.Equal(t, int64(2), c.metrics.FailedRecords)
+}
+
+func TestPipelineRunRollback(t *testing.T) {
+	ctx := context.Background()
+	c := &impl{metrics: &BenchmarkMetrics{}}
+	msgs := []kafka.ConsumerMessage{{Value: []byte(`{"device_id":"TEMP_001","timestamp":"2025-01-01T10:00:00Z"}`)}}
+
+	tcs := map[string]struct {
+		givenErr        error
+		givenPublishErr error
+		expCount        int64
+		expCommitted    bool
+		expPublished    bool
+	}{
+		"committed": {
+			expCount:     1,
+			expCommitted: true,
+			expPublished: true,
+		},
+		"rolled_back": {
+			givenErr: errors.New("disk full"),
+		},
+		"publish_failed_after_commit": {
+			givenPublishErr: errors.New("broker down"),
+			expCount:        1,
+			expCommitted:    true,
+			expPublished:    true,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			repo, _, err := repository.NewFromConfig(ctx, repository.Config{Backend: repository.BackendMemory})
+			require.NoError(t, err)
+			var committed bool
+			hooked := newStage("hooked", PhasePersist, func(_ context.Context, b *Batch) error {
+				b.OnCommit(func() { committed = true })
+				return nil
+			})
+			failing := newStage("failing", PhasePersist, func(context.Context, *Batch) error { return tc.givenErr })
+			var published bool
+			publish := newStage("publish", PhasePublish, func(context.Context, *Batch) error {
+				published = true
+				return tc.givenPublishErr
+			})
+			p := &pipeline{stages: []Stage{stageRegistry[stageJSON](c), stageRegistry[stageReadings](c), hooked, failing, publish}}
+
+			// When:
+			_, err = p.run(ctx, repo, msgs)
+
+			// Then: the readings are only kept if every persist stage succeeded, and only then published
+			switch {
+			case tc.givenErr != nil:
+				require.ErrorIs(t, err, tc.givenErr)
+			case tc.givenPublishErr != nil:
+				require.ErrorIs(t, err, tc.givenPublishErr)
+				require.ErrorIs(t, err, kafka.ErrBatchFailed)
+			default:
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expCommitted, committed)
+			require.Equal(t, tc.expPublished, published)
+			count, err := repo.IoT().CountReadings(ctx, model.GetReadingsInput{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expCount, count)
+		})
+	}
+}
+
+func TestPipelineRunDeadLetterOffsets(t *testing.T) {
+	ctx := context.Background()
+	msg := func(offset int64, payload string) kafka.ConsumerMessage {
+		return kafka.ConsumerMessage{ID: kafka.ConsumerMessageID{Topic: "iot.sensor", Partition: 0, Offset: offset}, Value: []byte(payload)}
+	}
+	msgs := []kafka.ConsumerMessage{
+		msg(4, `{"device_id":"TEMP_001","timestamp":"2025-01-01T10:00:00Z"}`),
+		msg(5, `not json`),
+		msg(6, `{"device_id":"TEMP_001","timestamp":"2025-01-01T10:00:01Z"}`),
+	}
+
+	tcs := map[string]struct {
+		givenPublishErr error
+		expPublished    []int64
+		expOffset       int64
+	}{
+		"published": {
+			expPublished: []int64{5},
+			expOffset:    7,
+		},
+		"publish_failed_after_commit": {
+			givenPublishErr: errors.New("broker down"),
+			expOffset:       5,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given: the offsets are stored with the readings
+			repo, _, err := repository.NewFromConfig(ctx, repository.Config{Backend: repository.BackendMemory})
+			require.NoError(t, err)
+			dlq := &fakeDeadLetter{err: tc.givenPublishErr}
+			c := &impl{metrics: &BenchmarkMetrics{}, offsetStore: true, deadLetter: dlq}
+			p, err := newPipeline(c, []string{stageJSON, stageReadings, stageOffsets, stageDeadLetter})
+			require.NoError(t, err)
+
+			// When:
+			_, err = p.run(ctx, repo, msgs)
+
+			// Then: the stored offset never passes a record which isn't dead lettered
+			require.ErrorIs(t, err, tc.givenPublishErr)
+			require.Equal(t, tc.expPublished, dlq.published)
+			offsets, err := repo.Offset().GetOffsets(ctx, ConsumerGroup, "iot.sensor")
+			require.NoError(t, err)
+			require.Equal(t, map[int32]int64{0: tc.expOffset}, offsets)
+		})
+	}
+}
+
+type fakeDeadLetter struct {
+	published []int64
+	err       error
+}
+
+func (f *fakeDeadLetter) PublishDeadLetter(_ context.Context, msg kafka.ConsumerMessage, _ error, _ int) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, msg.ID.Offset)
+	return nil
+}
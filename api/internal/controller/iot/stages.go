@@ -0,0 +1,223 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/obsmetrics"
+)
+
+// Names of the built-in pipeline stages
+const (
+	stageJSON       = "json"
+	stageRequired   = "required"
+	stageReadings   = "readings"
+	stageAlerts     = "alerts"
+	stageOffsets    = "offsets"
+	stageDeadLetter = "dead_letter"
+	stageMetrics    = "metrics"
+	stageOrdering   = "ordering"
+)
+
+// stageRegistry creates the stages by the name they are listed under in IOT_PIPELINE_STAGES.
+// A new stage only needs to be registered here.
+var stageRegistry = map[string]func(c *impl) Stage{
+	stageJSON:       func(c *impl) Stage { return newStage(stageJSON, PhaseDecode, c.decodeJSON) },
+	stageRequired:   func(c *impl) Stage { return newStage(stageRequired, PhaseValidate, c.validateRequired) },
+	stageReadings:   func(c *impl) Stage { return newStage(stageReadings, PhasePersist, c.persistReadings) },
+	stageAlerts:     func(c *impl) Stage { return newStage(stageAlerts, PhasePersist, c.persistAlerts) },
+	stageOffsets:    func(c *impl) Stage { return newStage(stageOffsets, PhasePersist, c.persistOffsets) },
+	stageDeadLetter: func(c *impl) Stage { return newStage(stageDeadLetter, PhasePublish, c.publishDeadLetters) },
+	stageMetrics:    func(c *impl) Stage { return newStage(stageMetrics, PhaseNotify, c.notifyMetrics) },
+	stageOrdering:   func(c *impl) Stage { return newStage(stageOrdering, PhaseNotify, c.notifyOrdering) },
+}
+
+// funcStage is a stage backed by a func
+type funcStage struct {
+	name    string
+	phase   Phase
+	process func(ctx context.Context, b *Batch) error
+}
+
+func newStage(name string, phase Phase, process func(ctx context.Context, b *Batch) error) Stage {
+	return funcStage{name: name, phase: phase, process: process}
+}
+
+func (s funcStage) Name() string                                { return s.name }
+func (s funcStage) Phase() Phase                                { return s.phase }
+func (s funcStage) Process(ctx context.Context, b *Batch) error { return s.process(ctx, b) }
+
+// decodeJSON unmarshals the payloads into readings
+func (c *impl) decodeJSON(_ context.Context, b *Batch) error {
+	for _, r := range b.Accepted() {
+		if err := json.Unmarshal(r.Msg.Value, &r.Data); err != nil {
+			log.Printf("Error unmarshaling message: %v", err)
+			c.incrementFailedRecords()
+			r.Reject(stageJSON, err)
+			continue
+		}
+		r.Reading = readingOf(r.Data)
+		r.Reading.CreatedAt = b.ReceivedAt
+		r.Reading.MessageID = messageID(r.Data, r.Msg)
+	}
+	return nil
+}
+
+// readingOf maps a message to its reading, the ingestion fields are left to the caller
+func readingOf(msg model.IoTDataMessage) model.SensorReading {
+	return model.SensorReading{
+		DeviceID:    msg.DeviceID,
+		DeviceName:  msg.DeviceName,
+		DeviceType:  msg.DeviceType,
+		Location:    msg.Location,
+		Floor:       msg.Floor,
+		Zone:        msg.Zone,
+		Temperature: msg.Temperature,
+		Humidity:    msg.Humidity,
+		CO2:         msg.CO2,
+		Timestamp:   msg.Timestamp,
+	}
+}
+
+// validateRequired rejects the readings without a device or a time
+func (c *impl) validateRequired(_ context.Context, b *Batch) error {
+	for _, r := range b.Accepted() {
+		var err error
+		switch {
+		case r.Reading.DeviceID == "":
+			err = errors.New("device_id is required")
+		case r.Reading.Timestamp.IsZero():
+			err = errors.New("timestamp is required")
+		default:
+			continue
+		}
+		log.Printf("Invalid reading at %s[%d]@%d: %v", r.Msg.ID.Topic, r.Msg.ID.Partition, r.Msg.ID.Offset, err)
+		c.incrementFailedRecords()
+		r.Reject(stageRequired, err)
+	}
+	return nil
+}
+
+// persistReadings inserts the readings
+func (c *impl) persistReadings(ctx context.Context, b *Batch) error {
+	readings := b.Readings()
+	if len(readings) == 0 {
+		return nil
+	}
+	return b.Repo.IoT().BatchInsertReadings(ctx, readings)
+}
+
+// persistAlerts raises and resolves the alerts of the readings, the breaches they start or end are only
+// recorded once the batch is committed
+func (c *impl) persistAlerts(ctx context.Context, b *Batch) error {
+	readings := b.Readings()
+	if len(readings) == 0 {
+		return nil
+	}
+	raised, changes, err := c.evaluateAlerts(ctx, b.Repo, readings)
+	if err != nil {
+		return err
+	}
+	b.AlertsRaised = raised
+	b.OnCommit(func() { c.alertEval.apply(changes) })
+	return nil
+}
+
+// persistOffsets stores the offsets of the batch with its readings when the offset store is the DB.
+// The rejected records are only dead lettered once the batch is committed, so the offsets stop at the
+// first of them until publishDeadLetters moves them past it, a crash in between has them consumed again.
+func (c *impl) persistOffsets(ctx context.Context, b *Batch) error {
+	if !c.offsetStore {
+		return nil
+	}
+	return b.Repo.Offset().SaveOffsets(ctx, c.storedOffsets(b))
+}
+
+// storedOffsets returns the offsets to store in the batch transaction, up to the first rejected
+// record of each partition when the rejected records are dead lettered
+func (c *impl) storedOffsets(b *Batch) []model.ConsumerOffset {
+	msgs := make([]kafka.ConsumerMessage, len(b.Records))
+	for i, r := range b.Records {
+		msgs[i] = r.Msg
+	}
+	offsets := nextOffsets(msgs)
+	if c.deadLetter == nil {
+		return offsets
+	}
+
+	for _, r := range b.Rejected() {
+		for i := range offsets {
+			if offsets[i].Topic == r.Msg.ID.Topic && offsets[i].Partition == r.Msg.ID.Partition && r.Msg.ID.Offset < offsets[i].Offset {
+				offsets[i].Offset = r.Msg.ID.Offset
+			}
+		}
+	}
+	return offsets
+}
+
+// publishDeadLetters publishes the rejected records to the dead letter topic once the batch is committed,
+// so a rolled back batch publishes nothing. A failure has the batch retried and they may be published twice.
+// With the DB offset store the offsets then move past them, see persistOffsets.
+func (c *impl) publishDeadLetters(ctx context.Context, b *Batch) error {
+	if c.deadLetter == nil {
+		return nil
+	}
+	rejected := b.Rejected()
+	for _, r := range rejected {
+		if err := c.deadLetter.PublishDeadLetter(ctx, r.Msg, r.Err, 1); err != nil {
+			return fmt.Errorf("failed to dead letter message: %w", err)
+		}
+	}
+	if !c.offsetStore || len(rejected) == 0 {
+		return nil
+	}
+
+	msgs := make([]kafka.ConsumerMessage, len(b.Records))
+	for i, r := range b.Records {
+		msgs[i] = r.Msg
+	}
+	if err := b.Repo.Offset().SaveOffsets(ctx, nextOffsets(msgs)); err != nil {
+		return fmt.Errorf("failed to store the offsets past the dead letters: %w", err)
+	}
+	return nil
+}
+
+// notifyMetrics records the metrics of the committed readings
+func (c *impl) notifyMetrics(ctx context.Context, b *Batch) error {
+	readings := b.Readings()
+	if len(readings) == 0 {
+		return nil
+	}
+
+	for _, r := range readings {
+		obsmetrics.SensorReadings.WithLabelValues(r.DeviceID, "temperature").Set(r.Temperature)
+		obsmetrics.SensorReadings.WithLabelValues(r.DeviceID, "humidity").Set(r.Humidity)
+		obsmetrics.SensorReadings.WithLabelValues(r.DeviceID, "co2").Set(r.CO2)
+		obsmetrics.MessagesProcessed.WithLabelValues(r.DeviceID, "success").Inc()
+	}
+
+	latency := time.Since(b.ReceivedAt)
+	endToEndLatency := time.Since(readings[0].CreatedAt)
+	c.updateMetrics(len(readings), len(b.Records), latency, endToEndLatency)
+	for i := 0; i < b.AlertsRaised; i++ {
+		c.promMetrics.RecordAlertGenerated()
+	}
+	obsmetrics.ProcessingLatency.WithLabelValues("batch").Observe(latency.Seconds())
+
+	return c.SaveMetrics(ctx)
+}
+
+// notifyOrdering counts the readings consumed after a newer reading of their device
+func (c *impl) notifyOrdering(_ context.Context, b *Batch) error {
+	if violations := c.ordering.check(b.Readings()); violations > 0 {
+		log.Printf("[HandleBatch] %d readings consumed after a newer reading of their device", violations)
+		c.promMetrics.RecordOrderingViolations(violations)
+	}
+	return nil
+}
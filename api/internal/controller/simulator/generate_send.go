@@ -9,21 +9,28 @@ import (
 
 	"github.com/nhan1603/IoTsystem/api/internal/model"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/uid"
 )
 
 // sendMessage send the message to kafka
-func sendMessage(ctx context.Context, message model.IoTDataMessage, topic string, producer *kafka.SyncProducer) error {
-	b, err := json.Marshal(message)
+func sendMessage(ctx context.Context, message model.IoTDataMessage, topic string, send Sender) error {
+	// Lets the consumer dedupe the message even if the producer sends it twice
+	id, err := uid.Generate()
 	if err != nil {
-		return fmt.Errorf("marshal input failed: %w", err)
+		return fmt.Errorf("generate message id failed: %w", err)
 	}
+	message.MessageID = id
 
-	_, _, err = producer.SendMessage(ctx, topic, b, kafka.ProducerMessageOption{})
+	b, err := json.Marshal(message)
 	if err != nil {
-		return err
+		return fmt.Errorf("marshal input failed: %w", err)
 	}
 
-	return nil
+	// Keyed by device so its readings stay on one partition and are consumed in order
+	return send(ctx, topic, b, kafka.ProducerMessageOption{
+		Key:          message.DeviceID,
+		PartitionKey: fmt.Sprintf("%d/%d", message.Floor, message.Zone),
+	})
 }
 
 // generateSensorReading generates a random sensor reading for a device
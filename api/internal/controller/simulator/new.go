@@ -11,21 +11,24 @@ type Controller interface {
 	Simulate(ctx context.Context)
 }
 
+// Sender sends a message to Kafka, returning once it is delivered or only queued depending on the producer
+type Sender func(ctx context.Context, topic string, payload []byte, opt kafka.ProducerMessageOption) error
+
 // New initializes a new Controller instance and returns it
 func New(
 	repo repository.Registry,
-	producer *kafka.SyncProducer,
+	send Sender,
 	topic string,
 ) Controller {
 	return impl{
-		repo:     repo,
-		producer: producer,
-		topic:    topic,
+		repo:  repo,
+		send:  send,
+		topic: topic,
 	}
 }
 
 type impl struct {
-	repo     repository.Registry
-	producer *kafka.SyncProducer
-	topic    string
+	repo  repository.Registry
+	send  Sender
+	topic string
 }
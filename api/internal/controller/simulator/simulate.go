@@ -10,7 +10,6 @@ import (
 
 	"github.com/nhan1603/IoTsystem/api/internal/model"
 	"github.com/nhan1603/IoTsystem/api/internal/pkg/env"
-	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
 )
 
 const sensorInterval = 1 // seconds
@@ -31,7 +30,7 @@ func (i impl) Simulate(ctx context.Context) {
 	done := make(chan struct{})
 
 	go func() {
-		executeSensorSimulation(ctxCancel, listDevices, sensorInterval*time.Second, i.topic, i.producer)
+		executeSensorSimulation(ctxCancel, listDevices, sensorInterval*time.Second, i.topic, i.send)
 		close(done)
 	}()
 
@@ -45,7 +44,7 @@ func (i impl) Simulate(ctx context.Context) {
 }
 
 // executeSensorSimulation sends random sensor data at intervals for each device
-func executeSensorSimulation2(ctx context.Context, listDevices []model.IoTDevice, interval time.Duration, topic string, producer *kafka.SyncProducer) {
+func executeSensorSimulation2(ctx context.Context, listDevices []model.IoTDevice, interval time.Duration, topic string, send Sender) {
 	var sentCount int64
 	batchSize, _ := strconv.Atoi(env.GetwithDefault("PRODUCER_RATE", "100"))
 	generate := func() {
@@ -57,7 +56,7 @@ func executeSensorSimulation2(ctx context.Context, listDevices []model.IoTDevice
 				reading := generateSensorReading(device)
 				// b, _ := json.Marshal(reading)
 				// fmt.Println(string(b))
-				_ = sendMessage(ctx, reading, topic, producer)
+				_ = sendMessage(ctx, reading, topic, send)
 				newCount := atomic.AddInt64(&sentCount, 1)
 				if newCount%1000 == 0 {
 					log.Printf("[Simulator] Total messages sent: %d", newCount)
@@ -70,7 +69,7 @@ func executeSensorSimulation2(ctx context.Context, listDevices []model.IoTDevice
 	}
 }
 
-func executeSensorSimulation(ctx context.Context, listDevices []model.IoTDevice, interval time.Duration, topic string, producer *kafka.SyncProducer) {
+func executeSensorSimulation(ctx context.Context, listDevices []model.IoTDevice, interval time.Duration, topic string, send Sender) {
 	var sentCount int64
 	batchSize, _ := strconv.Atoi(env.GetwithDefault("PRODUCER_RATE", "100"))
 
@@ -89,7 +88,7 @@ func executeSensorSimulation(ctx context.Context, listDevices []model.IoTDevice,
 				for i := 0; i < batchSize; i++ {
 					device := listDevices[i%len(listDevices)]
 					reading := generateSensorReading(device)
-					if err := sendMessage(ctx, reading, topic, producer); err != nil {
+					if err := sendMessage(ctx, reading, topic, send); err != nil {
 						log.Printf("[Simulator] Error sending message: %v", err)
 						continue
 					}
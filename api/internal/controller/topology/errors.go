@@ -0,0 +1,14 @@
+package topology
+
+import "errors"
+
+var (
+	// ErrFloorNotFound means the floor does not exist
+	ErrFloorNotFound = errors.New("floor not found")
+	// ErrFloorAlreadyExists means another floor has the same floor number
+	ErrFloorAlreadyExists = errors.New("floor already exists")
+	// ErrZoneNotFound means the zone does not exist on the floor
+	ErrZoneNotFound = errors.New("zone not found")
+	// ErrZoneAlreadyExists means another zone of the floor has the same name
+	ErrZoneAlreadyExists = errors.New("zone already exists")
+)
@@ -0,0 +1,90 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+)
+
+// GetFloors retrieves all floors of the building
+func (i impl) GetFloors(ctx context.Context) ([]model.Floor, error) {
+	floors, err := i.repo.Topology().GetFloors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get floors: %w", err)
+	}
+	return floors, nil
+}
+
+// GetFloorTree retrieves a floor with its zones and the devices placed in each zone
+func (i impl) GetFloorTree(ctx context.Context, floorID int) (model.FloorTree, error) {
+	floor, err := i.getFloor(ctx, floorID)
+	if err != nil {
+		return model.FloorTree{}, err
+	}
+
+	zones, err := i.repo.Topology().GetZones(ctx, floorID)
+	if err != nil {
+		return model.FloorTree{}, fmt.Errorf("failed to get zones of floor %d: %w", floorID, err)
+	}
+
+	devices, err := i.repo.Topology().GetFloorDevices(ctx, floorID)
+	if err != nil {
+		return model.FloorTree{}, fmt.Errorf("failed to get devices of floor %d: %w", floorID, err)
+	}
+
+	byZone := make(map[int][]model.IoTDevice, len(zones))
+	for _, d := range devices {
+		byZone[d.Zone] = append(byZone[d.Zone], d)
+	}
+
+	tree := model.FloorTree{Floor: floor, Zones: make([]model.ZoneTree, len(zones))}
+	for idx, z := range zones {
+		tree.Zones[idx] = model.ZoneTree{Zone: z, Devices: byZone[z.ID]}
+	}
+
+	return tree, nil
+}
+
+// CreateFloor adds a floor to the building
+func (i impl) CreateFloor(ctx context.Context, floor model.Floor) (model.Floor, error) {
+	created, err := i.repo.Topology().CreateFloor(ctx, floor)
+	if err != nil {
+		if errors.Is(err, topology.ErrAlreadyExists) {
+			return model.Floor{}, ErrFloorAlreadyExists
+		}
+		return model.Floor{}, fmt.Errorf("failed to create floor %d: %w", floor.FloorNumber, err)
+	}
+
+	log.Printf("[CreateFloor] created floor %d with number %d", created.ID, created.FloorNumber)
+	return created, nil
+}
+
+// UpdateFloor updates the number, description and area of a floor
+func (i impl) UpdateFloor(ctx context.Context, floor model.Floor) (model.Floor, error) {
+	updated, err := i.repo.Topology().UpdateFloor(ctx, floor)
+	if err != nil {
+		switch {
+		case errors.Is(err, topology.ErrNotFound):
+			return model.Floor{}, ErrFloorNotFound
+		case errors.Is(err, topology.ErrAlreadyExists):
+			return model.Floor{}, ErrFloorAlreadyExists
+		}
+		return model.Floor{}, fmt.Errorf("failed to update floor %d: %w", floor.ID, err)
+	}
+	return updated, nil
+}
+
+func (i impl) getFloor(ctx context.Context, floorID int) (model.Floor, error) {
+	floor, err := i.repo.Topology().GetFloor(ctx, floorID)
+	if err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return model.Floor{}, ErrFloorNotFound
+		}
+		return model.Floor{}, fmt.Errorf("failed to get floor %d: %w", floorID, err)
+	}
+	return floor, nil
+}
@@ -0,0 +1,31 @@
+package topology
+
+import (
+	"context"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+)
+
+// Controller represents the specification of this pkg
+type Controller interface {
+	GetFloors(ctx context.Context) ([]model.Floor, error)
+	GetFloorTree(ctx context.Context, floorID int) (model.FloorTree, error)
+	CreateFloor(ctx context.Context, floor model.Floor) (model.Floor, error)
+	UpdateFloor(ctx context.Context, floor model.Floor) (model.Floor, error)
+	GetZones(ctx context.Context, floorID int) ([]model.Zone, error)
+	GetZoneTree(ctx context.Context, floorID, zoneID int) (model.ZoneTree, error)
+	CreateZone(ctx context.Context, zone model.Zone) (model.Zone, error)
+	UpdateZone(ctx context.Context, zone model.Zone) (model.Zone, error)
+}
+
+// New initializes a new Controller instance and returns it
+func New(repo repository.Registry) Controller {
+	return impl{
+		repo: repo,
+	}
+}
+
+type impl struct {
+	repo repository.Registry
+}
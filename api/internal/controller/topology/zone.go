@@ -0,0 +1,82 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+)
+
+// GetZones retrieves the zones of a floor
+func (i impl) GetZones(ctx context.Context, floorID int) ([]model.Zone, error) {
+	if _, err := i.getFloor(ctx, floorID); err != nil {
+		return nil, err
+	}
+
+	zones, err := i.repo.Topology().GetZones(ctx, floorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zones of floor %d: %w", floorID, err)
+	}
+	return zones, nil
+}
+
+// GetZoneTree retrieves a zone with the devices placed in it
+func (i impl) GetZoneTree(ctx context.Context, floorID, zoneID int) (model.ZoneTree, error) {
+	zone, err := i.repo.Topology().GetZone(ctx, floorID, zoneID)
+	if err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return model.ZoneTree{}, ErrZoneNotFound
+		}
+		return model.ZoneTree{}, fmt.Errorf("failed to get zone %d of floor %d: %w", zoneID, floorID, err)
+	}
+
+	devices, err := i.repo.Topology().GetFloorDevices(ctx, floorID)
+	if err != nil {
+		return model.ZoneTree{}, fmt.Errorf("failed to get devices of floor %d: %w", floorID, err)
+	}
+
+	tree := model.ZoneTree{Zone: zone}
+	for _, d := range devices {
+		if d.Zone == zoneID {
+			tree.Devices = append(tree.Devices, d)
+		}
+	}
+
+	return tree, nil
+}
+
+// CreateZone adds a zone to an existing floor
+func (i impl) CreateZone(ctx context.Context, zone model.Zone) (model.Zone, error) {
+	if _, err := i.getFloor(ctx, zone.FloorID); err != nil {
+		return model.Zone{}, err
+	}
+
+	created, err := i.repo.Topology().CreateZone(ctx, zone)
+	if err != nil {
+		if errors.Is(err, topology.ErrAlreadyExists) {
+			return model.Zone{}, ErrZoneAlreadyExists
+		}
+		return model.Zone{}, fmt.Errorf("failed to create zone %s of floor %d: %w", zone.Name, zone.FloorID, err)
+	}
+
+	log.Printf("[CreateZone] created zone %d %q on floor %d", created.ID, created.Name, created.FloorID)
+	return created, nil
+}
+
+// UpdateZone updates the name, type, description and area of a zone
+func (i impl) UpdateZone(ctx context.Context, zone model.Zone) (model.Zone, error) {
+	updated, err := i.repo.Topology().UpdateZone(ctx, zone)
+	if err != nil {
+		switch {
+		case errors.Is(err, topology.ErrNotFound):
+			return model.Zone{}, ErrZoneNotFound
+		case errors.Is(err, topology.ErrAlreadyExists):
+			return model.Zone{}, ErrZoneAlreadyExists
+		}
+		return model.Zone{}, fmt.Errorf("failed to update zone %d of floor %d: %w", zone.ID, zone.FloorID, err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+// PartitionLagResponse represents the lag of a single partition, committed_offset is -1 when nothing was committed yet
+type PartitionLagResponse struct {
+	Topic           string `json:"topic"`
+	Partition       int32  `json:"partition"`
+	HighWaterMark   int64  `json:"high_water_mark"`
+	CommittedOffset int64  `json:"committed_offset"`
+	Lag             int64  `json:"lag"`
+}
+
+// ConsumerLagResponse represents the last lag computed for the ingestion consumer group
+type ConsumerLagResponse struct {
+	GroupID    string                 `json:"group_id"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	TotalLag   int64                  `json:"total_lag"`
+	Partitions []PartitionLagResponse `json:"partitions"`
+}
+
+// GetConsumerLag returns the last computed lag of the ingestion consumer
+func (h Handler) GetConsumerLag() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		if h.lagTracker == nil {
+			return webErrLagUnavailable
+		}
+
+		snapshot := h.lagTracker.Snapshot()
+		if snapshot.UpdatedAt.IsZero() {
+			return webErrLagUnavailable
+		}
+
+		httpserver.RespondJSON(w, toConsumerLagResponse(snapshot))
+
+		return nil
+	})
+}
+
+func toConsumerLagResponse(s kafka.LagSnapshot) ConsumerLagResponse {
+	resp := ConsumerLagResponse{
+		GroupID:    s.GroupID,
+		UpdatedAt:  s.UpdatedAt,
+		TotalLag:   s.Total(),
+		Partitions: make([]PartitionLagResponse, 0, len(s.Partitions)),
+	}
+	for _, p := range s.Partitions {
+		resp.Partitions = append(resp.Partitions, PartitionLagResponse{
+			Topic:           p.Topic,
+			Partition:       p.Partition,
+			HighWaterMark:   p.HighWaterMark,
+			CommittedOffset: p.CommittedOffset,
+			Lag:             p.Lag,
+		})
+	}
+	return resp
+}
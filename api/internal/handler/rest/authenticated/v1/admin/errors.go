@@ -0,0 +1,12 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+)
+
+// Web errors
+var (
+	webErrLagUnavailable = &httpserver.Error{Status: http.StatusServiceUnavailable, Code: "lag_unavailable", Desc: "consumer lag has not been computed yet"}
+)
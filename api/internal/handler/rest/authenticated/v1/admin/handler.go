@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/kafka"
+)
+
+// Handler is the web handler for this pkg
+type Handler struct {
+	lagTracker *kafka.LagTracker
+}
+
+// New instantiates a new Handler and returns it
+func New(lagTracker *kafka.LagTracker) Handler {
+	return Handler{lagTracker: lagTracker}
+}
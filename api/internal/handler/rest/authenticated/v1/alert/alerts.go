@@ -0,0 +1,147 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// AlertResponse represents a single alert in the response, unreached state times are omitted
+type AlertResponse struct {
+	ID             int64      `json:"id,string"`
+	RuleID         int64      `json:"rule_id,string"`
+	DeviceID       string     `json:"device_id"`
+	Metric         string     `json:"metric"`
+	Value          float64    `json:"value"`
+	Threshold      float64    `json:"threshold"`
+	Status         string     `json:"status"`
+	TriggeredAt    time.Time  `json:"triggered_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// GetAlertsResponse represents result of listing alerts
+type GetAlertsResponse struct {
+	Data []AlertResponse `json:"data"`
+}
+
+// GetAlerts returns the alerts matching the query params, newest first
+func (h Handler) GetAlerts() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		input, webErr := parseGetAlertsInput(r.URL.Query())
+		if webErr != nil {
+			return webErr
+		}
+
+		alerts, err := h.alertCtrl.GetAlerts(r.Context(), input)
+		if err != nil {
+			return toAlertWebErr("GetAlerts", err)
+		}
+
+		resp := GetAlertsResponse{Data: make([]AlertResponse, len(alerts))}
+		for i, a := range alerts {
+			resp.Data[i] = toAlertResponse(a)
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// GetAlert returns the alert in the path
+func (h Handler) GetAlert() http.HandlerFunc {
+	return h.alertByID("GetAlert", h.alertCtrl.GetAlert)
+}
+
+// AcknowledgeAlert acknowledges the open alert in the path
+func (h Handler) AcknowledgeAlert() http.HandlerFunc {
+	return h.alertByID("AcknowledgeAlert", h.alertCtrl.AcknowledgeAlert)
+}
+
+// ResolveAlert resolves the alert in the path
+func (h Handler) ResolveAlert() http.HandlerFunc {
+	return h.alertByID("ResolveAlert", h.alertCtrl.ResolveAlert)
+}
+
+func (h Handler) alertByID(op string, fn func(ctx context.Context, id int64) (model.Alert, error)) http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		id, webErr := parsePathID(r, webErrInvalidAlertID)
+		if webErr != nil {
+			return webErr
+		}
+
+		a, err := fn(r.Context(), id)
+		if err != nil {
+			return toAlertWebErr(op, err)
+		}
+
+		httpserver.RespondJSON(w, toAlertResponse(a))
+
+		return nil
+	})
+}
+
+// parseGetAlertsInput reads and validates the alert filters from the query params,
+// status may be given as a comma separated list
+func parseGetAlertsInput(q url.Values) (model.GetAlertsInput, *httpserver.Error) {
+	input := model.GetAlertsInput{
+		DeviceID: strings.TrimSpace(q.Get("device_id")),
+		Limit:    model.PaginationDefaultLimit,
+	}
+
+	if v := q.Get("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			status := model.AlertStatus(strings.ToLower(strings.TrimSpace(s)))
+			switch status {
+			case model.AlertStatusOpen, model.AlertStatusAcknowledged, model.AlertStatusResolved:
+			default:
+				return model.GetAlertsInput{}, webErrInvalidStatus
+			}
+			input.Statuses = append(input.Statuses, status)
+		}
+	}
+
+	var err error
+	if v := q.Get("rule_id"); v != "" {
+		if input.RuleID, err = strconv.ParseInt(v, 10, 64); err != nil || input.RuleID <= 0 {
+			return model.GetAlertsInput{}, webErrInvalidRuleID
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil || input.Limit <= 0 || input.Limit > model.PaginationMaxLimit {
+			return model.GetAlertsInput{}, webErrInvalidLimit
+		}
+	}
+
+	return input, nil
+}
+
+func toAlertResponse(a model.Alert) AlertResponse {
+	resp := AlertResponse{
+		ID:          a.ID,
+		RuleID:      a.RuleID,
+		DeviceID:    a.DeviceID,
+		Metric:      string(a.Metric),
+		Value:       a.Value,
+		Threshold:   a.Threshold,
+		Status:      a.Status.ToString(),
+		TriggeredAt: a.TriggeredAt,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	if !a.AcknowledgedAt.IsZero() {
+		resp.AcknowledgedAt = &a.AcknowledgedAt
+	}
+	if !a.ResolvedAt.IsZero() {
+		resp.ResolvedAt = &a.ResolvedAt
+	}
+	return resp
+}
@@ -0,0 +1,34 @@
+package alert
+
+import (
+	"net/http"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+)
+
+const (
+	// ErrCodeValidationFailed represents the error code for a failed validation
+	ErrCodeValidationFailed = "validation_failed"
+)
+
+// Web errors
+var (
+	webErrInternalServer = &httpserver.Error{Status: http.StatusInternalServerError, Code: "internal_error", Desc: "Something went wrong, please check again."}
+
+	webErrInvalidRuleID     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid alert rule id"}
+	webErrInvalidRuleName   = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid alert rule name"}
+	webErrInvalidDeviceType = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid device type"}
+	webErrInvalidScope      = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid scope, zone requires floor"}
+	webErrInvalidMetric     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid metric, expected one of temperature, humidity, co2"}
+	webErrInvalidOperator   = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid operator, expected one of gt, gte, lt, lte"}
+	webErrInvalidDuration   = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid duration"}
+	webErrInvalidAlertID    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid alert id"}
+	webErrInvalidStatus     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid status, expected one of open, acknowledged, resolved"}
+	webErrInvalidLimit      = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid limit"}
+	webErrFloorNotFound     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "floor not found"}
+	webErrZoneNotFound      = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "zone not found on floor"}
+
+	webErrRuleNotFound      = &httpserver.Error{Status: http.StatusNotFound, Code: "not_found", Desc: "alert rule not found"}
+	webErrAlertNotFound     = &httpserver.Error{Status: http.StatusNotFound, Code: "not_found", Desc: "alert not found"}
+	webErrInvalidTransition = &httpserver.Error{Status: http.StatusConflict, Code: "invalid_transition", Desc: "alert can't move to this status"}
+)
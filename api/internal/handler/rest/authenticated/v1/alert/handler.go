@@ -0,0 +1,15 @@
+package alert
+
+import (
+	"github.com/nhan1603/IoTsystem/api/internal/controller/alert"
+)
+
+// Handler is the web handler for this pkg
+type Handler struct {
+	alertCtrl alert.Controller
+}
+
+// New instantiates a new Handler and returns it
+func New(alertCtrl alert.Controller) Handler {
+	return Handler{alertCtrl: alertCtrl}
+}
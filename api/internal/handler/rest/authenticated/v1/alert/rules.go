@@ -0,0 +1,236 @@
+package alert
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/alert"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// maxRuleDuration bounds how long a threshold may need to stay breached
+const maxRuleDuration = 24 * time.Hour
+
+// RuleRequest holds the input payload to create or update an alert rule
+type RuleRequest struct {
+	Name            string  `json:"name"`
+	DeviceID        string  `json:"device_id"`
+	DeviceType      string  `json:"device_type"`
+	FloorID         int     `json:"floor_id"`
+	ZoneID          int     `json:"zone_id"`
+	Metric          string  `json:"metric"`
+	Operator        string  `json:"operator"`
+	Threshold       float64 `json:"threshold"`
+	DurationSeconds int     `json:"duration_seconds"`
+	// Enabled defaults to true when omitted
+	Enabled *bool `json:"enabled"`
+}
+
+// RuleResponse represents a single alert rule in the response
+type RuleResponse struct {
+	ID              int64     `json:"id,string"`
+	Name            string    `json:"name"`
+	DeviceID        string    `json:"device_id"`
+	DeviceType      string    `json:"device_type"`
+	FloorID         int       `json:"floor_id"`
+	ZoneID          int       `json:"zone_id"`
+	Metric          string    `json:"metric"`
+	Operator        string    `json:"operator"`
+	Threshold       float64   `json:"threshold"`
+	DurationSeconds int       `json:"duration_seconds"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GetRulesResponse represents result of listing alert rules
+type GetRulesResponse struct {
+	Data []RuleResponse `json:"data"`
+}
+
+// GetRules returns all alert rules
+func (h Handler) GetRules() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		rules, err := h.alertCtrl.GetRules(r.Context())
+		if err != nil {
+			log.Printf("[GetRules] failed to get alert rules. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+
+		resp := GetRulesResponse{Data: make([]RuleResponse, len(rules))}
+		for i, rule := range rules {
+			resp.Data[i] = toRuleResponse(rule)
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// GetRule returns the alert rule in the path
+func (h Handler) GetRule() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		id, webErr := parsePathID(r, webErrInvalidRuleID)
+		if webErr != nil {
+			return webErr
+		}
+
+		rule, err := h.alertCtrl.GetRule(r.Context(), id)
+		if err != nil {
+			return toAlertWebErr("GetRule", err)
+		}
+
+		httpserver.RespondJSON(w, toRuleResponse(rule))
+
+		return nil
+	})
+}
+
+// CreateRule adds an alert rule
+func (h Handler) CreateRule() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		var req RuleRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[CreateRule] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		rule, webErr := validateRuleRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+
+		created, err := h.alertCtrl.CreateRule(r.Context(), rule)
+		if err != nil {
+			return toAlertWebErr("CreateRule", err)
+		}
+
+		httpserver.RespondJSON(w, toRuleResponse(created))
+
+		return nil
+	})
+}
+
+// UpdateRule overwrites the alert rule in the path
+func (h Handler) UpdateRule() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		id, webErr := parsePathID(r, webErrInvalidRuleID)
+		if webErr != nil {
+			return webErr
+		}
+
+		var req RuleRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[UpdateRule] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		rule, webErr := validateRuleRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+		rule.ID = id
+
+		updated, err := h.alertCtrl.UpdateRule(r.Context(), rule)
+		if err != nil {
+			return toAlertWebErr("UpdateRule", err)
+		}
+
+		httpserver.RespondJSON(w, toRuleResponse(updated))
+
+		return nil
+	})
+}
+
+// validateRuleRequest checks the payload and converts it to an alert rule
+func validateRuleRequest(req RuleRequest) (model.AlertRule, *httpserver.Error) {
+	rule := model.AlertRule{
+		Name:       strings.TrimSpace(req.Name),
+		DeviceID:   strings.TrimSpace(req.DeviceID),
+		DeviceType: strings.ToLower(strings.TrimSpace(req.DeviceType)),
+		Floor:      req.FloorID,
+		Zone:       req.ZoneID,
+		Metric:     model.AlertMetric(strings.ToLower(strings.TrimSpace(req.Metric))),
+		Operator:   model.AlertOperator(strings.ToLower(strings.TrimSpace(req.Operator))),
+		Threshold:  req.Threshold,
+		Duration:   time.Duration(req.DurationSeconds) * time.Second,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	if rule.Name == "" {
+		return model.AlertRule{}, webErrInvalidRuleName
+	}
+	if rule.DeviceType != "" {
+		switch model.DeviceType(rule.DeviceType) {
+		case model.DeviceTypeTemperature, model.DeviceTypeHumidity, model.DeviceTypeCO2, model.DeviceTypeMulti:
+		default:
+			return model.AlertRule{}, webErrInvalidDeviceType
+		}
+	}
+	if rule.Floor < 0 || rule.Zone < 0 || (rule.Zone > 0 && rule.Floor == 0) {
+		return model.AlertRule{}, webErrInvalidScope
+	}
+	if !rule.Metric.IsValid() {
+		return model.AlertRule{}, webErrInvalidMetric
+	}
+	if !rule.Operator.IsValid() {
+		return model.AlertRule{}, webErrInvalidOperator
+	}
+	if rule.Duration < 0 || rule.Duration > maxRuleDuration {
+		return model.AlertRule{}, webErrInvalidDuration
+	}
+
+	return rule, nil
+}
+
+// parsePathID reads a positive snowflake id from the path
+func parsePathID(r *http.Request, webErr *httpserver.Error) (int64, *httpserver.Error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, webErr
+	}
+	return id, nil
+}
+
+// toAlertWebErr maps the controller errors to web errors
+func toAlertWebErr(op string, err error) *httpserver.Error {
+	switch {
+	case errors.Is(err, alert.ErrRuleNotFound):
+		return webErrRuleNotFound
+	case errors.Is(err, alert.ErrAlertNotFound):
+		return webErrAlertNotFound
+	case errors.Is(err, alert.ErrInvalidTransition):
+		return webErrInvalidTransition
+	case errors.Is(err, alert.ErrFloorNotFound):
+		return webErrFloorNotFound
+	case errors.Is(err, alert.ErrZoneNotFound):
+		return webErrZoneNotFound
+	default:
+		log.Printf("[%s] failed. Err: %+v\n", op, err)
+		return webErrInternalServer
+	}
+}
+
+func toRuleResponse(rule model.AlertRule) RuleResponse {
+	return RuleResponse{
+		ID:              rule.ID,
+		Name:            rule.Name,
+		DeviceID:        rule.DeviceID,
+		DeviceType:      rule.DeviceType,
+		FloorID:         rule.Floor,
+		ZoneID:          rule.Zone,
+		Metric:          string(rule.Metric),
+		Operator:        string(rule.Operator),
+		Threshold:       rule.Threshold,
+		DurationSeconds: int(rule.Duration.Seconds()),
+		Enabled:         rule.Enabled,
+		CreatedAt:       rule.CreatedAt,
+		UpdatedAt:       rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,83 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRuleRequest(t *testing.T) {
+	disabled := false
+
+	tcs := map[string]struct {
+		givenReq RuleRequest
+		expRule  model.AlertRule
+		expErr   *httpserver.Error
+	}{
+		"zone_co2": {
+			givenReq: RuleRequest{
+				Name: " High CO2 ", FloorID: 1, ZoneID: 2, Metric: "CO2", Operator: "gt",
+				Threshold: 1000, DurationSeconds: 300,
+			},
+			expRule: model.AlertRule{
+				Name: "High CO2", Floor: 1, Zone: 2, Metric: model.AlertMetricCO2, Operator: model.AlertOperatorGT,
+				Threshold: 1000, Duration: 5 * time.Minute, Enabled: true,
+			},
+		},
+		"disabled_device_type": {
+			givenReq: RuleRequest{
+				Name: "Cold", DeviceType: "temperature", Metric: "temperature", Operator: "lte",
+				Threshold: 15, Enabled: &disabled,
+			},
+			expRule: model.AlertRule{
+				Name: "Cold", DeviceType: "temperature", Metric: model.AlertMetricTemperature,
+				Operator: model.AlertOperatorLTE, Threshold: 15,
+			},
+		},
+		"empty_name": {
+			givenReq: RuleRequest{Metric: "co2", Operator: "gt"},
+			expErr:   webErrInvalidRuleName,
+		},
+		"zone_without_floor": {
+			givenReq: RuleRequest{Name: "x", ZoneID: 2, Metric: "co2", Operator: "gt"},
+			expErr:   webErrInvalidScope,
+		},
+		"invalid_device_type": {
+			givenReq: RuleRequest{Name: "x", DeviceType: "pressure", Metric: "co2", Operator: "gt"},
+			expErr:   webErrInvalidDeviceType,
+		},
+		"invalid_metric": {
+			givenReq: RuleRequest{Name: "x", Metric: "pressure", Operator: "gt"},
+			expErr:   webErrInvalidMetric,
+		},
+		"invalid_operator": {
+			givenReq: RuleRequest{Name: "x", Metric: "co2", Operator: "eq"},
+			expErr:   webErrInvalidOperator,
+		},
+		"negative_duration": {
+			givenReq: RuleRequest{Name: "x", Metric: "co2", Operator: "gt", DurationSeconds: -1},
+			expErr:   webErrInvalidDuration,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			req := tc.givenReq
+
+			// When:
+			rule, webErr := validateRuleRequest(req)
+
+			// Then:
+			if tc.expErr != nil {
+				require.Equal(t, tc.expErr, webErr)
+				return
+			}
+			require.Nil(t, webErr)
+			require.Equal(t, tc.expRule, rule)
+		})
+	}
+}
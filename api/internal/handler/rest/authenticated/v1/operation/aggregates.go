@@ -0,0 +1,144 @@
+package operation
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+const (
+	defaultAggregateInterval = model.AggregateIntervalHour
+	defaultAggregateGroupBy  = model.AggregateGroupByDevice
+	// defaultAggregateRange is the time range looked back from end when start is not given
+	defaultAggregateRange = 24 * time.Hour
+)
+
+// MetricAggregateResponse represents the statistics of one metric
+type MetricAggregateResponse struct {
+	Avg float64 `json:"avg"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ReadingAggregateResponse represents the statistics of one group in one bucket
+type ReadingAggregateResponse struct {
+	Bucket      time.Time               `json:"bucket"`
+	DeviceID    string                  `json:"device_id,omitempty"`
+	Floor       int                     `json:"floor,omitempty"`
+	Zone        int                     `json:"zone,omitempty"`
+	Count       int64                   `json:"count"`
+	Temperature MetricAggregateResponse `json:"temperature"`
+	Humidity    MetricAggregateResponse `json:"humidity"`
+	CO2         MetricAggregateResponse `json:"co2"`
+}
+
+// GetReadingAggregatesResponse represents result of aggregating sensor readings
+type GetReadingAggregatesResponse struct {
+	Interval  model.AggregateInterval    `json:"interval"`
+	GroupBy   model.AggregateGroupBy     `json:"group_by"`
+	StartTime time.Time                  `json:"start"`
+	EndTime   time.Time                  `json:"end"`
+	Data      []ReadingAggregateResponse `json:"data"`
+}
+
+// GetReadingAggregates returns the avg/min/max of the readings per time bucket, for charts
+func (h Handler) GetReadingAggregates() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		input, webErr := parseAggregateReadingsInput(r.URL.Query(), time.Now().UTC())
+		if webErr != nil {
+			return webErr
+		}
+
+		aggs, err := h.iotCtrl.AggregateReadings(r.Context(), input)
+		if err != nil {
+			log.Printf("[GetReadingAggregates] failed to aggregate readings. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+
+		resp := GetReadingAggregatesResponse{
+			Interval:  input.Interval,
+			GroupBy:   input.GroupBy,
+			StartTime: input.StartTime,
+			EndTime:   input.EndTime,
+			Data:      make([]ReadingAggregateResponse, len(aggs)),
+		}
+		for i, a := range aggs {
+			resp.Data[i] = ReadingAggregateResponse{
+				Bucket:      a.Bucket,
+				DeviceID:    a.DeviceID,
+				Floor:       a.Floor,
+				Zone:        a.Zone,
+				Count:       a.Count,
+				Temperature: MetricAggregateResponse(a.Temperature),
+				Humidity:    MetricAggregateResponse(a.Humidity),
+				CO2:         MetricAggregateResponse(a.CO2),
+			}
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// parseAggregateReadingsInput reads and validates the aggregation params from the query params,
+// the time range defaults to the day before now
+func parseAggregateReadingsInput(q url.Values, now time.Time) (model.AggregateReadingsInput, *httpserver.Error) {
+	input := model.AggregateReadingsInput{
+		Interval: defaultAggregateInterval,
+		GroupBy:  defaultAggregateGroupBy,
+		DeviceID: strings.TrimSpace(q.Get("device_id")),
+		EndTime:  now,
+	}
+
+	if v := q.Get("interval"); v != "" {
+		input.Interval = model.AggregateInterval(strings.ToLower(v))
+		if input.Interval.Duration() == 0 {
+			return model.AggregateReadingsInput{}, webErrInvalidInterval
+		}
+	}
+	if v := q.Get("group_by"); v != "" {
+		input.GroupBy = model.AggregateGroupBy(strings.ToLower(v))
+		switch input.GroupBy {
+		case model.AggregateGroupByDevice, model.AggregateGroupByZone, model.AggregateGroupByFloor:
+		default:
+			return model.AggregateReadingsInput{}, webErrInvalidGroupBy
+		}
+	}
+
+	var err error
+	if v := q.Get("floor"); v != "" {
+		if input.Floor, err = strconv.Atoi(v); err != nil || input.Floor <= 0 {
+			return model.AggregateReadingsInput{}, webErrInvalidFloor
+		}
+	}
+	if v := q.Get("zone"); v != "" {
+		if input.Zone, err = strconv.Atoi(v); err != nil || input.Zone <= 0 {
+			return model.AggregateReadingsInput{}, webErrInvalidZone
+		}
+	}
+	if v := q.Get("end"); v != "" {
+		if input.EndTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return model.AggregateReadingsInput{}, webErrInvalidEndTime
+		}
+	}
+	input.StartTime = input.EndTime.Add(-defaultAggregateRange)
+	if v := q.Get("start"); v != "" {
+		if input.StartTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return model.AggregateReadingsInput{}, webErrInvalidStartTime
+		}
+	}
+	if !input.StartTime.Before(input.EndTime) {
+		return model.AggregateReadingsInput{}, webErrInvalidTimeRange
+	}
+	if input.EndTime.Sub(input.StartTime) > time.Duration(model.AggregateMaxBuckets)*input.Interval.Duration() {
+		return model.AggregateReadingsInput{}, webErrTooManyBuckets
+	}
+
+	return input, nil
+}
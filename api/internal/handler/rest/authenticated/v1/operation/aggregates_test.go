@@ -0,0 +1,83 @@
+package operation
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAggregateReadingsInput(t *testing.T) {
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tcs := map[string]struct {
+		givenQuery string
+		expInput   model.AggregateReadingsInput
+		expErr     *httpserver.Error
+	}{
+		"defaults": {
+			givenQuery: "",
+			expInput: model.AggregateReadingsInput{
+				Interval:  model.AggregateIntervalHour,
+				GroupBy:   model.AggregateGroupByDevice,
+				StartTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:   now,
+			},
+		},
+		"all_fields": {
+			givenQuery: "interval=5m&group_by=Zone&device_id=TEMP_001&floor=1&zone=2" +
+				"&start=2025-01-01T10:00:00Z&end=2025-01-01T12:00:00Z",
+			expInput: model.AggregateReadingsInput{
+				Interval:  model.AggregateIntervalFiveMinutes,
+				GroupBy:   model.AggregateGroupByZone,
+				DeviceID:  "TEMP_001",
+				Floor:     1,
+				Zone:      2,
+				StartTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		"invalid_interval": {
+			givenQuery: "interval=2m",
+			expErr:     webErrInvalidInterval,
+		},
+		"invalid_group_by": {
+			givenQuery: "group_by=building",
+			expErr:     webErrInvalidGroupBy,
+		},
+		"invalid_floor": {
+			givenQuery: "floor=0",
+			expErr:     webErrInvalidFloor,
+		},
+		"empty_range": {
+			givenQuery: "start=2025-01-01T10:00:00Z&end=2025-01-01T10:00:00Z",
+			expErr:     webErrInvalidTimeRange,
+		},
+		"too_many_buckets": {
+			givenQuery: "interval=1m&start=2024-12-30T00:00:00Z",
+			expErr:     webErrTooManyBuckets,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			q, err := url.ParseQuery(tc.givenQuery)
+			require.NoError(t, err)
+
+			// When:
+			input, webErr := parseAggregateReadingsInput(q, now)
+
+			// Then:
+			if tc.expErr != nil {
+				require.Equal(t, tc.expErr, webErr)
+				return
+			}
+			require.Nil(t, webErr)
+			require.Equal(t, tc.expInput, input)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/iot"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// DeviceRequest holds the input payload to register or update a device
+type DeviceRequest struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Location string `json:"location"`
+	FloorID  int    `json:"floor_id"`
+	ZoneID   int    `json:"zone_id"`
+}
+
+// DeviceResponse represents a single device in the response
+type DeviceResponse struct {
+	ID        int64     `json:"id"`
+	DeviceID  string    `json:"device_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Location  string    `json:"location"`
+	FloorID   int       `json:"floor_id"`
+	ZoneID    int       `json:"zone_id"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetDevicesResponse represents result of listing devices
+type GetDevicesResponse struct {
+	Data []DeviceResponse `json:"data"`
+}
+
+// GetDevices returns the active devices
+func (h Handler) GetDevices() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		devices, err := h.iotCtrl.GetDevices(r.Context())
+		if err != nil {
+			log.Printf("[GetDevices] failed to get devices. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+
+		resp := GetDevicesResponse{Data: make([]DeviceResponse, len(devices))}
+		for i, d := range devices {
+			resp.Data[i] = toDeviceResponse(d)
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// GetDevice returns the device in the path, active or not
+func (h Handler) GetDevice() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		deviceID := strings.TrimSpace(chi.URLParam(r, "id"))
+		if deviceID == "" {
+			return webErrInvalidDeviceID
+		}
+
+		device, err := h.iotCtrl.GetDevice(r.Context(), deviceID)
+		if err != nil {
+			return toDeviceWebErr("GetDevice", err)
+		}
+
+		httpserver.RespondJSON(w, toDeviceResponse(device))
+
+		return nil
+	})
+}
+
+// CreateDevice registers a new device
+func (h Handler) CreateDevice() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		var req DeviceRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[CreateDevice] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		device, webErr := validateDeviceRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+
+		created, err := h.iotCtrl.CreateDevice(r.Context(), device)
+		if err != nil {
+			return toDeviceWebErr("CreateDevice", err)
+		}
+
+		httpserver.RespondJSON(w, toDeviceResponse(created))
+
+		return nil
+	})
+}
+
+// UpdateDevice overwrites the device in the path
+func (h Handler) UpdateDevice() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		var req DeviceRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[UpdateDevice] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+		req.DeviceID = chi.URLParam(r, "id")
+
+		device, webErr := validateDeviceRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+
+		updated, err := h.iotCtrl.UpdateDevice(r.Context(), device)
+		if err != nil {
+			return toDeviceWebErr("UpdateDevice", err)
+		}
+
+		httpserver.RespondJSON(w, toDeviceResponse(updated))
+
+		return nil
+	})
+}
+
+// DeactivateDevice deactivates the device in the path
+func (h Handler) DeactivateDevice() http.HandlerFunc {
+	return h.setDeviceActive("DeactivateDevice", h.iotCtrl.DeactivateDevice)
+}
+
+// ReactivateDevice reactivates the device in the path
+func (h Handler) ReactivateDevice() http.HandlerFunc {
+	return h.setDeviceActive("ReactivateDevice", h.iotCtrl.ReactivateDevice)
+}
+
+func (h Handler) setDeviceActive(op string, fn func(ctx context.Context, deviceID string) error) http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		deviceID := strings.TrimSpace(chi.URLParam(r, "id"))
+		if deviceID == "" {
+			return webErrInvalidDeviceID
+		}
+
+		if err := fn(r.Context(), deviceID); err != nil {
+			return toDeviceWebErr(op, err)
+		}
+
+		httpserver.RespondJSON(w, httpserver.Success{Message: true})
+
+		return nil
+	})
+}
+
+// validateDeviceRequest checks the payload and converts it to a device
+func validateDeviceRequest(req DeviceRequest) (model.IoTDevice, *httpserver.Error) {
+	device := model.IoTDevice{
+		DeviceID: strings.TrimSpace(req.DeviceID),
+		Name:     strings.TrimSpace(req.Name),
+		Type:     strings.ToLower(strings.TrimSpace(req.Type)),
+		Location: strings.TrimSpace(req.Location),
+		Floor:    req.FloorID,
+		Zone:     req.ZoneID,
+	}
+
+	if device.DeviceID == "" {
+		return model.IoTDevice{}, webErrInvalidDeviceID
+	}
+	if device.Name == "" {
+		return model.IoTDevice{}, webErrInvalidDeviceName
+	}
+	switch model.DeviceType(device.Type) {
+	case model.DeviceTypeTemperature, model.DeviceTypeHumidity, model.DeviceTypeCO2, model.DeviceTypeMulti:
+	default:
+		return model.IoTDevice{}, webErrInvalidDeviceType
+	}
+	if device.Location == "" {
+		return model.IoTDevice{}, webErrInvalidDeviceLocation
+	}
+	if device.Floor <= 0 {
+		return model.IoTDevice{}, webErrInvalidFloor
+	}
+	if device.Zone <= 0 {
+		return model.IoTDevice{}, webErrInvalidZone
+	}
+
+	return device, nil
+}
+
+// toDeviceWebErr maps the controller errors to web errors
+func toDeviceWebErr(op string, err error) *httpserver.Error {
+	switch {
+	case errors.Is(err, iot.ErrDeviceNotFound):
+		return webErrDeviceNotFound
+	case errors.Is(err, iot.ErrDeviceAlreadyExists):
+		return webErrDeviceAlreadyExists
+	case errors.Is(err, iot.ErrFloorNotFound):
+		return webErrFloorNotFound
+	case errors.Is(err, iot.ErrZoneNotFound):
+		return webErrZoneNotFound
+	default:
+		log.Printf("[%s] failed. Err: %+v\n", op, err)
+		return webErrInternalServer
+	}
+}
+
+func toDeviceResponse(d model.IoTDevice) DeviceResponse {
+	return DeviceResponse{
+		ID:        d.ID,
+		DeviceID:  d.DeviceID,
+		Name:      d.Name,
+		Type:      d.Type,
+		Location:  d.Location,
+		FloorID:   d.Floor,
+		ZoneID:    d.Zone,
+		IsActive:  d.IsActive,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
@@ -0,0 +1,114 @@
+package operation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/iot"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceHandlers(t *testing.T) {
+	type call struct {
+		method string
+		path   string
+		body   string
+	}
+
+	tcs := map[string]struct {
+		givenCalls []call
+		expStatus  int
+		expBody    string
+	}{
+		"create": {
+			givenCalls: []call{{http.MethodPost, "/devices", `{"device_id":"TEMP_100","name":"Temperature Sensor 100","type":"Temperature","location":"Main Building","floor_id":2,"zone_id":4}`}},
+			expStatus:  http.StatusOK,
+			expBody:    `"device_id":"TEMP_100","name":"Temperature Sensor 100","type":"temperature","location":"Main Building","floor_id":2,"zone_id":4,"is_active":true`,
+		},
+		"create_conflict": {
+			givenCalls: []call{{http.MethodPost, "/devices", `{"device_id":"TEMP_001","name":"Temperature Sensor 1","type":"temperature","location":"Main Building","floor_id":1,"zone_id":1}`}},
+			expStatus:  http.StatusConflict,
+			expBody:    `"error":"already_exists"`,
+		},
+		"create_in_zone_of_another_floor": {
+			givenCalls: []call{{http.MethodPost, "/devices", `{"device_id":"TEMP_100","name":"Temperature Sensor 100","type":"temperature","location":"Main Building","floor_id":1,"zone_id":3}`}},
+			expStatus:  http.StatusBadRequest,
+			expBody:    `"error_description":"zone not found on floor"`,
+		},
+		"update": {
+			givenCalls: []call{{http.MethodPut, "/devices/TEMP_001", `{"name":"Lab Temperature","type":"temperature","location":"Main Building","floor_id":2,"zone_id":3}`}},
+			expStatus:  http.StatusOK,
+			expBody:    `"device_id":"TEMP_001","name":"Lab Temperature","type":"temperature","location":"Main Building","floor_id":2,"zone_id":3,"is_active":true`,
+		},
+		"update_missing": {
+			givenCalls: []call{{http.MethodPut, "/devices/TEMP_404", `{"name":"Ghost","type":"temperature","location":"Main Building","floor_id":1,"zone_id":1}`}},
+			expStatus:  http.StatusNotFound,
+			expBody:    `"error_description":"device not found"`,
+		},
+		"deactivate": {
+			givenCalls: []call{
+				{http.MethodPost, "/devices/TEMP_001/deactivate", ""},
+				{http.MethodGet, "/devices/TEMP_001", ""},
+			},
+			expStatus: http.StatusOK,
+			expBody:   `"device_id":"TEMP_001","name":"Temperature Sensor 1","type":"temperature","location":"Main Building","floor_id":1,"zone_id":1,"is_active":false`,
+		},
+		"reactivate": {
+			givenCalls: []call{
+				{http.MethodPost, "/devices/TEMP_001/deactivate", ""},
+				{http.MethodPost, "/devices/TEMP_001/reactivate", ""},
+				{http.MethodGet, "/devices/TEMP_001", ""},
+			},
+			expStatus: http.StatusOK,
+			expBody:   `"is_active":true`,
+		},
+		"deactivate_missing": {
+			givenCalls: []call{{http.MethodPost, "/devices/TEMP_404/deactivate", ""}},
+			expStatus:  http.StatusNotFound,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			router := newDeviceRouter(t)
+
+			// When:
+			var rec *httptest.ResponseRecorder
+			for _, c := range tc.givenCalls {
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+			}
+
+			// Then:
+			require.Equal(t, tc.expStatus, rec.Code)
+			require.Contains(t, rec.Body.String(), tc.expBody)
+		})
+	}
+}
+
+// newDeviceRouter routes the device handlers to a controller over the sample building of the seed migration
+func newDeviceRouter(t *testing.T) http.Handler {
+	repo, cleanup, err := repository.NewFromConfig(context.Background(), repository.Config{
+		Backend:    repository.BackendMemory,
+		MemorySeed: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	ctrl, err := iot.New(repo, nil, nil)
+	require.NoError(t, err)
+
+	h := New(ctrl)
+	r := chi.NewRouter()
+	r.Post("/devices", h.CreateDevice())
+	r.Get("/devices/{id}", h.GetDevice())
+	r.Put("/devices/{id}", h.UpdateDevice())
+	r.Post("/devices/{id}/deactivate", h.DeactivateDevice())
+	r.Post("/devices/{id}/reactivate", h.ReactivateDevice())
+	return r
+}
@@ -17,4 +17,26 @@ var (
 	webErrInvalidItem    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid data provided"}
 	webErrInvalidOrder   = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid request, invalid order"}
 	webErrInternalServer = &httpserver.Error{Status: http.StatusInternalServerError, Code: "internal_error", Desc: "Something went wrong, please check again."}
+
+	webErrInvalidDeviceID   = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid device id"}
+	webErrInvalidDeviceType = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid device type"}
+	webErrInvalidFloor      = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid floor"}
+	webErrInvalidZone       = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid zone"}
+	webErrInvalidStartTime  = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid start time, expected RFC3339"}
+	webErrInvalidEndTime    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid end time, expected RFC3339"}
+	webErrInvalidTimeRange  = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "start time must not be after end time"}
+	webErrInvalidLimit      = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid limit"}
+	webErrInvalidCursor     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid cursor"}
+	webErrFilterRequired    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "too many devices to list their readings together, filter them by device, type or location"}
+	webErrInvalidWithTotal  = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid with_total, expected true or false"}
+	webErrInvalidInterval   = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid interval, expected one of 1m, 5m, 1h, 1d"}
+	webErrInvalidGroupBy    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid group by, expected one of device, zone, floor"}
+	webErrTooManyBuckets    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "time range spans too many buckets, use a larger interval"}
+
+	webErrInvalidDeviceName     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid device name"}
+	webErrInvalidDeviceLocation = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid device location"}
+	webErrFloorNotFound         = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "floor not found"}
+	webErrZoneNotFound          = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "zone not found on floor"}
+	webErrDeviceNotFound        = &httpserver.Error{Status: http.StatusNotFound, Code: "not_found", Desc: "device not found"}
+	webErrDeviceAlreadyExists   = &httpserver.Error{Status: http.StatusConflict, Code: "already_exists", Desc: "device already exists"}
 )
@@ -0,0 +1,231 @@
+package operation
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/iot"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// ReadingResponse represents a single sensor reading in the response
+type ReadingResponse struct {
+	ID          int64     `json:"id"`
+	DeviceID    string    `json:"device_id"`
+	DeviceName  string    `json:"device_name"`
+	DeviceType  string    `json:"device_type"`
+	Location    string    `json:"location"`
+	Floor       int       `json:"floor"`
+	Zone        int       `json:"zone"`
+	Temperature float64   `json:"temperature"`
+	Humidity    float64   `json:"humidity"`
+	CO2         float64   `json:"co2"`
+	Timestamp   time.Time `json:"timestamp"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GetReadingsResponse represents result of querying sensor readings
+type GetReadingsResponse struct {
+	Data       []ReadingResponse `json:"data"`
+	Pagination model.Pagination  `json:"pagination"`
+}
+
+// GetReadings returns the sensor readings matching the query params
+func (h Handler) GetReadings() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		input, webErr := parseGetReadingsInput(r.URL.Query())
+		if webErr != nil {
+			return webErr
+		}
+		withTotal, webErr := parseWithTotal(r.URL.Query())
+		if webErr != nil {
+			return webErr
+		}
+
+		readings, nextCursor, err := h.iotCtrl.GetReadings(r.Context(), input)
+		if err != nil {
+			if errors.Is(err, iot.ErrInvalidCursor) {
+				return webErrInvalidCursor
+			}
+			if errors.Is(err, iot.ErrFilterRequired) {
+				return webErrFilterRequired
+			}
+			log.Printf("[GetReadings] failed to get readings. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+
+		return h.respondReadings(w, r, input, withTotal, readings, nextCursor)
+	})
+}
+
+// GetDeviceReadings returns the sensor readings of the device in the path
+func (h Handler) GetDeviceReadings() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		deviceID := strings.TrimSpace(chi.URLParam(r, "id"))
+		if deviceID == "" {
+			return webErrInvalidDeviceID
+		}
+
+		input, webErr := parseGetReadingsInput(r.URL.Query())
+		if webErr != nil {
+			return webErr
+		}
+		withTotal, webErr := parseWithTotal(r.URL.Query())
+		if webErr != nil {
+			return webErr
+		}
+		input.DeviceID = deviceID
+
+		readings, nextCursor, err := h.iotCtrl.GetReadingsByDevice(r.Context(), deviceID, input)
+		if err != nil {
+			if errors.Is(err, iot.ErrInvalidCursor) {
+				return webErrInvalidCursor
+			}
+			log.Printf("[GetDeviceReadings] failed to get readings of device %s. Err: %+v\n", deviceID, err)
+			return webErrInternalServer
+		}
+
+		return h.respondReadings(w, r, input, withTotal, readings, nextCursor)
+	})
+}
+
+// GetLatestReadings returns the latest reading of every device
+func (h Handler) GetLatestReadings() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		readings, err := h.iotCtrl.GetLatestReadings(r.Context())
+		if err != nil {
+			log.Printf("[GetLatestReadings] failed to get latest readings. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+
+		total := int64(len(readings))
+		httpserver.RespondJSON(w, GetReadingsResponse{
+			Data: toReadingResponses(readings),
+			Pagination: model.Pagination{
+				TotalCount: &total,
+				Limit:      len(readings),
+			},
+		})
+
+		return nil
+	})
+}
+
+// respondReadings writes a page of readings, the readings matching the filters are only
+// counted with with_total=true as that reads all of them
+func (h Handler) respondReadings(w http.ResponseWriter, r *http.Request, input model.GetReadingsInput, withTotal bool, readings []model.SensorReading, nextCursor string) error {
+	pagination := model.Pagination{
+		Limit:      input.Limit,
+		NextCursor: nextCursor,
+	}
+	if withTotal {
+		total, err := h.iotCtrl.CountReadings(r.Context(), input)
+		if err != nil {
+			if errors.Is(err, iot.ErrFilterRequired) {
+				return webErrFilterRequired
+			}
+			log.Printf("[respondReadings] failed to count readings. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+		pagination.TotalCount = &total
+	}
+
+	httpserver.RespondJSON(w, GetReadingsResponse{
+		Data:       toReadingResponses(readings),
+		Pagination: pagination,
+	})
+
+	return nil
+}
+
+// parseWithTotal reads whether the total count of the readings is asked for
+func parseWithTotal(q url.Values) (bool, *httpserver.Error) {
+	v := q.Get("with_total")
+	if v == "" {
+		return false, nil
+	}
+	withTotal, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, webErrInvalidWithTotal
+	}
+	return withTotal, nil
+}
+
+// parseGetReadingsInput reads and validates the readings filters from the query params
+func parseGetReadingsInput(q url.Values) (model.GetReadingsInput, *httpserver.Error) {
+	input := model.GetReadingsInput{
+		DeviceID:   strings.TrimSpace(q.Get("device_id")),
+		DeviceType: strings.ToLower(strings.TrimSpace(q.Get("type"))),
+		Location:   strings.TrimSpace(q.Get("location")),
+		Limit:      model.PaginationDefaultLimit,
+		Cursor:     strings.TrimSpace(q.Get("cursor")),
+	}
+
+	if input.DeviceType != "" {
+		switch model.DeviceType(input.DeviceType) {
+		case model.DeviceTypeTemperature, model.DeviceTypeHumidity, model.DeviceTypeCO2, model.DeviceTypeMulti:
+		default:
+			return model.GetReadingsInput{}, webErrInvalidDeviceType
+		}
+	}
+
+	var err error
+	if v := q.Get("floor"); v != "" {
+		if input.Floor, err = strconv.Atoi(v); err != nil || input.Floor <= 0 {
+			return model.GetReadingsInput{}, webErrInvalidFloor
+		}
+	}
+	if v := q.Get("zone"); v != "" {
+		if input.Zone, err = strconv.Atoi(v); err != nil || input.Zone <= 0 {
+			return model.GetReadingsInput{}, webErrInvalidZone
+		}
+	}
+	if v := q.Get("start"); v != "" {
+		if input.StartTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return model.GetReadingsInput{}, webErrInvalidStartTime
+		}
+	}
+	if v := q.Get("end"); v != "" {
+		if input.EndTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return model.GetReadingsInput{}, webErrInvalidEndTime
+		}
+	}
+	if !input.StartTime.IsZero() && !input.EndTime.IsZero() && input.StartTime.After(input.EndTime) {
+		return model.GetReadingsInput{}, webErrInvalidTimeRange
+	}
+	if v := q.Get("limit"); v != "" {
+		if input.Limit, err = strconv.Atoi(v); err != nil || input.Limit <= 0 || input.Limit > model.PaginationMaxLimit {
+			return model.GetReadingsInput{}, webErrInvalidLimit
+		}
+	}
+
+	return input, nil
+}
+
+func toReadingResponses(readings []model.SensorReading) []ReadingResponse {
+	resp := make([]ReadingResponse, len(readings))
+	for i, r := range readings {
+		resp[i] = ReadingResponse{
+			ID:          r.ID,
+			DeviceID:    r.DeviceID,
+			DeviceName:  r.DeviceName,
+			DeviceType:  r.DeviceType,
+			Location:    r.Location,
+			Floor:       r.Floor,
+			Zone:        r.Zone,
+			Temperature: r.Temperature,
+			Humidity:    r.Humidity,
+			CO2:         r.CO2,
+			Timestamp:   r.Timestamp,
+			CreatedAt:   r.CreatedAt,
+		}
+	}
+	return resp
+}
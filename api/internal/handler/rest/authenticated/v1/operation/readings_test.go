@@ -0,0 +1,130 @@
+package operation
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGetReadingsInput(t *testing.T) {
+	tcs := map[string]struct {
+		givenQuery string
+		expInput   model.GetReadingsInput
+		expErr     *httpserver.Error
+	}{
+		"defaults": {
+			givenQuery: "",
+			expInput: model.GetReadingsInput{
+				Limit: model.PaginationDefaultLimit,
+			},
+		},
+		"all_fields": {
+			givenQuery: "device_id=TEMP_001&type=Temperature&location=Main+Building&floor=1&zone=2" +
+				"&start=2025-01-01T00:00:00Z&end=2025-01-02T00:00:00Z&limit=50&cursor=abc",
+			expInput: model.GetReadingsInput{
+				DeviceID:   "TEMP_001",
+				DeviceType: "temperature",
+				Location:   "Main Building",
+				Floor:      1,
+				Zone:       2,
+				StartTime:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndTime:    time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+				Limit:      50,
+				Cursor:     "abc",
+			},
+		},
+		"invalid_type": {
+			givenQuery: "type=pressure",
+			expErr:     webErrInvalidDeviceType,
+		},
+		"invalid_floor": {
+			givenQuery: "floor=-1",
+			expErr:     webErrInvalidFloor,
+		},
+		"invalid_zone": {
+			givenQuery: "zone=abc",
+			expErr:     webErrInvalidZone,
+		},
+		"invalid_start": {
+			givenQuery: "start=yesterday",
+			expErr:     webErrInvalidStartTime,
+		},
+		"invalid_end": {
+			givenQuery: "end=2025-01-02",
+			expErr:     webErrInvalidEndTime,
+		},
+		"start_after_end": {
+			givenQuery: "start=2025-01-02T00:00:00Z&end=2025-01-01T00:00:00Z",
+			expErr:     webErrInvalidTimeRange,
+		},
+		"limit_over_max": {
+			givenQuery: "limit=1001",
+			expErr:     webErrInvalidLimit,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			q, err := url.ParseQuery(tc.givenQuery)
+			require.NoError(t, err)
+
+			// When:
+			input, webErr := parseGetReadingsInput(q)
+
+			// Then:
+			if tc.expErr != nil {
+				require.Equal(t, tc.expErr, webErr)
+				return
+			}
+			require.Nil(t, webErr)
+			require.Equal(t, tc.expInput, input)
+		})
+	}
+}
+
+func TestParseWithTotal(t *testing.T) {
+	tcs := map[string]struct {
+		givenQuery string
+		expTotal   bool
+		expErr     *httpserver.Error
+	}{
+		"omitted": {
+			givenQuery: "limit=10",
+		},
+		"true": {
+			givenQuery: "with_total=true",
+			expTotal:   true,
+		},
+		"false": {
+			givenQuery: "with_total=false",
+		},
+		"invalid": {
+			givenQuery: "with_total=yes",
+			expErr:     webErrInvalidWithTotal,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			q, err := url.ParseQuery(tc.givenQuery)
+			require.NoError(t, err)
+
+			// When:
+			withTotal, webErr := parseWithTotal(q)
+
+			// Then:
+			if tc.expErr != nil {
+				require.Equal(t, tc.expErr, webErr)
+				return
+			}
+			require.Nil(t, webErr)
+			require.Equal(t, tc.expTotal, withTotal)
+		})
+	}
+}
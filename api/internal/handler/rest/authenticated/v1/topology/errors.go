@@ -0,0 +1,29 @@
+package topology
+
+import (
+	"net/http"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+)
+
+const (
+	// ErrCodeValidationFailed represents the error code for a failed validation
+	ErrCodeValidationFailed = "validation_failed"
+)
+
+// Web errors
+var (
+	webErrInternalServer = &httpserver.Error{Status: http.StatusInternalServerError, Code: "internal_error", Desc: "Something went wrong, please check again."}
+
+	webErrInvalidFloorID     = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid floor id"}
+	webErrInvalidFloorNumber = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid floor number"}
+	webErrInvalidZoneID      = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid zone id"}
+	webErrInvalidZoneName    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid zone name"}
+	webErrInvalidZoneType    = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid zone type"}
+	webErrInvalidArea        = &httpserver.Error{Status: http.StatusBadRequest, Code: ErrCodeValidationFailed, Desc: "invalid area"}
+
+	webErrFloorNotFound      = &httpserver.Error{Status: http.StatusNotFound, Code: "not_found", Desc: "floor not found"}
+	webErrZoneNotFound       = &httpserver.Error{Status: http.StatusNotFound, Code: "not_found", Desc: "zone not found on floor"}
+	webErrFloorAlreadyExists = &httpserver.Error{Status: http.StatusConflict, Code: "already_exists", Desc: "floor number already exists"}
+	webErrZoneAlreadyExists  = &httpserver.Error{Status: http.StatusConflict, Code: "already_exists", Desc: "zone name already exists on floor"}
+)
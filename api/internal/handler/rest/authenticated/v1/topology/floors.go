@@ -0,0 +1,197 @@
+package topology
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/controller/topology"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// FloorRequest holds the input payload to create or update a floor
+type FloorRequest struct {
+	FloorNumber int     `json:"floor_number"`
+	Description string  `json:"description"`
+	TotalArea   float64 `json:"total_area"`
+}
+
+// FloorResponse represents a single floor in the response
+type FloorResponse struct {
+	ID          int       `json:"id"`
+	FloorNumber int       `json:"floor_number"`
+	Description string    `json:"description"`
+	TotalArea   float64   `json:"total_area"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FloorTreeResponse represents a floor with its zones and their devices
+type FloorTreeResponse struct {
+	FloorResponse
+	Zones []ZoneTreeResponse `json:"zones"`
+}
+
+// GetFloorsResponse represents result of listing floors
+type GetFloorsResponse struct {
+	Data []FloorResponse `json:"data"`
+}
+
+// GetFloors returns all floors of the building
+func (h Handler) GetFloors() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floors, err := h.topologyCtrl.GetFloors(r.Context())
+		if err != nil {
+			log.Printf("[GetFloors] failed to get floors. Err: %+v\n", err)
+			return webErrInternalServer
+		}
+
+		resp := GetFloorsResponse{Data: make([]FloorResponse, len(floors))}
+		for i, f := range floors {
+			resp.Data[i] = toFloorResponse(f)
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// GetFloor returns the floor in the path with its zones and devices
+func (h Handler) GetFloor() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floorID, webErr := parsePathID(r, "floorID", webErrInvalidFloorID)
+		if webErr != nil {
+			return webErr
+		}
+
+		tree, err := h.topologyCtrl.GetFloorTree(r.Context(), floorID)
+		if err != nil {
+			return toTopologyWebErr("GetFloor", err)
+		}
+
+		resp := FloorTreeResponse{
+			FloorResponse: toFloorResponse(tree.Floor),
+			Zones:         make([]ZoneTreeResponse, len(tree.Zones)),
+		}
+		for i, z := range tree.Zones {
+			resp.Zones[i] = toZoneTreeResponse(z)
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// CreateFloor adds a floor to the building
+func (h Handler) CreateFloor() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		var req FloorRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[CreateFloor] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		floor, webErr := validateFloorRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+
+		created, err := h.topologyCtrl.CreateFloor(r.Context(), floor)
+		if err != nil {
+			return toTopologyWebErr("CreateFloor", err)
+		}
+
+		httpserver.RespondJSON(w, toFloorResponse(created))
+
+		return nil
+	})
+}
+
+// UpdateFloor overwrites the floor in the path
+func (h Handler) UpdateFloor() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floorID, webErr := parsePathID(r, "floorID", webErrInvalidFloorID)
+		if webErr != nil {
+			return webErr
+		}
+
+		var req FloorRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[UpdateFloor] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		floor, webErr := validateFloorRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+		floor.ID = floorID
+
+		updated, err := h.topologyCtrl.UpdateFloor(r.Context(), floor)
+		if err != nil {
+			return toTopologyWebErr("UpdateFloor", err)
+		}
+
+		httpserver.RespondJSON(w, toFloorResponse(updated))
+
+		return nil
+	})
+}
+
+// validateFloorRequest checks the payload and converts it to a floor
+func validateFloorRequest(req FloorRequest) (model.Floor, *httpserver.Error) {
+	floor := model.Floor{
+		FloorNumber: req.FloorNumber,
+		Description: strings.TrimSpace(req.Description),
+		TotalArea:   req.TotalArea,
+	}
+
+	// floor numbers may be negative for basements
+	if floor.TotalArea < 0 {
+		return model.Floor{}, webErrInvalidArea
+	}
+
+	return floor, nil
+}
+
+// parsePathID reads a positive integer id from the path
+func parsePathID(r *http.Request, key string, webErr *httpserver.Error) (int, *httpserver.Error) {
+	id, err := strconv.Atoi(chi.URLParam(r, key))
+	if err != nil || id <= 0 {
+		return 0, webErr
+	}
+	return id, nil
+}
+
+// toTopologyWebErr maps the controller errors to web errors
+func toTopologyWebErr(op string, err error) *httpserver.Error {
+	switch {
+	case errors.Is(err, topology.ErrFloorNotFound):
+		return webErrFloorNotFound
+	case errors.Is(err, topology.ErrZoneNotFound):
+		return webErrZoneNotFound
+	case errors.Is(err, topology.ErrFloorAlreadyExists):
+		return webErrFloorAlreadyExists
+	case errors.Is(err, topology.ErrZoneAlreadyExists):
+		return webErrZoneAlreadyExists
+	default:
+		log.Printf("[%s] failed. Err: %+v\n", op, err)
+		return webErrInternalServer
+	}
+}
+
+func toFloorResponse(f model.Floor) FloorResponse {
+	return FloorResponse{
+		ID:          f.ID,
+		FloorNumber: f.FloorNumber,
+		Description: f.Description,
+		TotalArea:   f.TotalArea,
+		CreatedAt:   f.CreatedAt,
+		UpdatedAt:   f.UpdatedAt,
+	}
+}
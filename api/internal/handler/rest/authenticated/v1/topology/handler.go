@@ -0,0 +1,15 @@
+package topology
+
+import (
+	"github.com/nhan1603/IoTsystem/api/internal/controller/topology"
+)
+
+// Handler is the web handler for this pkg
+type Handler struct {
+	topologyCtrl topology.Controller
+}
+
+// New instantiates a new Handler and returns it
+func New(topologyCtrl topology.Controller) Handler {
+	return Handler{topologyCtrl: topologyCtrl}
+}
@@ -0,0 +1,217 @@
+package topology
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// ZoneRequest holds the input payload to create or update a zone
+type ZoneRequest struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Area        float64 `json:"area"`
+}
+
+// ZoneResponse represents a single zone in the response
+type ZoneResponse struct {
+	ID          int       `json:"id"`
+	FloorID     int       `json:"floor_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Area        float64   `json:"area"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ZoneDeviceResponse represents a device placed in a zone
+type ZoneDeviceResponse struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Location string `json:"location"`
+	IsActive bool   `json:"is_active"`
+}
+
+// ZoneTreeResponse represents a zone with its devices
+type ZoneTreeResponse struct {
+	ZoneResponse
+	Devices []ZoneDeviceResponse `json:"devices"`
+}
+
+// GetZonesResponse represents result of listing the zones of a floor
+type GetZonesResponse struct {
+	Data []ZoneResponse `json:"data"`
+}
+
+// GetZones returns the zones of the floor in the path
+func (h Handler) GetZones() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floorID, webErr := parsePathID(r, "floorID", webErrInvalidFloorID)
+		if webErr != nil {
+			return webErr
+		}
+
+		zones, err := h.topologyCtrl.GetZones(r.Context(), floorID)
+		if err != nil {
+			return toTopologyWebErr("GetZones", err)
+		}
+
+		resp := GetZonesResponse{Data: make([]ZoneResponse, len(zones))}
+		for i, z := range zones {
+			resp.Data[i] = toZoneResponse(z)
+		}
+		httpserver.RespondJSON(w, resp)
+
+		return nil
+	})
+}
+
+// GetZone returns the zone in the path with its devices
+func (h Handler) GetZone() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floorID, webErr := parsePathID(r, "floorID", webErrInvalidFloorID)
+		if webErr != nil {
+			return webErr
+		}
+		zoneID, webErr := parsePathID(r, "zoneID", webErrInvalidZoneID)
+		if webErr != nil {
+			return webErr
+		}
+
+		tree, err := h.topologyCtrl.GetZoneTree(r.Context(), floorID, zoneID)
+		if err != nil {
+			return toTopologyWebErr("GetZone", err)
+		}
+
+		httpserver.RespondJSON(w, toZoneTreeResponse(tree))
+
+		return nil
+	})
+}
+
+// CreateZone adds a zone to the floor in the path
+func (h Handler) CreateZone() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floorID, webErr := parsePathID(r, "floorID", webErrInvalidFloorID)
+		if webErr != nil {
+			return webErr
+		}
+
+		var req ZoneRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[CreateZone] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		zone, webErr := validateZoneRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+		zone.FloorID = floorID
+
+		created, err := h.topologyCtrl.CreateZone(r.Context(), zone)
+		if err != nil {
+			return toTopologyWebErr("CreateZone", err)
+		}
+
+		httpserver.RespondJSON(w, toZoneResponse(created))
+
+		return nil
+	})
+}
+
+// UpdateZone overwrites the zone in the path
+func (h Handler) UpdateZone() http.HandlerFunc {
+	return httpserver.HandlerErr(func(w http.ResponseWriter, r *http.Request) error {
+		floorID, webErr := parsePathID(r, "floorID", webErrInvalidFloorID)
+		if webErr != nil {
+			return webErr
+		}
+		zoneID, webErr := parsePathID(r, "zoneID", webErrInvalidZoneID)
+		if webErr != nil {
+			return webErr
+		}
+
+		var req ZoneRequest
+		if err := httpserver.ParseJSON(r.Body, &req); err != nil {
+			log.Printf("[UpdateZone] failed to parse json. Err: %+v\n", err)
+			return err
+		}
+
+		zone, webErr := validateZoneRequest(req)
+		if webErr != nil {
+			return webErr
+		}
+		zone.ID = zoneID
+		zone.FloorID = floorID
+
+		updated, err := h.topologyCtrl.UpdateZone(r.Context(), zone)
+		if err != nil {
+			return toTopologyWebErr("UpdateZone", err)
+		}
+
+		httpserver.RespondJSON(w, toZoneResponse(updated))
+
+		return nil
+	})
+}
+
+// validateZoneRequest checks the payload and converts it to a zone
+func validateZoneRequest(req ZoneRequest) (model.Zone, *httpserver.Error) {
+	zone := model.Zone{
+		Name:        strings.TrimSpace(req.Name),
+		Type:        strings.ToLower(strings.TrimSpace(req.Type)),
+		Description: strings.TrimSpace(req.Description),
+		Area:        req.Area,
+	}
+
+	// lengths follow the zones table columns
+	if zone.Name == "" || len(zone.Name) > 100 {
+		return model.Zone{}, webErrInvalidZoneName
+	}
+	if zone.Type == "" || len(zone.Type) > 50 {
+		return model.Zone{}, webErrInvalidZoneType
+	}
+	if zone.Area < 0 {
+		return model.Zone{}, webErrInvalidArea
+	}
+
+	return zone, nil
+}
+
+func toZoneResponse(z model.Zone) ZoneResponse {
+	return ZoneResponse{
+		ID:          z.ID,
+		FloorID:     z.FloorID,
+		Name:        z.Name,
+		Type:        z.Type,
+		Description: z.Description,
+		Area:        z.Area,
+		CreatedAt:   z.CreatedAt,
+		UpdatedAt:   z.UpdatedAt,
+	}
+}
+
+func toZoneTreeResponse(t model.ZoneTree) ZoneTreeResponse {
+	resp := ZoneTreeResponse{
+		ZoneResponse: toZoneResponse(t.Zone),
+		Devices:      make([]ZoneDeviceResponse, len(t.Devices)),
+	}
+	for i, d := range t.Devices {
+		resp.Devices[i] = ZoneDeviceResponse{
+			DeviceID: d.DeviceID,
+			Name:     d.Name,
+			Type:     d.Type,
+			Location: d.Location,
+			IsActive: d.IsActive,
+		}
+	}
+	return resp
+}
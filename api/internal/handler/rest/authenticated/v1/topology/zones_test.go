@@ -0,0 +1,57 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhan1603/IoTsystem/api/internal/appconfig/httpserver"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateZoneRequest(t *testing.T) {
+	tcs := map[string]struct {
+		givenReq ZoneRequest
+		expZone  model.Zone
+		expErr   *httpserver.Error
+	}{
+		"valid": {
+			givenReq: ZoneRequest{Name: " Zone C ", Type: "Office", Description: "Open space", Area: 120.5},
+			expZone:  model.Zone{Name: "Zone C", Type: "office", Description: "Open space", Area: 120.5},
+		},
+		"empty_name": {
+			givenReq: ZoneRequest{Name: "  ", Type: "office"},
+			expErr:   webErrInvalidZoneName,
+		},
+		"name_too_long": {
+			givenReq: ZoneRequest{Name: strings.Repeat("a", 101), Type: "office"},
+			expErr:   webErrInvalidZoneName,
+		},
+		"empty_type": {
+			givenReq: ZoneRequest{Name: "Zone C"},
+			expErr:   webErrInvalidZoneType,
+		},
+		"negative_area": {
+			givenReq: ZoneRequest{Name: "Zone C", Type: "office", Area: -1},
+			expErr:   webErrInvalidArea,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			req := tc.givenReq
+
+			// When:
+			zone, webErr := validateZoneRequest(req)
+
+			// Then:
+			if tc.expErr != nil {
+				require.Equal(t, tc.expErr, webErr)
+				return
+			}
+			require.Nil(t, webErr)
+			require.Equal(t, tc.expZone, zone)
+		})
+	}
+}
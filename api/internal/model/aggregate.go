@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+)
+
+// AggregateInterval is enum for the width of the aggregation buckets
+type AggregateInterval string
+
+const (
+	// AggregateIntervalMinute buckets readings per minute
+	AggregateIntervalMinute AggregateInterval = "1m"
+	// AggregateIntervalFiveMinutes buckets readings per 5 minutes
+	AggregateIntervalFiveMinutes AggregateInterval = "5m"
+	// AggregateIntervalHour buckets readings per hour
+	AggregateIntervalHour AggregateInterval = "1h"
+	// AggregateIntervalDay buckets readings per day
+	AggregateIntervalDay AggregateInterval = "1d"
+)
+
+// Duration returns the width of the bucket, 0 if the interval is unknown
+func (ai AggregateInterval) Duration() time.Duration {
+	switch ai {
+	case AggregateIntervalMinute:
+		return time.Minute
+	case AggregateIntervalFiveMinutes:
+		return 5 * time.Minute
+	case AggregateIntervalHour:
+		return time.Hour
+	case AggregateIntervalDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// AggregateGroupBy is enum for how readings are grouped inside a bucket
+type AggregateGroupBy string
+
+const (
+	// AggregateGroupByDevice aggregates each device separately
+	AggregateGroupByDevice AggregateGroupBy = "device"
+	// AggregateGroupByZone aggregates all devices of a zone together
+	AggregateGroupByZone AggregateGroupBy = "zone"
+	// AggregateGroupByFloor aggregates all devices of a floor together
+	AggregateGroupByFloor AggregateGroupBy = "floor"
+)
+
+// AggregateMaxBuckets caps the number of buckets of one group a query may span
+const AggregateMaxBuckets = 1440
+
+// AggregateReadingsInput represents input for aggregating sensor readings.
+// Readings are taken from StartTime inclusive to EndTime exclusive.
+type AggregateReadingsInput struct {
+	Interval  AggregateInterval
+	GroupBy   AggregateGroupBy
+	DeviceID  string
+	Floor     int
+	Zone      int
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// MetricAggregate holds the statistics of one metric inside a bucket
+type MetricAggregate struct {
+	Avg float64
+	Min float64
+	Max float64
+}
+
+// ReadingAggregate represents the statistics of one group in one time bucket.
+// Only the fields of the group are set: DeviceID for device, Floor and Zone
+// for zone, Floor for floor.
+type ReadingAggregate struct {
+	Bucket      time.Time
+	DeviceID    string
+	Floor       int
+	Zone        int
+	Count       int64
+	Temperature MetricAggregate
+	Humidity    MetricAggregate
+	CO2         MetricAggregate
+}
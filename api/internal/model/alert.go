@@ -0,0 +1,148 @@
+package model
+
+import (
+	"time"
+)
+
+// AlertMetric is enum for the reading metric a rule watches
+type AlertMetric string
+
+const (
+	// AlertMetricTemperature watches the temperature of readings
+	AlertMetricTemperature AlertMetric = "temperature"
+	// AlertMetricHumidity watches the humidity of readings
+	AlertMetricHumidity AlertMetric = "humidity"
+	// AlertMetricCO2 watches the CO2 of readings
+	AlertMetricCO2 AlertMetric = "co2"
+)
+
+// IsValid checks whether the metric is known
+func (am AlertMetric) IsValid() bool {
+	switch am {
+	case AlertMetricTemperature, AlertMetricHumidity, AlertMetricCO2:
+		return true
+	}
+	return false
+}
+
+// Value returns the value of the metric in the reading
+func (am AlertMetric) Value(r SensorReading) float64 {
+	switch am {
+	case AlertMetricTemperature:
+		return r.Temperature
+	case AlertMetricHumidity:
+		return r.Humidity
+	default:
+		return r.CO2
+	}
+}
+
+// AlertOperator is enum for how a metric is compared to the threshold
+type AlertOperator string
+
+const (
+	// AlertOperatorGT breaches when the value is greater than the threshold
+	AlertOperatorGT AlertOperator = "gt"
+	// AlertOperatorGTE breaches when the value is greater than or equal to the threshold
+	AlertOperatorGTE AlertOperator = "gte"
+	// AlertOperatorLT breaches when the value is less than the threshold
+	AlertOperatorLT AlertOperator = "lt"
+	// AlertOperatorLTE breaches when the value is less than or equal to the threshold
+	AlertOperatorLTE AlertOperator = "lte"
+)
+
+// IsValid checks whether the operator is known
+func (ao AlertOperator) IsValid() bool {
+	switch ao {
+	case AlertOperatorGT, AlertOperatorGTE, AlertOperatorLT, AlertOperatorLTE:
+		return true
+	}
+	return false
+}
+
+// AlertStatus is enum for the lifecycle of an alert
+type AlertStatus string
+
+const (
+	// AlertStatusOpen is a newly raised alert
+	AlertStatusOpen AlertStatus = "open"
+	// AlertStatusAcknowledged is an alert someone is looking at
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+	// AlertStatusResolved is an alert whose metric went back to normal or was closed by hand
+	AlertStatusResolved AlertStatus = "resolved"
+)
+
+// ToString convert enum to string
+func (as AlertStatus) ToString() string {
+	return string(as)
+}
+
+// AlertRule represents a threshold rule, e.g. CO2 > 1000 ppm for 5 minutes.
+// Empty scope fields match any device, Zone is only meaningful with Floor.
+type AlertRule struct {
+	ID         int64
+	Name       string
+	DeviceID   string
+	DeviceType string
+	Floor      int
+	Zone       int
+	Metric     AlertMetric
+	Operator   AlertOperator
+	Threshold  float64
+	// Duration is how long the threshold must stay breached before an alert is raised
+	Duration  time.Duration
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Matches checks whether the reading is in the scope of the rule
+func (r AlertRule) Matches(reading SensorReading) bool {
+	return (r.DeviceID == "" || r.DeviceID == reading.DeviceID) &&
+		(r.DeviceType == "" || r.DeviceType == reading.DeviceType) &&
+		(r.Floor == 0 || r.Floor == reading.Floor) &&
+		(r.Zone == 0 || r.Zone == reading.Zone)
+}
+
+// Breached checks whether the value breaches the threshold of the rule
+func (r AlertRule) Breached(v float64) bool {
+	switch r.Operator {
+	case AlertOperatorGT:
+		return v > r.Threshold
+	case AlertOperatorGTE:
+		return v >= r.Threshold
+	case AlertOperatorLT:
+		return v < r.Threshold
+	case AlertOperatorLTE:
+		return v <= r.Threshold
+	default:
+		return false
+	}
+}
+
+// Alert represents a rule breached by a device. The timestamps of the states not
+// reached yet are zero.
+type Alert struct {
+	ID             int64
+	RuleID         int64
+	DeviceID       string
+	Metric         AlertMetric
+	Value          float64
+	Threshold      float64
+	Status         AlertStatus
+	TriggeredAt    time.Time
+	AcknowledgedAt time.Time
+	ResolvedAt     time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// GetAlertsInput represents input for querying alerts, newest first
+type GetAlertsInput struct {
+	Statuses []AlertStatus
+	RuleID   int64
+	DeviceID string
+	// DeviceIDs keeps the alerts of any of the devices when set
+	DeviceIDs []string
+	Limit     int
+}
@@ -32,6 +32,8 @@ type SensorReading struct {
 	CO2         float64
 	Timestamp   time.Time
 	CreatedAt   time.Time
+	// MessageID identifies the message the reading came from, storing it again is a no-op
+	MessageID string
 }
 
 // IoTDataMessage represents the Kafka message format for IoT data
@@ -46,6 +48,8 @@ type IoTDataMessage struct {
 	Humidity    float64   `json:"humidity"`
 	CO2         float64   `json:"co2"`
 	Timestamp   time.Time `json:"timestamp"`
+	// MessageID is optionally assigned by the producer, it is derived from the Kafka position of its first copy otherwise
+	MessageID string `json:"message_id,omitempty"`
 }
 
 // GetReadingsInput represents input for querying sensor readings
@@ -58,7 +62,8 @@ type GetReadingsInput struct {
 	StartTime  time.Time
 	EndTime    time.Time
 	Limit      int
-	Page       int
+	// Cursor is the opaque keyset cursor returned as the next cursor of the previous page
+	Cursor string
 }
 
 // DeviceType is enum for device types
@@ -92,4 +97,5 @@ type BenchmarkMetrics struct {
 	Throughput       float64 // records per second
 	BatchSize        int
 	DatabaseType     string
+	CreatedAt        time.Time // the time of saving when left zero
 }
@@ -0,0 +1,9 @@
+package model
+
+// ConsumerOffset is the offset of the next message a consumer group reads from a topic partition
+type ConsumerOffset struct {
+	GroupID   string
+	Topic     string
+	Partition int32
+	Offset    int64
+}
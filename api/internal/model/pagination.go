@@ -8,7 +8,10 @@ const (
 
 // Pagination represents for a response struct
 type Pagination struct {
-	TotalCount  int64 `json:"totalCount"`
-	CurrentPage int   `json:"currentPage"`
-	Limit       int   `json:"limit"`
+	// TotalCount is left out unless it was asked for, counting may read every matching row
+	TotalCount  *int64 `json:"totalCount,omitempty"`
+	CurrentPage int    `json:"currentPage,omitempty"`
+	Limit       int    `json:"limit"`
+	// NextCursor is empty when there is no further page
+	NextCursor string `json:"nextCursor,omitempty"`
 }
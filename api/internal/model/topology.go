@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+)
+
+// Floor represents a floor of the building
+type Floor struct {
+	ID          int
+	FloorNumber int
+	Description string
+	TotalArea   float64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Zone represents an area of a floor, e.g. an office or a laboratory
+type Zone struct {
+	ID          int
+	FloorID     int
+	Name        string
+	Type        string
+	Description string
+	Area        float64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// FloorTree represents a floor with its zones and their devices
+type FloorTree struct {
+	Floor
+	Zones []ZoneTree
+}
+
+// ZoneTree represents a zone with its devices
+type ZoneTree struct {
+	Zone
+	Devices []IoTDevice
+}
@@ -0,0 +1,252 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/scylladb/gocqlx/v2"
+)
+
+const (
+	// MaxBatchBytes is the default size of the values of a batch, the default
+	// batch_size_warn_threshold_in_kb of Cassandra. A larger partition group is split.
+	MaxBatchBytes = 5 * 1024
+	// maxParallelBatches bounds the batches of different partitions executed at once
+	maxParallelBatches = 8
+)
+
+// Batch collects writes grouped by partition key and executes them as one batch per
+// partition, so no unlogged batch spans several partitions. The statements of a partition
+// are split into batches of at most maxBytes of values, except for the conditional ones.
+//
+// A partition group is applied atomically and in isolation, different partitions are not:
+// when the execution of a group fails, the groups already executed stay applied. Writes are
+// upserts so the whole batch can be retried.
+type Batch struct {
+	mu          sync.Mutex
+	consistency *gocql.Consistency
+	maxBytes    int
+	groups      []*group
+	byKey       map[string]*group
+}
+
+// group is the statements of a partition
+type group struct {
+	key        string
+	statements []statement
+	// notApplied is returned when the conditions of the group are not met,
+	// nil when the group has no conditional statement
+	notApplied error
+}
+
+type statement struct {
+	stmt string
+	args []interface{}
+	size int
+}
+
+// NewBatch returns an empty batch using the consistency of the session
+func NewBatch() *Batch {
+	return &Batch{
+		maxBytes: MaxBatchBytes,
+		byKey:    map[string]*group{},
+	}
+}
+
+// SetConsistency sets the consistency of the writes of the batch
+func (b *Batch) SetConsistency(c gocql.Consistency) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consistency = &c
+}
+
+// SetMaxBytes sets the size of the values above which the statements of a partition are split
+func (b *Batch) SetMaxBytes(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.maxBytes = n
+}
+
+// Query adds a statement writing to the partition identified by partition, any string
+// naming the table and the values of its partition key
+func (b *Batch) Query(partition, stmt string, args ...interface{}) {
+	b.add(partition, nil, stmt, args)
+}
+
+// Conditional adds a lightweight transaction writing to the partition, the statements of
+// the partition are then applied only when every condition is met and notApplied is
+// returned otherwise. Conditional partitions are executed first, so the others are
+// skipped when one is not applied.
+func (b *Batch) Conditional(partition string, notApplied error, stmt string, args ...interface{}) {
+	b.add(partition, notApplied, stmt, args)
+}
+
+func (b *Batch) add(partition string, notApplied error, stmt string, args []interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.byKey[partition]
+	if !ok {
+		g = &group{key: partition}
+		b.byKey[partition] = g
+		b.groups = append(b.groups, g)
+	}
+	if g.notApplied == nil {
+		g.notApplied = notApplied
+	}
+	g.statements = append(g.statements, statement{stmt: stmt, args: args, size: valuesSize(args)})
+}
+
+// Len returns the number of statements of the batch
+func (b *Batch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := 0
+	for _, g := range b.groups {
+		n += len(g.statements)
+	}
+	return n
+}
+
+// Exec executes the statements, the conditional partitions first and one after the other,
+// then the others in parallel
+func (b *Batch) Exec(ctx context.Context, session *gocql.Session) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var conditional, unconditional []*group
+	for _, g := range b.groups {
+		if g.notApplied != nil {
+			conditional = append(conditional, g)
+		} else {
+			unconditional = append(unconditional, g)
+		}
+	}
+
+	for _, g := range conditional {
+		if err := b.execConditional(ctx, session, g); err != nil {
+			return err
+		}
+	}
+
+	var chunks [][]statement
+	for _, g := range unconditional {
+		chunks = append(chunks, b.split(g.statements)...)
+	}
+
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, maxParallelBatches)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = session.ExecuteBatch(b.newBatch(ctx, session, gocql.UnloggedBatch, chunk))
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("execute cassandra batch: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *Batch) execConditional(ctx context.Context, session *gocql.Session, g *group) error {
+	// Conditions of several statements are checked together, the group can't be split
+	applied, iter, err := session.MapExecuteBatchCAS(b.newBatch(ctx, session, gocql.LoggedBatch, g.statements), map[string]interface{}{})
+	if iter != nil {
+		// A not applied lightweight transaction returns the current row, it isn't needed
+		_ = iter.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("execute cassandra batch of %s: %w", g.key, err)
+	}
+	if !applied {
+		return g.notApplied
+	}
+	return nil
+}
+
+func (b *Batch) newBatch(ctx context.Context, session *gocql.Session, typ gocql.BatchType, statements []statement) *gocql.Batch {
+	batch := session.NewBatch(typ).WithContext(ctx)
+	if b.consistency != nil {
+		batch.SetConsistency(*b.consistency)
+	}
+	for _, s := range statements {
+		batch.Query(s.stmt, s.args...)
+	}
+	return batch
+}
+
+// split cuts the statements of a partition into chunks of at most maxBytes of values,
+// a statement larger than maxBytes gets a chunk of its own
+func (b *Batch) split(statements []statement) [][]statement {
+	var chunks [][]statement
+	start, size := 0, 0
+	for i, s := range statements {
+		if i > start && size+s.size > b.maxBytes {
+			chunks = append(chunks, statements[start:i])
+			start, size = i, 0
+		}
+		size += s.size
+	}
+	if start < len(statements) {
+		chunks = append(chunks, statements[start:])
+	}
+	return chunks
+}
+
+// valuesSize estimates the size of the values of a statement, as counted against the
+// batch size thresholds of Cassandra
+func valuesSize(args []interface{}) int {
+	size := 0
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case *time.Time:
+			if v != nil {
+				size += 8
+			}
+		case gocql.UUID:
+			size += 16
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// BindMap returns the values of the named columns of the map, a batch only binds positional values
+func BindMap(names []string, m map[string]interface{}) []interface{} {
+	values := make([]interface{}, len(names))
+	for i, name := range names {
+		values[i] = m[name]
+	}
+	return values
+}
+
+// BindStruct returns the values of the named columns of the struct, mapped by their db tags
+func BindStruct(names []string, arg interface{}) []interface{} {
+	fields := gocqlx.DefaultMapper.FieldsByName(reflect.ValueOf(arg), names)
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i] = f.Interface()
+	}
+	return values
+}
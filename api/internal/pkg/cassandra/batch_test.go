@@ -0,0 +1,95 @@
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch_Split(t *testing.T) {
+	tcs := map[string]struct {
+		givenMaxBytes int
+		givenValues   []string
+		expChunks     []int
+	}{
+		"under_the_limit": {
+			givenMaxBytes: 10,
+			givenValues:   []string{"aaa", "bbb", "ccc"},
+			expChunks:     []int{3},
+		},
+		"split_at_the_limit": {
+			givenMaxBytes: 10,
+			givenValues:   []string{"aaaaa", "bbbbb", "ccccc"},
+			expChunks:     []int{2, 1},
+		},
+		"statement_larger_than_the_limit": {
+			givenMaxBytes: 10,
+			givenValues:   []string{"aaa", strings.Repeat("b", 20), "ccc"},
+			expChunks:     []int{1, 1, 1},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			b := NewBatch()
+			b.SetMaxBytes(tc.givenMaxBytes)
+			for _, v := range tc.givenValues {
+				b.Query("readings:A", "INSERT", v)
+			}
+
+			// When:
+			chunks := b.split(b.groups[0].statements)
+
+			// Then:
+			var sizes []int
+			for _, chunk := range chunks {
+				sizes = append(sizes, len(chunk))
+			}
+			require.Equal(t, tc.expChunks, sizes)
+		})
+	}
+}
+
+func TestBatch_Groups(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errTaken := errors.New("taken")
+
+	// Given:
+	b := NewBatch()
+
+	// When:
+	b.Query("readings:A", "INSERT A1", "A1")
+	b.Conditional("devices:A", errNotFound, "UPDATE A IF EXISTS", "A")
+	b.Query("readings:B", "INSERT B1", "B1")
+	b.Query("readings:A", "INSERT A2", "A2")
+	b.Conditional("devices:A", errTaken, "INSERT A IF NOT EXISTS", "A")
+
+	// Then: statements are grouped by partition in order, the first condition error is kept
+	require.Equal(t, 5, b.Len())
+	require.Len(t, b.groups, 3)
+	require.Equal(t, "readings:A", b.groups[0].key)
+	require.Len(t, b.groups[0].statements, 2)
+	require.NoError(t, b.groups[0].notApplied)
+	require.Equal(t, "devices:A", b.groups[1].key)
+	require.Len(t, b.groups[1].statements, 2)
+	require.Equal(t, errNotFound, b.groups[1].notApplied)
+	require.Equal(t, "readings:B", b.groups[2].key)
+}
+
+func TestWrite_InScope(t *testing.T) {
+	// Given:
+	b := NewBatch()
+	ctx := WithBatch(context.Background(), b)
+
+	// When: the session isn't used, the writes join the batch of the scope
+	err := Write(ctx, nil, func(b *Batch) {
+		b.Query("readings:A", "INSERT", "A")
+	})
+
+	// Then:
+	require.NoError(t, err)
+	require.Equal(t, 1, b.Len())
+}
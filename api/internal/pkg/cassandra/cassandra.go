@@ -6,23 +6,48 @@ import (
 	"github.com/gocql/gocql"
 )
 
-type ctxKey struct{}
+type ctxKey int
 
-var batchKey ctxKey
+const (
+	batchKey ctxKey = iota
+	consistencyKey
+)
 
-// WithBatch puts *gocql.Batch into context so repos can append queries.
-func WithBatch(ctx context.Context, b *gocql.Batch) context.Context {
+// WithBatch puts the batch of a DoInTx scope into context so repos add their writes to it.
+func WithBatch(ctx context.Context, b *Batch) context.Context {
 	return context.WithValue(ctx, batchKey, b)
 }
 
-// BatchFrom extracts *gocql.Batch from context.
-func BatchFrom(ctx context.Context) (*gocql.Batch, bool) {
-	b, ok := ctx.Value(batchKey).(*gocql.Batch)
+// BatchFrom extracts the batch of the DoInTx scope from context.
+func BatchFrom(ctx context.Context) (*Batch, bool) {
+	b, ok := ctx.Value(batchKey).(*Batch)
 	return b, ok && b != nil
 }
 
-// BatchLike is the minimal surface we need from *gocql.Batch`.
-type BatchLike interface {
-	Query(stmt string, names []string) *gocql.Batch
-	WithContext(ctx interface{}) *gocql.Batch // we won’t use this from repos
+// WithConsistency sets the consistency of the writes of the DoInTx scopes started with ctx,
+// they use the consistency of the session otherwise.
+func WithConsistency(ctx context.Context, c gocql.Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey, c)
+}
+
+// ConsistencyFrom extracts the consistency set by WithConsistency from context.
+func ConsistencyFrom(ctx context.Context) (gocql.Consistency, bool) {
+	c, ok := ctx.Value(consistencyKey).(gocql.Consistency)
+	return c, ok
+}
+
+// Write adds the statements of fn to the batch of the DoInTx scope of ctx, they are executed
+// when the scope ends. Outside of a scope they are executed right away.
+func Write(ctx context.Context, session *gocql.Session, fn func(b *Batch)) error {
+	if b, ok := BatchFrom(ctx); ok {
+		fn(b)
+		return nil
+	}
+
+	b := NewBatch()
+	if c, ok := ConsistencyFrom(ctx); ok {
+		b.SetConsistency(c)
+	}
+	fn(b)
+	return b.Exec(ctx, session)
 }
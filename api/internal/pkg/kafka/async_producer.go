@@ -0,0 +1,163 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// AsyncProducerConfig tunes how an AsyncProducer batches its messages
+type AsyncProducerConfig struct {
+	Linger      time.Duration // how long to wait for more messages before sending a batch
+	BatchBytes  int           // batch size in bytes which triggers a send
+	Compression string        // none, gzip, snappy, lz4 or zstd
+	Idempotent  bool          // retries don't duplicate messages, acks default to all and can't be lowered
+	Acks        string        // none, leader or all
+}
+
+// DeliveryReport is the outcome of an asynchronously sent message
+type DeliveryReport struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Err       error
+}
+
+// AsyncProducer publishes Kafka messages in the background, their outcome is only known through delivery reports.
+type AsyncProducer struct {
+	client     sarama.Client
+	producer   sarama.AsyncProducer
+	onDelivery func(DeliveryReport)
+	wg         sync.WaitGroup
+}
+
+// NewAsyncProducer creates a new async producer, onDelivery is called with the report of every message and can be nil
+func NewAsyncProducer(ctx context.Context, clientCfg ClientConfig, asyncCfg AsyncProducerConfig, onDelivery func(DeliveryReport), opts ...ProducerOption) (*AsyncProducer, error) {
+	log.Println("Initializing Kafka AsyncProducer")
+
+	baseCfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &producerConfig{baseCfg}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := asyncCfg.apply(cfg); err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(clientCfg.Brokers, cfg.Config)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "client init failed")
+	}
+
+	p, err := sarama.NewAsyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, pkgerrors.Wrap(err, "starting async producer")
+	}
+
+	return newAsyncProducer(client, p, onDelivery), nil
+}
+
+// newAsyncProducer wraps the sarama producer and starts reporting its deliveries
+func newAsyncProducer(client sarama.Client, p sarama.AsyncProducer, onDelivery func(DeliveryReport)) *AsyncProducer {
+	ap := &AsyncProducer{
+		client:     client,
+		producer:   p,
+		onDelivery: onDelivery,
+	}
+
+	ap.wg.Add(2)
+	go func() {
+		defer ap.wg.Done()
+		for pm := range p.Successes() {
+			ap.report(DeliveryReport{Topic: pm.Topic, Partition: pm.Partition, Offset: pm.Offset})
+		}
+	}()
+	go func() {
+		defer ap.wg.Done()
+		for pe := range p.Errors() {
+			ap.report(DeliveryReport{Topic: pe.Msg.Topic, Partition: pe.Msg.Partition, Offset: pe.Msg.Offset, Err: pe.Err})
+		}
+	}()
+
+	return ap
+}
+
+// SendMessage queues the message, it only blocks while the producer's input is full
+func (p *AsyncProducer) SendMessage(ctx context.Context, topic string, payload []byte, opt ProducerMessageOption) error {
+	pm, err := prepareProducerMessage(topic, payload, opt)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case p.producer.Input() <- pm:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the queued messages, waits for their reports and shuts down the producer.
+// The failed deliveries are only reported to onDelivery, Close doesn't drain them.
+func (p *AsyncProducer) Close() error {
+	p.producer.AsyncClose()
+	p.wg.Wait()
+	if !p.client.Closed() { // Just in case
+		if err := p.client.Close(); err != nil {
+			return pkgerrors.Wrap(err, "could not stop producer client")
+		}
+	}
+	return nil
+}
+
+func (p *AsyncProducer) report(r DeliveryReport) {
+	if p.onDelivery != nil {
+		p.onDelivery(r)
+	}
+}
+
+// apply sets the async config on the sarama config, zero values keep sarama's defaults
+func (c AsyncProducerConfig) apply(cfg *producerConfig) error {
+	cfg.Producer.Return.Successes = true // Mandatory for delivery reports
+	cfg.Producer.Return.Errors = true
+	cfg.Producer.Flush.Frequency = c.Linger
+	cfg.Producer.Flush.Bytes = c.BatchBytes
+
+	if c.Compression != "" {
+		if err := cfg.Producer.Compression.UnmarshalText([]byte(strings.ToLower(c.Compression))); err != nil {
+			return pkgerrors.Wrap(err, "invalid compression")
+		}
+	}
+
+	switch strings.ToLower(c.Acks) {
+	case "":
+	case "none", "0":
+		cfg.Producer.RequiredAcks = sarama.NoResponse
+	case "leader", "1":
+		cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	case "all", "-1":
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+	default:
+		return fmt.Errorf("invalid acks %q, expected one of none, leader, all", c.Acks)
+	}
+
+	if c.Idempotent {
+		cfg.Producer.Idempotent = true
+		cfg.Net.MaxOpenRequests = 1
+		if c.Acks == "" {
+			cfg.Producer.RequiredAcks = sarama.WaitForAll
+		}
+	}
+
+	return pkgerrors.Wrap(cfg.Validate(), "invalid async producer config")
+}
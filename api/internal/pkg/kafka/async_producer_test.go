@@ -0,0 +1,133 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncProducerConfig_Apply(t *testing.T) {
+	tcs := map[string]struct {
+		given          AsyncProducerConfig
+		expCompression sarama.CompressionCodec
+		expAcks        sarama.RequiredAcks
+		expErr         bool
+	}{
+		"defaults": {
+			expCompression: sarama.CompressionNone,
+			expAcks:        sarama.WaitForLocal,
+		},
+		"lz4_all_acks": {
+			given:          AsyncProducerConfig{Linger: 10 * time.Millisecond, BatchBytes: 1 << 16, Compression: "LZ4", Acks: "all"},
+			expCompression: sarama.CompressionLZ4,
+			expAcks:        sarama.WaitForAll,
+		},
+		"idempotent_defaults_to_all_acks": {
+			given:          AsyncProducerConfig{Compression: "zstd", Idempotent: true},
+			expCompression: sarama.CompressionZSTD,
+			expAcks:        sarama.WaitForAll,
+		},
+		"idempotent_with_leader_acks": {
+			given:  AsyncProducerConfig{Idempotent: true, Acks: "leader"},
+			expErr: true,
+		},
+		"invalid_compression": {
+			given:  AsyncProducerConfig{Compression: "brotli"},
+			expErr: true,
+		},
+		"invalid_acks": {
+			given:  AsyncProducerConfig{Acks: "some"},
+			expErr: true,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			cfg := &producerConfig{sarama.NewConfig()}
+
+			// When:
+			err := tc.given.apply(cfg)
+
+			// Then:
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expCompression, cfg.Producer.Compression)
+			require.Equal(t, tc.expAcks, cfg.Producer.RequiredAcks)
+			require.Equal(t, tc.given.Linger, cfg.Producer.Flush.Frequency)
+			require.Equal(t, tc.given.BatchBytes, cfg.Producer.Flush.Bytes)
+			require.True(t, cfg.Producer.Return.Successes)
+		})
+	}
+}
+
+func TestAsyncProducer_Close(t *testing.T) {
+	errBroker := errors.New("broker down")
+
+	tcs := map[string]struct {
+		givenFail []bool
+		expErrs   []error
+	}{
+		"delivered": {
+			givenFail: []bool{false, false},
+			expErrs:   []error{nil, nil},
+		},
+		"failed_deliveries_are_reported": {
+			givenFail: []bool{false, true, true},
+			expErrs:   []error{nil, errBroker, errBroker},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			cfg := &producerConfig{sarama.NewConfig()}
+			require.NoError(t, AsyncProducerConfig{}.apply(cfg))
+			mp := mocks.NewAsyncProducer(t, cfg.Config)
+			for _, fail := range tc.givenFail {
+				if fail {
+					mp.ExpectInputAndFail(errBroker)
+				} else {
+					mp.ExpectInputAndSucceed()
+				}
+			}
+			client := &fakeClient{}
+			var mu sync.Mutex
+			var errs []error
+			p := newAsyncProducer(client, mp, func(r DeliveryReport) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, r.Err)
+			})
+			for range tc.givenFail {
+				require.NoError(t, p.SendMessage(context.Background(), "iot.sensor", []byte("{}"), ProducerMessageOption{}))
+			}
+
+			// When:
+			err := p.Close()
+
+			// Then: every delivery was reported before Close returned
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.expErrs, errs)
+			require.True(t, client.closed)
+		})
+	}
+}
+
+type fakeClient struct {
+	sarama.Client
+	closed bool
+}
+
+func (c *fakeClient) Closed() bool { return c.closed }
+func (c *fakeClient) Close() error {
+	c.closed = true
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -14,36 +16,72 @@ import (
 
 // BatchConsumer wraps a Kafka consumer with batch processing capabilities.
 type BatchConsumer struct {
-	client   sarama.Client
-	consumer sarama.ConsumerGroup
-	topic    string
-	handler  batchMessageHandler
+	client     sarama.Client
+	consumer   sarama.ConsumerGroup
+	topic      string
+	groupID    string
+	handler    batchMessageHandler
+	lagTracker *LagTracker
+	lifecycle  *consumerLifecycle
 }
 
 // BatchConsumeHandler is called with a slice of messages to process as a batch.
 type BatchConsumeHandler func(ctx context.Context, msgs []ConsumerMessage) error
 
+// ConsumerOption overrides the properties of a consumer
+type ConsumerOption func(*consumerConfig)
+
+// WithDeadLetter publishes the messages that can't be processed even alone instead of dropping them
+func WithDeadLetter(publisher DeadLetterPublisher) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.deadLetter = publisher
+	}
+}
+
+// WithClaimWorkers processes each claimed partition with n workers, messages being sharded by key
+func WithClaimWorkers(n int) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.workers = n
+	}
+}
+
 // NewBatchConsumer creates a new consumer supporting batch processing.
 func NewBatchConsumer(
 	ctx context.Context,
 	topic string,
-	broker string,
+	clientCfg ClientConfig,
 	handler BatchConsumeHandler,
 	groupID string,
 	batchSize int,
 	batchTimeout time.Duration,
+	opts ...ConsumerOption,
 ) (*BatchConsumer, error) {
 	if topic == "" {
 		return nil, errors.New("topic is empty")
 	}
 
-	baseCfg := sarama.NewConfig()
+	baseCfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
 	cfg := &consumerConfig{Config: baseCfg, groupID: groupID}
-	cfg.maxRetriesPerMsg = 35
+	cfg.retryPolicy = DefaultRetryPolicy()
 	cfg.batchSize = batchSize
 	cfg.batchTimeout = batchTimeout
+	cfg.workers = 1
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.workers > 1 && cfg.offsetStore != nil {
+		// a worker's batch can hold offsets above ones still in progress in another worker,
+		// storing it would skip those on restore
+		return nil, errors.New("claim workers can't be combined with an offset store")
+	}
+	if len(cfg.retryPolicy.Tiers) > 0 && cfg.retryPublisher == nil {
+		return nil, errors.New("retry tiers require a retry publisher")
+	}
 
-	client, err := sarama.NewClient([]string{broker}, cfg.Config)
+	client, err := sarama.NewClient(clientCfg.Brokers, cfg.Config)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "client init failed")
 	}
@@ -53,24 +91,39 @@ func NewBatchConsumer(
 		return nil, pkgerrors.Wrap(err, "creating new consumer group")
 	}
 
+	lifecycle := newConsumerLifecycle(cfg)
 	h := batchMessageHandler{
 		handler:               handler,
-		maxRetriesPerMsg:      cfg.maxRetriesPerMsg,
+		retryPolicy:           cfg.retryPolicy,
+		retryPublisher:        cfg.retryPublisher,
 		disablePayloadLogging: cfg.disablePayloadLogging,
 		batchSize:             cfg.batchSize,
 		batchTimeout:          cfg.batchTimeout,
+		deadLetter:            cfg.deadLetter,
+		groupID:               cfg.groupID,
+		offsetStore:           cfg.offsetStore,
+		workers:               cfg.workers,
+		batchSizer:            cfg.batchSizer,
+		lifecycle:             lifecycle,
 	}
 
 	return &BatchConsumer{
-		client:   client,
-		consumer: cg,
-		topic:    topic,
-		handler:  h,
+		client:     client,
+		consumer:   cg,
+		topic:      topic,
+		groupID:    cfg.groupID,
+		handler:    h,
+		lagTracker: cfg.lagTracker,
+		lifecycle:  lifecycle,
 	}, nil
 }
 
 // Consume starts consuming messages in batches.
 func (c *BatchConsumer) Consume(ctx context.Context) error {
+	if c.lagTracker != nil {
+		go c.trackLag(ctx)
+	}
+
 	errCh := make(chan error, 1)
 	go func() {
 		for {
@@ -90,7 +143,12 @@ func (c *BatchConsumer) Consume(ctx context.Context) error {
 		return err
 	case <-ctx.Done():
 		log.Println("[Kafka BatchConsumer] shutting down")
-		c.consumer.Close()
+		// waits for the session's cleanup, which flushes the in-flight batches
+		err := c.consumer.Close()
+		if err != nil {
+			log.Printf("[Kafka BatchConsumer] Leaving the group failed: %v", err)
+		}
+		c.lifecycle.shutdown(err == nil)
 		c.client.Close()
 		return nil
 	}
@@ -100,140 +158,407 @@ func (c *BatchConsumer) Consume(ctx context.Context) error {
 type batchMessageHandler struct {
 	handler               BatchConsumeHandler
 	disablePayloadLogging bool
-	maxRetriesPerMsg      int
+	retryPolicy           RetryPolicy
+	retryPublisher        RetryPublisher
 	batchSize             int
 	batchTimeout          time.Duration
+	deadLetter            DeadLetterPublisher
+	groupID               string
+	offsetStore           OffsetStore
+	workers               int
+	batchSizer            *AdaptiveBatchSizer
+	lifecycle             *consumerLifecycle
+}
+
+// droppedMessage is a message that failed even when processed alone
+type droppedMessage struct {
+	msg      ConsumerMessage
+	err      error
+	attempts int
+}
+
+func (h batchMessageHandler) Setup(sess sarama.ConsumerGroupSession) error {
+	if err := h.restoreOffsets(sess); err != nil {
+		return err
+	}
+	h.lifecycle.rebalanced(sess)
+	return nil
 }
 
-func (h batchMessageHandler) Setup(_ sarama.ConsumerGroupSession) error { return nil }
-func (h batchMessageHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
+// Cleanup runs once the claims of the session ended, before the partitions are released
+func (h batchMessageHandler) Cleanup(sess sarama.ConsumerGroupSession) error {
 	log.Println("[Kafka Consumer] Cleaning up...")
+	if err := h.flushPending(sess); err != nil {
+		log.Printf("[Kafka Consumer] Flushing in-flight batches failed: %v", err)
+		return err
+	}
 	return nil
 }
 
+// ConsumeClaim hands the messages to the claim workers, sharded by key so the messages of a device
+// are processed in order. The partition offset only moves past a message once all lower ones are done.
 func (h batchMessageHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	batch := make([]ConsumerMessage, 0, h.batchSize)
 	log.Println("[Kafka Consumer] Starting to consume messages...")
-	timer := time.NewTimer(h.batchTimeout)
-	defer timer.Stop()
 
-	resetTimer := func() {
-		if !timer.Stop() {
-			select {
-			case <-timer.C:
-			default:
-			}
-		}
-		timer.Reset(h.batchTimeout)
+	workers := h.workers
+	if workers < 1 {
+		workers = 1
 	}
 
-	commitSuccesses := func(succeeded []ConsumerMessage) {
-		for _, m := range succeeded {
-			// Mark only the offsets that truly succeeded
-			sess.MarkOffset(m.ID.Topic, m.ID.Partition, m.ID.Offset+1, "")
-		}
+	// stops the dispatch and the other workers as soon as one of them fails
+	ctx, cancel := context.WithCancel(sess.Context())
+	defer cancel()
+
+	tracker := newCommitTracker()
+	inputs := make([]chan ConsumerMessage, workers)
+	errCh := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := range inputs {
+		inputs[i] = make(chan ConsumerMessage, h.maxBatchSize())
+		wg.Add(1)
+		go func(in <-chan ConsumerMessage) {
+			defer wg.Done()
+			if err := h.runWorker(ctx, sess, claim, tracker, in); err != nil {
+				errCh <- err
+				cancel()
+			}
+		}(inputs[i])
 	}
 
-	processAndCommit := func(b []ConsumerMessage) {
-		if len(b) == 0 {
-			return
-		}
-
-		// respect rebalance/cancel
-		ctx, cancel := context.WithTimeout(sess.Context(), h.batchTimeout) // e.g., 5–10s
-		defer cancel()
+	h.dispatch(ctx, claim, tracker, inputs)
+	for _, in := range inputs {
+		close(in)
+	}
+	wg.Wait()
 
-		log.Printf("[Kafka Consumer] Processing batch of %d messages...\n", len(b))
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return nil
+	}
+}
 
-		// Try whole batch with retry
-		if err := h.processBatch(ctx, b); err == nil {
-			commitSuccesses(b)
-			sess.Commit()
+// dispatch routes the claimed messages to the workers until the claim or ctx ends
+func (h batchMessageHandler) dispatch(ctx context.Context, claim sarama.ConsumerGroupClaim, tracker *commitTracker, inputs []chan ConsumerMessage) {
+	for {
+		select {
+		case cm, ok := <-claim.Messages():
+			if !ok {
+				return
+			}
+			m := toConsumerMessage(cm)
+			tracker.add(m.ID.Offset)
+			select {
+			case inputs[shardOf(m, len(inputs))] <- m:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
 			return
 		}
+	}
+}
 
-		// Fallback: bisect to isolate bad records
-		succeeded, dropped := h.processWithBisection(sess.Context(), b)
-
-		// Commit successful records to make progress
-		commitSuccesses(succeeded)
+// runWorker processes the messages of its shard in batches until its input is closed.
+// It stops right away when ctx is done. When the session ended its batch and buffered messages are parked for
+// the cleanup to flush, otherwise, i.e. another worker failed, they are consumed again by the next claim.
+func (h batchMessageHandler) runWorker(
+	ctx context.Context,
+	sess sarama.ConsumerGroupSession,
+	claim sarama.ConsumerGroupClaim,
+	tracker *commitTracker,
+	in <-chan ConsumerMessage,
+) error {
+	batch := make([]ConsumerMessage, 0, h.maxBatchSize())
+	timer := time.NewTimer(h.batchTimeout)
+	defer timer.Stop()
 
-		// For drops: log + metrics + commit so the partition doesn't stall
-		for _, d := range dropped {
-			log.Printf("[Kafka Consumer] Dropping poison message at %s[%d]@%d key=%q",
-				d.ID.Topic, d.ID.Partition, d.ID.Offset, d.ID.Key)
-			sess.MarkOffset(d.ID.Topic, d.ID.Partition, d.ID.Offset+1, "")
+	resetTimer := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
 		}
+		timer.Reset(h.batchTimeout)
+	}
 
-		// flush offsets promptly
-		// only commit if we have marked offsets
-		// this avoids committing empty batches which can lead to confusion
-		if len(succeeded)+len(dropped) > 0 {
-			sess.Commit()
-		}
+	processAndCommit := func(b []ConsumerMessage) error {
+		return h.processAndCommit(ctx, sess, claim.Topic(), claim.Partition(), tracker, b)
 	}
 
 	for {
 		select {
-		case cm, ok := <-claim.Messages():
+		case m, ok := <-in:
 			if !ok {
-				processAndCommit(batch)
-				return nil
+				return processAndCommit(batch)
 			}
 			// collect message
-			batch = append(batch, toConsumerMessage(cm))
-			if len(batch) >= h.batchSize {
-				processAndCommit(batch)
+			batch = append(batch, m)
+			if len(batch) >= h.currentBatchSize() {
+				if err := processAndCommit(batch); err != nil {
+					return err
+				}
 				batch = batch[:0] // reset batch
 				resetTimer()      // reset timer after processing
 			}
 
 		case <-timer.C:
-			processAndCommit(batch)
+			if err := processAndCommit(batch); err != nil {
+				return err
+			}
 			batch = batch[:0]
 			resetTimer()
-		case <-sess.Context().Done():
-			if len(batch) > 0 {
-				processAndCommit(batch)
+		case <-ctx.Done():
+			if sess.Context().Err() != nil {
+				// the dispatch stops on ctx too and closes the input
+				for m := range in {
+					batch = append(batch, m)
+				}
+				h.lifecycle.park(pendingBatch{topic: claim.Topic(), partition: claim.Partition(), tracker: tracker, msgs: batch})
 			}
 			return nil
 		}
 	}
 }
 
-// Bisection fallback (no need for a single-message handler)
-func (h batchMessageHandler) processWithBisection(ctx context.Context, batch []ConsumerMessage) (succeeded, dropped []ConsumerMessage) {
+// processAndCommit processes a batch of a claim and marks the offsets the tracker allows to commit.
+// The attempt in flight when the session ends is finished so its writes get committed, but a failed batch
+// isn't bisected then: the failure may only come from the rebalance, the batch is consumed again instead.
+func (h batchMessageHandler) processAndCommit(
+	ctx context.Context,
+	sess sarama.ConsumerGroupSession,
+	topic string,
+	partition int32,
+	tracker *commitTracker,
+	b []ConsumerMessage,
+) error {
+	if len(b) == 0 {
+		return nil
+	}
+
+	commitSuccesses := func(done []ConsumerMessage) {
+		offsets := make([]int64, len(done))
+		for i, m := range done {
+			offsets[i] = m.ID.Offset
+		}
+		// Mark only up to the offsets that truly succeeded, in this worker and the others
+		if next, ok := tracker.markDone(offsets...); ok {
+			sess.MarkOffset(topic, partition, next, "")
+			// flush offsets promptly
+			start := time.Now()
+			sess.Commit()
+			h.lifecycle.committed(topic, partition, next, time.Since(start))
+		}
+	}
+
+	log.Printf("[Kafka Consumer] Processing batch of %d messages...\n", len(b))
+
+	// Try whole batch with retry, each attempt is bounded by the batch timeout and the retries
+	// by the session and the MaxElapsedTime of the retry policy
+	attempts, err := h.processBatch(ctx, b)
+	if err == nil {
+		commitSuccesses(b)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	// A batch failing for none of its messages goes to the retry topics as a whole rather than being bisected,
+	// which would make as many attempts as the batch has messages to fail each of them anyway
+	if errors.Is(err, ErrBatchFailed) && len(h.retryPolicy.Tiers) > 0 {
+		dropped, retryErr := h.publishFailures(ctx, b, err, attempts)
+		if len(dropped) > 0 {
+			commitSuccesses(dropped)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		return retryErr
+	}
+
+	// Fallback: bisect to isolate bad records, sending them to a retry topic or dead lettering them
+	// as they are found. Bisection stops at a drop that can't be published so nothing past it is processed
+	// or marked, and the claim ends to have the partition re-consumed from it.
+	succeeded, dropped, dlqErr := h.processWithBisection(ctx, b, attempts, err)
+
+	// Commit successful, retried and dead lettered records to make progress
+	// only commit if we have marked offsets
+	if len(succeeded)+len(dropped) > 0 {
+		commitSuccesses(append(succeeded, dropped...))
+	}
+	if ctx.Err() != nil {
+		// the rest of the batch is consumed again by the next owner of the partition
+		return nil
+	}
+
+	return dlqErr
+}
+
+// shardOf picks the worker of a message, messages without a key have no order to keep
+func shardOf(m ConsumerMessage, workers int) int {
+	if workers <= 1 {
+		return 0
+	}
+	if m.ID.Key == "" {
+		return int(m.ID.Offset % int64(workers))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(m.ID.Key))
+	return int(h.Sum32() % uint32(workers))
+}
+
+// Bisection fallback (no need for a single-message handler).
+// Messages failing even alone are retried later or dead lettered right away, in offset order, and the
+// bisection stops if one can't be. Their attempts include the ones spent on the whole batch.
+// Once the bisection spent the MaxBisectAttempts of the retry policy, the messages left are handled as failing
+// alone with the error of the last attempt that included them.
+// Like in processBatch, a session ending doesn't cancel the attempt in flight, but the bisection stops with
+// the context error before classifying anything else as the failure may only come from the rebalance.
+func (h batchMessageHandler) processWithBisection(
+	ctx context.Context,
+	batch []ConsumerMessage,
+	batchAttempts int,
+	batchErr error,
+) (succeeded, dropped []ConsumerMessage, err error) {
 	var ok, bad []ConsumerMessage
+	var spent int
 
-	var bisect func([]ConsumerMessage)
-	bisect = func(b []ConsumerMessage) {
+	var bisect func(b []ConsumerMessage, attempts int, cause error) error
+	bisect = func(b []ConsumerMessage, attempts int, cause error) error {
 		if len(b) == 0 {
-			return
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if limit := h.retryPolicy.MaxBisectAttempts; limit > 0 && spent >= limit {
+			published, err := h.publishFailures(ctx, b, cause, attempts)
+			bad = append(bad, published...)
+			return err
 		}
 		// try processing the batch
-		if err := h.handler(ctx, b); err == nil {
+		spent++
+		attempts++
+		attemptCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.batchTimeout)
+		err := h.handler(attemptCtx, b)
+		cancel()
+		if err == nil {
 			ok = append(ok, b...)
-			return
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 		if len(b) == 1 {
-			// Cannot be processed even alone → drop
+			// Cannot be processed even alone → retry topic or dead letter
+			if err := h.publishFailure(ctx, droppedMessage{msg: b[0], err: err, attempts: attempts}); err != nil {
+				return err
+			}
 			bad = append(bad, b[0])
-			return
+			return nil
 		}
 		mid := len(b) / 2
-		bisect(b[:mid])
-		bisect(b[mid:])
+		if err := bisect(b[:mid], attempts, err); err != nil {
+			return err
+		}
+		return bisect(b[mid:], attempts, err)
 	}
 
-	bisect(batch)
-	return ok, bad
+	err = bisect(batch, batchAttempts, batchErr)
+	return ok, bad, err
 }
 
-func (h batchMessageHandler) processBatch(ctx context.Context, batch []ConsumerMessage) error {
+// processBatch retries the whole batch and returns how many times it was attempted
+func (h batchMessageHandler) processBatch(ctx context.Context, batch []ConsumerMessage) (int, error) {
+	var attempts int
 	// wrap in retry
-	return backoff.Retry(func() error {
-		return h.handler(ctx, batch)
-	}, backoff.WithContext(consumeBackoff(h.maxRetriesPerMsg), ctx))
+	err := backoff.Retry(func() error {
+		attempts++
+		start := time.Now()
+		// a rebalance stops the retries but not the attempt in flight
+		attemptCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.batchTimeout)
+		err := h.handler(attemptCtx, batch)
+		cancel()
+		if h.batchSizer != nil {
+			h.batchSizer.observe(len(batch), time.Since(start), err)
+		}
+		return err
+	}, backoff.WithContext(h.retryPolicy.backOff(), ctx))
+	return attempts, err
+}
+
+// currentBatchSize is the number of messages a worker collects before processing them
+func (h batchMessageHandler) currentBatchSize() int {
+	if h.batchSizer != nil {
+		return h.batchSizer.Size()
+	}
+	return h.batchSize
+}
+
+// maxBatchSize is the most messages a worker can collect
+func (h batchMessageHandler) maxBatchSize() int {
+	if h.batchSizer != nil {
+		return h.batchSizer.cfg.Max
+	}
+	return h.batchSize
+}
+
+// publishFailure sends a dropped message to its next retry tier, or to the dead letter topic once it ran out of attempts
+func (h batchMessageHandler) publishFailure(ctx context.Context, d droppedMessage) error {
+	tier, attempt, ok := h.retryPolicy.nextTier(d.msg)
+	if !ok {
+		return h.publishDeadLetter(ctx, d)
+	}
+
+	err := backoff.Retry(func() error {
+		return h.retryPublisher.PublishRetry(ctx, d.msg, d.err, tier, attempt)
+	}, backoff.WithContext(deadLetterBackoff(), ctx))
+	if err != nil {
+		return pkgerrors.Wrap(err, "retrying failed message")
+	}
+
+	log.Printf("[Kafka Consumer] Retrying failed message at %s[%d]@%d key=%q in %v through %s, retry %d. Err: %v",
+		d.msg.ID.Topic, d.msg.ID.Partition, d.msg.ID.Offset, d.msg.ID.Key, tier.Delay, tier.Topic, attempt, d.err)
+	return nil
+}
+
+// publishFailures sends the failed messages to their next retry tier or the dead letter topic in offset order,
+// and stops at the first one that can't be
+func (h batchMessageHandler) publishFailures(
+	ctx context.Context,
+	msgs []ConsumerMessage,
+	cause error,
+	attempts int,
+) ([]ConsumerMessage, error) {
+	for i, m := range msgs {
+		if err := h.publishFailure(ctx, droppedMessage{msg: m, err: cause, attempts: attempts}); err != nil {
+			return msgs[:i], err
+		}
+	}
+	return msgs, nil
+}
+
+// publishDeadLetter hands a dropped message to the dead letter publisher, or only logs it when none is configured
+func (h batchMessageHandler) publishDeadLetter(ctx context.Context, d droppedMessage) error {
+	if h.deadLetter == nil {
+		log.Printf("[Kafka Consumer] Dropping poison message at %s[%d]@%d key=%q after %d attempts. Err: %v",
+			d.msg.ID.Topic, d.msg.ID.Partition, d.msg.ID.Offset, d.msg.ID.Key, d.attempts, d.err)
+		return nil
+	}
+
+	err := backoff.Retry(func() error {
+		return h.deadLetter.PublishDeadLetter(ctx, d.msg, d.err, d.attempts)
+	}, backoff.WithContext(deadLetterBackoff(), ctx))
+	if err != nil {
+		return pkgerrors.Wrap(err, "dead lettering poison message")
+	}
+
+	log.Printf("[Kafka Consumer] Dead lettered poison message at %s[%d]@%d key=%q after %d attempts",
+		d.msg.ID.Topic, d.msg.ID.Partition, d.msg.ID.Offset, d.msg.ID.Key, d.attempts)
+	return nil
 }
 
 func toConsumerMessage(cm *sarama.ConsumerMessage) ConsumerMessage {
@@ -244,11 +569,9 @@ func toConsumerMessage(cm *sarama.ConsumerMessage) ConsumerMessage {
 			Offset:    cm.Offset,
 			Key:       string(cm.Key),
 		},
-		Value:   cm.Value,
-		Headers: make(map[string]string, len(cm.Headers)),
-	}
-	for _, h := range cm.Headers {
-		m.Headers[string(h.Key)] = string(h.Value)
+		Value:     cm.Value,
+		Headers:   recordHeaders(cm.Headers),
+		Timestamp: cm.Timestamp,
 	}
 	return m
 }
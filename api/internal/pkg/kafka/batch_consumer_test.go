@@ -0,0 +1,276 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessWithBisection(t *testing.T) {
+	msgs := make([]ConsumerMessage, 4)
+	for i := range msgs {
+		msgs[i] = ConsumerMessage{ID: ConsumerMessageID{Offset: int64(i)}}
+	}
+	errPoison := errors.New("poison")
+
+	// Given: offset 2 fails whenever it is part of the batch
+	h := batchMessageHandler{
+		handler: func(_ context.Context, b []ConsumerMessage) error {
+			for _, m := range b {
+				if m.ID.Offset == 2 {
+					return errPoison
+				}
+			}
+			return nil
+		},
+	}
+
+	origBackoff := deadLetterBackoff
+	deadLetterBackoff = func() backoff.BackOff { return &backoff.StopBackOff{} }
+	defer func() { deadLetterBackoff = origBackoff }()
+	dlq := &fakeDeadLetter{}
+	h.deadLetter = dlq
+
+	// When:
+	succeeded, dropped, err := h.processWithBisection(context.Background(), msgs, 3, errPoison)
+
+	// Then: 3 batch attempts + [0..3], [2,3] and [2]
+	require.NoError(t, err)
+	require.Equal(t, []ConsumerMessage{msgs[0], msgs[1], msgs[3]}, succeeded)
+	require.Equal(t, []ConsumerMessage{msgs[2]}, dropped)
+	require.Equal(t, []droppedMessage{{msg: msgs[2], err: errPoison, attempts: 6}}, dlq.published)
+
+	// When: the dead letter can't be published
+	dlq.err = errors.New("broker down")
+	succeeded, dropped, err = h.processWithBisection(context.Background(), msgs, 3, errPoison)
+
+	// Then: nothing after it is processed
+	require.ErrorIs(t, err, dlq.err)
+	require.Equal(t, []ConsumerMessage{msgs[0], msgs[1]}, succeeded)
+	require.Empty(t, dropped)
+}
+
+func TestProcessAndCommit_SessionEndsDuringBisection(t *testing.T) {
+	msgs := make([]ConsumerMessage, 4)
+	tracker := newCommitTracker()
+	for i := range msgs {
+		msgs[i] = ConsumerMessage{ID: ConsumerMessageID{Topic: "iot.sensor", Partition: 1, Offset: int64(i)}}
+		tracker.add(int64(i))
+	}
+
+	// Given: the session ends during the first bisection attempt, which fails because of it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var attempts int
+	var attemptErrs []error
+	h := batchMessageHandler{
+		batchTimeout: time.Minute,
+		handler: func(attemptCtx context.Context, _ []ConsumerMessage) error {
+			attempts++
+			if attempts == 2 {
+				cancel()
+			}
+			attemptErrs = append(attemptErrs, attemptCtx.Err())
+			return errors.New("rebalance in progress")
+		},
+	}
+	origBackoff := deadLetterBackoff
+	deadLetterBackoff = func() backoff.BackOff { return &backoff.StopBackOff{} }
+	defer func() { deadLetterBackoff = origBackoff }()
+	dlq := &fakeDeadLetter{}
+	retries := &fakeRetry{}
+	h.deadLetter = dlq
+	h.retryPolicy = RetryPolicy{MaxAttempts: 2, Tiers: []RetryTier{{Topic: "iot.retry.1m", Delay: time.Minute}}}
+	h.retryPublisher = retries
+	sess := &fakeSession{ctx: ctx}
+
+	// When:
+	err := h.processAndCommit(ctx, sess, "iot.sensor", 1, tracker, msgs)
+
+	// Then: the attempt in flight isn't cancelled, nothing is retried, dead lettered or marked
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, []error{nil, nil}, attemptErrs)
+	require.Empty(t, retries.published)
+	require.Empty(t, dlq.published)
+	require.Empty(t, sess.markedOffsets())
+}
+
+type fakeDeadLetter struct {
+	published []droppedMessage
+	err       error
+}
+
+func (f *fakeDeadLetter) PublishDeadLetter(_ context.Context, msg ConsumerMessage, cause error, attempts int) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, droppedMessage{msg: msg, err: cause, attempts: attempts})
+	return nil
+}
+
+func TestConsumeClaimWorkers(t *testing.T) {
+	// Given: TEMP_002 is on another worker than TEMP_001 and HUM_001, offset 0 is slow
+	keys := []string{"TEMP_001", "TEMP_002", "HUM_001", "TEMP_001", "TEMP_002", "HUM_001"}
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(keys))}
+	for i, k := range keys {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "iot.sensor", Partition: 1, Offset: int64(i), Key: []byte(k)}
+	}
+	close(claim.messages)
+
+	var mu sync.Mutex
+	processed := map[string][]int64{}
+	slowDone := make(chan struct{})
+	h := batchMessageHandler{
+		batchSize:    1,
+		batchTimeout: time.Minute,
+		workers:      2,
+		handler: func(_ context.Context, b []ConsumerMessage) error {
+			if b[0].ID.Offset == 0 {
+				<-slowDone
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			processed[b[0].ID.Key] = append(processed[b[0].ID.Key], b[0].ID.Offset)
+			return nil
+		},
+	}
+	sess := &fakeSession{ctx: context.Background()}
+	var markedBeforeSlow []int64
+	var processedBeforeSlow []int64
+	go func() {
+		// let the other worker go ahead
+		time.Sleep(50 * time.Millisecond)
+		markedBeforeSlow = sess.markedOffsets()
+		mu.Lock()
+		processedBeforeSlow = append(processedBeforeSlow, processed["TEMP_002"]...)
+		mu.Unlock()
+		close(slowDone)
+	}()
+
+	// When:
+	err := h.ConsumeClaim(sess, claim)
+
+	// Then: per key order is kept and the offset only moved once offset 0 was done
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, processedBeforeSlow) // then the slow worker's input is full
+	require.Empty(t, markedBeforeSlow)
+	require.Equal(t, map[string][]int64{"TEMP_001": {0, 3}, "TEMP_002": {1, 4}, "HUM_001": {2, 5}}, processed)
+	require.Equal(t, int64(6), sess.marked[len(sess.marked)-1])
+	require.IsIncreasing(t, sess.marked)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return "iot.sensor" }
+func (c *fakeClaim) Partition() int32                         { return 1 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	claims map[string][]int32
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context   { return s.ctx }
+func (s *fakeSession) Commit()                    {}
+func (s *fakeSession) Claims() map[string][]int32 { return s.claims }
+func (s *fakeSession) MemberID() string           { return "member-1" }
+func (s *fakeSession) GenerationID() int32        { return 2 }
+func (s *fakeSession) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, offset)
+}
+
+func (s *fakeSession) markedOffsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+func TestConsumeClaimRebalance(t *testing.T) {
+	// Given: a batch is being collected when the session ends
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+	for i, k := range []string{"TEMP_001", "TEMP_002", "HUM_001"} {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "iot.sensor", Partition: 1, Offset: int64(i), Key: []byte(k)}
+	}
+
+	var processed []int64
+	var events []RebalanceEvent
+	lifecycle := newConsumerLifecycle(&consumerConfig{
+		groupID:     "iot",
+		onRebalance: func(e RebalanceEvent) { events = append(events, e) },
+	})
+	h := batchMessageHandler{
+		batchSize:    10,
+		batchTimeout: time.Minute,
+		workers:      1,
+		lifecycle:    lifecycle,
+		handler: func(_ context.Context, b []ConsumerMessage) error {
+			for _, m := range b {
+				processed = append(processed, m.ID.Offset)
+			}
+			return nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sess := &fakeSession{ctx: ctx, claims: map[string][]int32{"iot.sensor": {1}}}
+	require.NoError(t, h.Setup(sess))
+	go func() {
+		// let the worker collect the messages
+		for len(claim.messages) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	// When:
+	require.NoError(t, h.ConsumeClaim(sess, claim))
+
+	// Then: the batch is only flushed on cleanup
+	require.Empty(t, processed)
+	require.Empty(t, sess.markedOffsets())
+
+	// When:
+	require.NoError(t, h.Cleanup(sess))
+
+	// Then:
+	require.Equal(t, []int64{0, 1, 2}, processed)
+	require.Equal(t, []int64{3}, sess.markedOffsets())
+	require.Equal(t, map[string]int64{"iot.sensor/1": 3}, lifecycle.lastCommitted)
+	require.Equal(t, []RebalanceEvent{{GroupID: "iot", MemberID: "member-1", GenerationID: 2, Claims: sess.claims}}, events)
+}
+
+func TestToConsumerMessage(t *testing.T) {
+	// Given: a record with a repeated header
+	cm := &sarama.ConsumerMessage{
+		Topic: "iot.sensor", Partition: 1, Offset: 7, Key: []byte("TEMP_001"), Value: []byte(`{}`),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("baggage"), Value: []byte("a=1")},
+			{Key: []byte(HeaderMessageID), Value: []byte("iot.sensor/1/3")},
+			{Key: []byte("baggage"), Value: []byte("b=2")},
+		},
+	}
+
+	// When:
+	m := toConsumerMessage(cm)
+
+	// Then: the headers are kept in order, a lookup gets the last one of its key
+	require.Equal(t, headersOf("baggage", "a=1", HeaderMessageID, "iot.sensor/1/3", "baggage", "b=2"), m.Headers)
+	require.Equal(t, "b=2", m.Header("baggage"))
+	require.Equal(t, "iot.sensor/1/3", m.MessageID())
+	require.Empty(t, m.Header("trace-id"))
+}
@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSizerErrorWeight is the weight of the last batch in the error rate moving average
+	batchSizerErrorWeight = 0.2
+	// batchSizerMaxErrorRate stops growing the batches while the handler fails more often than this
+	batchSizerMaxErrorRate = 0.1
+)
+
+// AdaptiveBatchConfig bounds the batch size picked by an AdaptiveBatchSizer
+type AdaptiveBatchConfig struct {
+	Initial       int
+	Min           int
+	Max           int
+	TargetLatency time.Duration
+}
+
+// AdaptiveBatchSizer grows or shrinks the batch size from the observed handler latency and errors,
+// aiming at the target latency. It is shared by all the claims of a consumer as they hit the same database.
+type AdaptiveBatchSizer struct {
+	cfg      AdaptiveBatchConfig
+	onResize func(size int)
+
+	mu        sync.Mutex
+	size      int
+	errorRate float64
+}
+
+// NewAdaptiveBatchSizer creates a new AdaptiveBatchSizer, onResize is called with every new size,
+// the initial one included, and can be nil
+func NewAdaptiveBatchSizer(cfg AdaptiveBatchConfig, onResize func(size int)) (*AdaptiveBatchSizer, error) {
+	if cfg.Min < 1 || cfg.Max < cfg.Min {
+		return nil, errors.New("adaptive batch bounds must satisfy 1 <= min <= max")
+	}
+	if cfg.Initial < cfg.Min || cfg.Initial > cfg.Max {
+		return nil, errors.New("initial batch size is out of the adaptive bounds")
+	}
+	if cfg.TargetLatency <= 0 {
+		return nil, errors.New("adaptive batch target latency must be positive")
+	}
+
+	s := &AdaptiveBatchSizer{cfg: cfg, onResize: onResize, size: cfg.Initial}
+	if onResize != nil {
+		onResize(cfg.Initial)
+	}
+	return s, nil
+}
+
+// WithAdaptiveBatchSize lets the sizer pick the batch size instead of the fixed one of the consumer
+func WithAdaptiveBatchSize(sizer *AdaptiveBatchSizer) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.batchSizer = sizer
+	}
+}
+
+// Size returns the current batch size
+func (s *AdaptiveBatchSizer) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// observe adjusts the size from a handler call over n messages.
+// Failures halve it. Otherwise the latency is taken as proportional to the batch length, so the size
+// moves halfway to the one expected to hit the target, at most doubling at once.
+func (s *AdaptiveBatchSizer) observe(n int, latency time.Duration, err error) {
+	if n == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	s.errorRate = batchSizerErrorWeight*failed + (1-batchSizerErrorWeight)*s.errorRate
+
+	size := s.size
+	switch {
+	case err != nil:
+		size /= 2
+	case latency > 0:
+		ideal := int(float64(n) * float64(s.cfg.TargetLatency) / float64(latency))
+		next := (size + ideal) / 2
+		if next > size && s.errorRate > batchSizerMaxErrorRate {
+			next = size
+		}
+		size = min(next, 2*size)
+	}
+	size = max(s.cfg.Min, min(s.cfg.Max, size))
+
+	if size != s.size && s.onResize != nil {
+		s.onResize(size)
+	}
+	s.size = size
+}
@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveBatchSizer(t *testing.T) {
+	type observation struct {
+		n       int
+		latency time.Duration
+		err     error
+	}
+	tcs := map[string]struct {
+		givenObservations []observation
+		expSize           int
+	}{
+		"grows_below_target": {
+			givenObservations: []observation{{n: 100, latency: 100 * time.Millisecond}},
+			expSize:           200, // ideal is 500, capped at twice the size
+		},
+		"shrinks_above_target": {
+			givenObservations: []observation{{n: 100, latency: time.Second}},
+			expSize:           75, // halfway to 50
+		},
+		"converges_to_target": {
+			givenObservations: []observation{
+				{n: 100, latency: 250 * time.Millisecond},
+				{n: 150, latency: 375 * time.Millisecond},
+				{n: 175, latency: 437 * time.Millisecond},
+			},
+			expSize: 187,
+		},
+		"halves_on_error": {
+			givenObservations: []observation{{n: 100, latency: time.Millisecond, err: errors.New("db down")}},
+			expSize:           50,
+		},
+		"does_not_grow_while_failing": {
+			givenObservations: []observation{
+				{n: 100, err: errors.New("db down")},
+				{n: 50, latency: time.Millisecond},
+			},
+			expSize: 50,
+		},
+		"kept_within_bounds": {
+			givenObservations: []observation{
+				{n: 100, err: errors.New("db down")},
+				{n: 50, err: errors.New("db down")},
+				{n: 25, err: errors.New("db down")},
+			},
+			expSize: 20,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			var resized []int
+			sizer, err := NewAdaptiveBatchSizer(AdaptiveBatchConfig{
+				Initial:       100,
+				Min:           20,
+				Max:           1000,
+				TargetLatency: 500 * time.Millisecond,
+			}, func(size int) {
+				resized = append(resized, size)
+			})
+			require.NoError(t, err)
+
+			// When:
+			for _, o := range tc.givenObservations {
+				sizer.observe(o.n, o.latency, o.err)
+			}
+
+			// Then:
+			require.Equal(t, tc.expSize, sizer.Size())
+			require.Equal(t, tc.expSize, resized[len(resized)-1])
+		})
+	}
+}
+
+func TestNewAdaptiveBatchSizer_InvalidConfig(t *testing.T) {
+	tcs := map[string]AdaptiveBatchConfig{
+		"min_above_max":      {Initial: 10, Min: 20, Max: 10, TargetLatency: time.Second},
+		"initial_out_bounds": {Initial: 5, Min: 10, Max: 20, TargetLatency: time.Second},
+		"no_target":          {Initial: 10, Min: 10, Max: 20},
+	}
+	for desc, cfg := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			_, err := NewAdaptiveBatchSizer(cfg, nil)
+
+			// Then:
+			require.Error(t, err)
+		})
+	}
+}
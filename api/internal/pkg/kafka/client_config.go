@@ -0,0 +1,270 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Rebalance strategies of the consumer groups
+const (
+	RebalanceRange      = "range"
+	RebalanceRoundRobin = "roundrobin"
+	RebalanceSticky     = "sticky"
+)
+
+// SASL mechanisms
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// ClientConfig is the connection and group membership config shared by every client of this pkg.
+// Zero values keep sarama's defaults.
+type ClientConfig struct {
+	Brokers  []string   `yaml:"brokers"`
+	ClientID string     `yaml:"client_id"`
+	Version  string     `yaml:"version"` // Kafka version of the brokers, e.g. 3.6.0
+	TLS      TLSConfig  `yaml:"tls"`
+	SASL     SASLConfig `yaml:"sasl"`
+
+	SessionTimeout    time.Duration `yaml:"session_timeout"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	RebalanceStrategy string        `yaml:"rebalance_strategy"`
+	InstanceID        string        `yaml:"instance_id"` // static group membership, a restart within the session timeout doesn't rebalance
+
+	FetchMinBytes     int32 `yaml:"fetch_min_bytes"`
+	FetchDefaultBytes int32 `yaml:"fetch_default_bytes"`
+	FetchMaxBytes     int32 `yaml:"fetch_max_bytes"`
+}
+
+// TLSConfig enables TLS, the client certificate is optional
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// SASLConfig enables SASL authentication when Mechanism is set
+type SASLConfig struct {
+	Mechanism string `yaml:"mechanism"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+}
+
+// ClientConfigFromEnv loads the YAML file of KAFKA_CONFIG_FILE if any, then overrides it with the KAFKA_* env vars.
+// KAFKA_BROKERS is a comma separated list, KAFKA_BROKER is still read for a single broker.
+func ClientConfigFromEnv() (ClientConfig, error) {
+	var cfg ClientConfig
+	if path := os.Getenv("KAFKA_CONFIG_FILE"); path != "" {
+		var err error
+		if cfg, err = LoadClientConfig(path); err != nil {
+			return ClientConfig{}, err
+		}
+	}
+
+	lookup := func(key string, set func(v string) error) error {
+		v, ok := os.LookupEnv(key)
+		if !ok || v == "" {
+			return nil
+		}
+		if err := set(v); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return nil
+	}
+	str := func(dst *string) func(string) error {
+		return func(v string) error { *dst = v; return nil }
+	}
+	boolean := func(dst *bool) func(string) error {
+		return func(v string) (err error) { *dst, err = strconv.ParseBool(v); return }
+	}
+	duration := func(dst *time.Duration) func(string) error {
+		return func(v string) (err error) { *dst, err = time.ParseDuration(v); return }
+	}
+	bytes := func(dst *int32) func(string) error {
+		return func(v string) error {
+			n, err := strconv.ParseInt(v, 10, 32)
+			*dst = int32(n)
+			return err
+		}
+	}
+	brokers := func(v string) error {
+		cfg.Brokers = nil
+		for _, b := range strings.Split(v, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				cfg.Brokers = append(cfg.Brokers, b)
+			}
+		}
+		return nil
+	}
+
+	for _, e := range []struct {
+		key string
+		set func(string) error
+	}{
+		{"KAFKA_BROKER", brokers},
+		{"KAFKA_BROKERS", brokers},
+		{"KAFKA_CLIENT_ID", str(&cfg.ClientID)},
+		{"KAFKA_VERSION", str(&cfg.Version)},
+		{"KAFKA_TLS_ENABLED", boolean(&cfg.TLS.Enabled)},
+		{"KAFKA_TLS_CA_FILE", str(&cfg.TLS.CAFile)},
+		{"KAFKA_TLS_CERT_FILE", str(&cfg.TLS.CertFile)},
+		{"KAFKA_TLS_KEY_FILE", str(&cfg.TLS.KeyFile)},
+		{"KAFKA_TLS_INSECURE_SKIP_VERIFY", boolean(&cfg.TLS.InsecureSkipVerify)},
+		{"KAFKA_SASL_MECHANISM", str(&cfg.SASL.Mechanism)},
+		{"KAFKA_SASL_USERNAME", str(&cfg.SASL.Username)},
+		{"KAFKA_SASL_PASSWORD", str(&cfg.SASL.Password)},
+		{"KAFKA_SESSION_TIMEOUT", duration(&cfg.SessionTimeout)},
+		{"KAFKA_HEARTBEAT_INTERVAL", duration(&cfg.HeartbeatInterval)},
+		{"KAFKA_REBALANCE_STRATEGY", str(&cfg.RebalanceStrategy)},
+		{"KAFKA_GROUP_INSTANCE_ID", str(&cfg.InstanceID)},
+		{"KAFKA_FETCH_MIN_BYTES", bytes(&cfg.FetchMinBytes)},
+		{"KAFKA_FETCH_DEFAULT_BYTES", bytes(&cfg.FetchDefaultBytes)},
+		{"KAFKA_FETCH_MAX_BYTES", bytes(&cfg.FetchMaxBytes)},
+	} {
+		if err := lookup(e.key, e.set); err != nil {
+			return ClientConfig{}, err
+		}
+	}
+
+	if _, err := cfg.saramaConfig(); err != nil {
+		return ClientConfig{}, err
+	}
+	return cfg, nil
+}
+
+// LoadClientConfig reads the config from a YAML file, durations are written like 10s
+func LoadClientConfig(path string) (ClientConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ClientConfig{}, pkgerrors.Wrap(err, "reading kafka config file")
+	}
+	var cfg ClientConfig
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return ClientConfig{}, pkgerrors.Wrap(err, "parsing kafka config file")
+	}
+	return cfg, nil
+}
+
+// saramaConfig builds a new sarama config from the client config, every client gets its own
+func (c ClientConfig) saramaConfig() (*sarama.Config, error) {
+	if len(c.Brokers) == 0 {
+		return nil, errors.New("no kafka broker")
+	}
+
+	cfg := sarama.NewConfig()
+	if c.ClientID != "" {
+		cfg.ClientID = c.ClientID
+	}
+	if c.Version != "" {
+		v, err := sarama.ParseKafkaVersion(c.Version)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "invalid kafka version")
+		}
+		cfg.Version = v
+	}
+
+	if c.TLS.Enabled {
+		tlsCfg, err := c.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Net.TLS.Enable = true
+		cfg.Net.TLS.Config = tlsCfg
+	}
+
+	if c.SASL.Mechanism != "" {
+		cfg.Net.SASL.Enable = true
+		cfg.Net.SASL.User = c.SASL.Username
+		cfg.Net.SASL.Password = c.SASL.Password
+		switch strings.ToUpper(c.SASL.Mechanism) {
+		case SASLPlain:
+			cfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case SASLScramSHA256:
+			cfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			cfg.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hashGen: scramSHA256} }
+		case SASLScramSHA512:
+			cfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			cfg.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hashGen: scramSHA512} }
+		default:
+			return nil, fmt.Errorf("invalid sasl mechanism %q, expected one of %s, %s, %s", c.SASL.Mechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+		}
+	}
+
+	if c.SessionTimeout > 0 {
+		cfg.Consumer.Group.Session.Timeout = c.SessionTimeout
+	}
+	if c.HeartbeatInterval > 0 {
+		cfg.Consumer.Group.Heartbeat.Interval = c.HeartbeatInterval
+	}
+	switch c.RebalanceStrategy {
+	case "", RebalanceRange:
+	case RebalanceRoundRobin:
+		cfg.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
+	case RebalanceSticky:
+		cfg.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+	case "cooperative-sticky":
+		// sarama only implements the eager rebalance protocol
+		return nil, errors.New("cooperative-sticky rebalancing isn't supported by the kafka client, use sticky with a group instance id")
+	default:
+		return nil, fmt.Errorf("invalid rebalance strategy %q, expected one of %s, %s, %s", c.RebalanceStrategy, RebalanceRange, RebalanceRoundRobin, RebalanceSticky)
+	}
+	cfg.Consumer.Group.InstanceId = c.InstanceID
+
+	if c.FetchMinBytes > 0 {
+		cfg.Consumer.Fetch.Min = c.FetchMinBytes
+	}
+	if c.FetchDefaultBytes > 0 {
+		cfg.Consumer.Fetch.Default = c.FetchDefaultBytes
+	}
+	if c.FetchMaxBytes > 0 {
+		cfg.Consumer.Fetch.Max = c.FetchMaxBytes
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, pkgerrors.Wrap(err, "invalid kafka client config")
+	}
+	return cfg, nil
+}
+
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify, // #nosec G402 -- opt-in for test clusters
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "reading kafka CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no certificate found in kafka CA file")
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "loading kafka client certificate")
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
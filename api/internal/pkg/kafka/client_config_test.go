@@ -0,0 +1,140 @@
+package kafka
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientConfigFromEnv(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "kafka.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+brokers: [kafka-1:9092, kafka-2:9092]
+client_id: iot
+version: 3.6.0
+session_timeout: 30s
+sasl:
+  mechanism: SCRAM-SHA-512
+  username: iot
+  password: secret
+`), 0o600))
+
+	tcs := map[string]struct {
+		givenEnv  map[string]string
+		expResult ClientConfig
+		expErr    string
+	}{
+		"single_broker": {
+			givenEnv:  map[string]string{"KAFKA_BROKER": "kafka:9092"},
+			expResult: ClientConfig{Brokers: []string{"kafka:9092"}},
+		},
+		"broker_list": {
+			givenEnv: map[string]string{
+				"KAFKA_BROKERS":            "kafka-1:9092, kafka-2:9092,",
+				"KAFKA_VERSION":            "2.3.0",
+				"KAFKA_HEARTBEAT_INTERVAL": "2s",
+				"KAFKA_REBALANCE_STRATEGY": RebalanceSticky,
+				"KAFKA_GROUP_INSTANCE_ID":  "iot-0",
+				"KAFKA_FETCH_MIN_BYTES":    "1024",
+			},
+			expResult: ClientConfig{
+				Brokers:           []string{"kafka-1:9092", "kafka-2:9092"},
+				Version:           "2.3.0",
+				HeartbeatInterval: 2 * time.Second,
+				RebalanceStrategy: RebalanceSticky,
+				InstanceID:        "iot-0",
+				FetchMinBytes:     1024,
+			},
+		},
+		"file_overridden_by_env": {
+			givenEnv: map[string]string{
+				"KAFKA_CONFIG_FILE":   file,
+				"KAFKA_SASL_PASSWORD": "other",
+			},
+			expResult: ClientConfig{
+				Brokers:        []string{"kafka-1:9092", "kafka-2:9092"},
+				ClientID:       "iot",
+				Version:        "3.6.0",
+				SessionTimeout: 30 * time.Second,
+				SASL:           SASLConfig{Mechanism: SASLScramSHA512, Username: "iot", Password: "other"},
+			},
+		},
+		"no_broker": {
+			expErr: "no kafka broker",
+		},
+		"invalid_duration": {
+			givenEnv: map[string]string{"KAFKA_BROKER": "kafka:9092", "KAFKA_SESSION_TIMEOUT": "30"},
+			expErr:   "invalid KAFKA_SESSION_TIMEOUT",
+		},
+		"cooperative_sticky": {
+			givenEnv: map[string]string{"KAFKA_BROKER": "kafka:9092", "KAFKA_REBALANCE_STRATEGY": "cooperative-sticky"},
+			expErr:   "cooperative-sticky rebalancing isn't supported",
+		},
+		"static_membership_needs_version": {
+			givenEnv: map[string]string{"KAFKA_BROKER": "kafka:9092", "KAFKA_GROUP_INSTANCE_ID": "iot-0"},
+			expErr:   "invalid kafka client config",
+		},
+		"invalid_sasl_mechanism": {
+			givenEnv: map[string]string{"KAFKA_BROKER": "kafka:9092", "KAFKA_SASL_MECHANISM": "GSSAPI"},
+			expErr:   `invalid sasl mechanism "GSSAPI"`,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			for _, key := range []string{"KAFKA_CONFIG_FILE", "KAFKA_BROKER", "KAFKA_BROKERS"} {
+				t.Setenv(key, "")
+			}
+			for key, v := range tc.givenEnv {
+				t.Setenv(key, v)
+			}
+
+			// When:
+			cfg, err := ClientConfigFromEnv()
+
+			// Then:
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expResult, cfg)
+		})
+	}
+}
+
+func TestClientConfigSaramaConfig(t *testing.T) {
+	// Given:
+	cfg := ClientConfig{
+		Brokers:           []string{"kafka:9092"},
+		ClientID:          "iot",
+		Version:           "3.6.0",
+		SASL:              SASLConfig{Mechanism: SASLScramSHA256, Username: "iot", Password: "secret"},
+		SessionTimeout:    30 * time.Second,
+		HeartbeatInterval: 3 * time.Second,
+		RebalanceStrategy: RebalanceRoundRobin,
+		InstanceID:        "iot-0",
+		FetchMaxBytes:     1 << 20,
+	}
+
+	// When:
+	result, err := cfg.saramaConfig()
+
+	// Then:
+	require.NoError(t, err)
+	require.Equal(t, "iot", result.ClientID)
+	require.Equal(t, sarama.V3_6_0_0, result.Version)
+	require.True(t, result.Net.SASL.Enable)
+	require.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA256), result.Net.SASL.Mechanism)
+	require.IsType(t, &scramClient{}, result.Net.SASL.SCRAMClientGeneratorFunc())
+	require.False(t, result.Net.TLS.Enable)
+	require.Equal(t, 30*time.Second, result.Consumer.Group.Session.Timeout)
+	require.Equal(t, 3*time.Second, result.Consumer.Group.Heartbeat.Interval)
+	require.Equal(t, sarama.RoundRobinBalanceStrategyName, result.Consumer.Group.Rebalance.GroupStrategies[0].Name())
+	require.Equal(t, "iot-0", result.Consumer.Group.InstanceId)
+	require.Equal(t, int32(1<<20), result.Consumer.Fetch.Max)
+}
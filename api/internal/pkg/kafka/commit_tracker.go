@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"sync"
+)
+
+// commitTracker follows the offsets of a claim handed to the workers, in offset order, and
+// tells up to which offset the partition can be committed, i.e. once all lower offsets are done.
+// Offsets are tracked as delivered since topics can have gaps, e.g. transaction markers.
+type commitTracker struct {
+	mu      sync.Mutex
+	pending []int64 // dispatched offsets not committable yet, in offset order
+	done    map[int64]struct{}
+}
+
+func newCommitTracker() *commitTracker {
+	return &commitTracker{done: make(map[int64]struct{})}
+}
+
+// add registers a dispatched offset, it must be called in offset order and before the offset is done
+func (t *commitTracker) add(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, offset)
+}
+
+// markDone flags the offsets as processed and returns the offset to commit, i.e. the one of the next
+// message to consume, and false when the lowest pending offset is still in progress.
+func (t *commitTracker) markDone(offsets ...int64) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, o := range offsets {
+		t.done[o] = struct{}{}
+	}
+
+	var next int64
+	advanced := false
+	for len(t.pending) > 0 {
+		head := t.pending[0]
+		if _, ok := t.done[head]; !ok {
+			break
+		}
+		delete(t.done, head)
+		t.pending = t.pending[1:]
+		next, advanced = head+1, true
+	}
+	return next, advanced
+}
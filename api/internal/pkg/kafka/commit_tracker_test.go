@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitTracker(t *testing.T) {
+	type step struct {
+		done    []int64
+		expNext int64
+		expOK   bool
+	}
+	tcs := map[string]struct {
+		givenPending []int64
+		steps        []step
+	}{
+		"in_order": {
+			givenPending: []int64{0, 1, 2},
+			steps: []step{
+				{done: []int64{0, 1}, expNext: 2, expOK: true},
+				{done: []int64{2}, expNext: 3, expOK: true},
+			},
+		},
+		"waits_for_lower_offsets": {
+			givenPending: []int64{0, 1, 2, 3},
+			steps: []step{
+				{done: []int64{1, 3}},
+				{done: []int64{2}},
+				{done: []int64{0}, expNext: 4, expOK: true},
+			},
+		},
+		"offset_gaps": {
+			givenPending: []int64{10, 12, 15},
+			steps: []step{
+				{done: []int64{12}},
+				{done: []int64{10}, expNext: 13, expOK: true},
+				{done: []int64{15}, expNext: 16, expOK: true},
+			},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			tracker := newCommitTracker()
+			for _, o := range tc.givenPending {
+				tracker.add(o)
+			}
+
+			for _, s := range tc.steps {
+				// When:
+				next, ok := tracker.markDone(s.done...)
+
+				// Then:
+				require.Equal(t, s.expOK, ok, "done %v", s.done)
+				require.Equal(t, s.expNext, next, "done %v", s.done)
+			}
+		})
+	}
+}
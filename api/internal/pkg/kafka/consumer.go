@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -26,17 +27,26 @@ type ConsumeHandler func(ctx context.Context, msg ConsumerMessage) error
 type consumerConfig struct {
 	*sarama.Config
 	disablePayloadLogging bool
-	maxRetriesPerMsg      int
+	retryPolicy           RetryPolicy
+	retryPublisher        RetryPublisher
 	groupID               string
 	batchSize             int
 	batchTimeout          time.Duration
+	deadLetter            DeadLetterPublisher
+	offsetStore           OffsetStore
+	lagTracker            *LagTracker
+	workers               int
+	batchSizer            *AdaptiveBatchSizer
+	logger                *slog.Logger
+	runID                 string
+	onRebalance           func(RebalanceEvent)
 }
 
-// NewConsumer creates a new consumer using the given broker addresses and configuration.
+// NewConsumer creates a new consumer using the given client configuration.
 func NewConsumer(
 	ctx context.Context,
 	topic string,
-	broker string,
+	clientCfg ClientConfig,
 	handler ConsumeHandler,
 ) (*Consumer, error) {
 	log.Printf("Initializing kafka consumer for topic: [%s]", topic)
@@ -45,12 +55,15 @@ func NewConsumer(
 		return nil, errors.New("topic is empty")
 	}
 
-	baseCfg := sarama.NewConfig()
+	baseCfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &consumerConfig{Config: baseCfg, groupID: "iot"}
-	cfg.maxRetriesPerMsg = 35 // This evaluates to around 13hrs with the current backoff config
+	cfg.retryPolicy = DefaultRetryPolicy()
 
-	client, err := sarama.NewClient([]string{broker}, cfg.Config)
+	client, err := sarama.NewClient(clientCfg.Brokers, cfg.Config)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "client init failed")
 	}
@@ -62,7 +75,7 @@ func NewConsumer(
 
 	msgHandler := messageHandler{
 		handler:               handler,
-		maxRetriesPerMsg:      cfg.maxRetriesPerMsg,
+		retryPolicy:           cfg.retryPolicy,
 		disablePayloadLogging: cfg.disablePayloadLogging,
 	}
 
@@ -109,9 +122,36 @@ func (c *Consumer) Consume(ctx context.Context) error {
 
 // ConsumerMessage encapsulates a Kafka message returned by the consumer.
 type ConsumerMessage struct {
-	ID      ConsumerMessageID
-	Value   []byte
-	Headers map[string]string
+	ID    ConsumerMessageID
+	Value []byte
+	// Headers are kept as they were produced, in order and with their repeated keys,
+	// so the copies published to the retry and dead letter topics carry them as is
+	Headers   []sarama.RecordHeader
+	Timestamp time.Time
+}
+
+// Header returns the value of the last header under key, empty when there is none
+func (m ConsumerMessage) Header(key string) string {
+	for i := len(m.Headers) - 1; i >= 0; i-- {
+		if string(m.Headers[i].Key) == key {
+			return string(m.Headers[i].Value)
+		}
+	}
+	return ""
+}
+
+// recordHeaders copies the headers of a consumed record
+func recordHeaders(headers []*sarama.RecordHeader) []sarama.RecordHeader {
+	if len(headers) == 0 {
+		return nil
+	}
+	res := make([]sarama.RecordHeader, 0, len(headers))
+	for _, h := range headers {
+		if h != nil {
+			res = append(res, *h)
+		}
+	}
+	return res
 }
 
 // ConsumerMessageID is the unique identifier of the message
@@ -122,10 +162,23 @@ type ConsumerMessageID struct {
 	Key       string
 }
 
+// HeaderMessageID carries the id a message got when it was first consumed, the copies of
+// the message published to the retry and dead letter topics keep it
+const HeaderMessageID = "x-message-id"
+
+// MessageID returns the id carried by the message, or its position when it has none which
+// is the same across redeliveries of the first copy
+func (m ConsumerMessage) MessageID() string {
+	if id := m.Header(HeaderMessageID); id != "" {
+		return id
+	}
+	return fmt.Sprintf("%s/%d/%d", m.ID.Topic, m.ID.Partition, m.ID.Offset)
+}
+
 type messageHandler struct {
 	handler               ConsumeHandler
 	disablePayloadLogging bool
-	maxRetriesPerMsg      int
+	retryPolicy           RetryPolicy
 }
 
 func (h messageHandler) Setup(s sarama.ConsumerGroupSession) error {
@@ -166,13 +219,9 @@ func (h messageHandler) consume(s sarama.ConsumerGroupSession, cm *sarama.Consum
 			Offset:    cm.Offset,
 			Key:       msgKey,
 		},
-		Value:   cm.Value,
-		Headers: make(map[string]string, len(cm.Headers)), // It's ok to possibly over provision in case of duplicate.
-	}
-	if cm.Headers != nil {
-		for _, r := range cm.Headers {
-			msg.Headers[string(r.Key[:])] = string(r.Value[:])
-		}
+		Value:     cm.Value,
+		Headers:   recordHeaders(cm.Headers),
+		Timestamp: cm.Timestamp,
 	}
 
 	var attempts int
@@ -186,7 +235,7 @@ func (h messageHandler) consume(s sarama.ConsumerGroupSession, cm *sarama.Consum
 			return err
 		}
 		return nil
-	}, backoff.WithContext(consumeBackoff(h.maxRetriesPerMsg), ctx)); err != nil {
+	}, backoff.WithContext(h.retryPolicy.backOff(), ctx)); err != nil {
 		log.Printf("[Kafka Consumer] Giving up on processing. Partition: [%d], Offset: [%d] after [%d] attempts. Will just commit and move on", cm.Partition, cm.Offset, attempts)
 	}
 
@@ -203,12 +252,10 @@ func (h messageHandler) commitMessageOffset(
 	cgs.MarkOffset(msgID.Topic, msgID.Partition, offsetToCommit, "")
 }
 
-func consumeBackoff(maxRetries int) backoff.BackOff {
+var deadLetterBackoff = func() backoff.BackOff {
 	b := backoff.NewExponentialBackOff()
-	b.InitialInterval = 5 * time.Second
+	b.InitialInterval = 500 * time.Millisecond
 	b.RandomizationFactor = 0
-	b.Multiplier = 1.25
-	b.MaxInterval = 30 * time.Minute
-	b.MaxElapsedTime = 12 * time.Hour
-	return backoff.WithMaxRetries(b, uint64(maxRetries))
+	b.MaxInterval = 10 * time.Second
+	return backoff.WithMaxRetries(b, 5)
 }
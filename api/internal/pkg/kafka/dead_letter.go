@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Headers added to every dead-lettered message on top of its original headers
+const (
+	HeaderDLQError           = "x-dlq-error"
+	HeaderDLQSourceTopic     = "x-dlq-source-topic"
+	HeaderDLQSourcePartition = "x-dlq-source-partition"
+	HeaderDLQSourceOffset    = "x-dlq-source-offset"
+	HeaderDLQAttempts        = "x-dlq-attempts"
+)
+
+// DeadLetterPublisher publishes messages that could not be processed so they can be inspected and replayed
+type DeadLetterPublisher interface {
+	PublishDeadLetter(ctx context.Context, msg ConsumerMessage, cause error, attempts int) error
+}
+
+// DeadLetterProducer publishes dead letters to a single topic through a SyncProducer
+type DeadLetterProducer struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewDeadLetterProducer creates a new DeadLetterProducer publishing to the given topic
+func NewDeadLetterProducer(producer *SyncProducer, topic string) (*DeadLetterProducer, error) {
+	if topic == "" {
+		return nil, errors.New("dead letter topic is empty")
+	}
+	return &DeadLetterProducer{producer: producer.producer, topic: topic}, nil
+}
+
+// PublishDeadLetter publishes the message with its original key, value and headers plus the dead letter headers
+// and HeaderMessageID
+func (p *DeadLetterProducer) PublishDeadLetter(ctx context.Context, msg ConsumerMessage, cause error, attempts int) error {
+	if _, _, err := p.producer.SendMessage(toDeadLetterMessage(p.topic, msg, cause, attempts)); err != nil {
+		return pkgerrors.Wrapf(err, "publishing dead letter %s[%d]@%d", msg.ID.Topic, msg.ID.Partition, msg.ID.Offset)
+	}
+	return nil
+}
+
+// toDeadLetterMessage builds the message without going through prepareProducerMessage,
+// as poison messages may have an empty key or payload which must be kept as is
+func toDeadLetterMessage(topic string, msg ConsumerMessage, cause error, attempts int) *sarama.ProducerMessage {
+	pm := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: make([]sarama.RecordHeader, 0, len(msg.Headers)+6),
+	}
+	if msg.ID.Key != "" {
+		pm.Key = sarama.StringEncoder(msg.ID.Key)
+	}
+
+	for _, h := range msg.Headers {
+		if IsDeadLetterHeader(string(h.Key)) {
+			// Replayed messages failing again get fresh dead letter headers
+			continue
+		}
+		pm.Headers = append(pm.Headers, h)
+	}
+	if msg.Header(HeaderMessageID) == "" {
+		// the copy gets a new position, the id of the first one is kept to dedupe it
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(HeaderMessageID), Value: []byte(msg.MessageID())})
+	}
+
+	var errMsg string
+	if cause != nil {
+		errMsg = cause.Error()
+	}
+	for _, h := range [][2]string{
+		{HeaderDLQError, errMsg},
+		{HeaderDLQSourceTopic, msg.ID.Topic},
+		{HeaderDLQSourcePartition, strconv.FormatInt(int64(msg.ID.Partition), 10)},
+		{HeaderDLQSourceOffset, strconv.FormatInt(msg.ID.Offset, 10)},
+		{HeaderDLQAttempts, strconv.Itoa(attempts)},
+	} {
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(h[0]), Value: []byte(h[1])})
+	}
+
+	return pm
+}
+
+// IsDeadLetterHeader tells if the header is one added by PublishDeadLetter
+func IsDeadLetterHeader(key string) bool {
+	switch key {
+	case HeaderDLQError, HeaderDLQSourceTopic, HeaderDLQSourcePartition, HeaderDLQSourceOffset, HeaderDLQAttempts:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDeadLetterMessage(t *testing.T) {
+	tcs := map[string]struct {
+		givenMsg   ConsumerMessage
+		givenCause error
+		expKey     sarama.Encoder
+		expHeaders []sarama.RecordHeader
+	}{
+		"keeps_key_value_and_headers": {
+			givenMsg: ConsumerMessage{
+				ID:      ConsumerMessageID{Topic: "iot.sensor", Partition: 3, Offset: 42, Key: "TEMP_001"},
+				Value:   []byte(`{"device_id":`),
+				Headers: headersOf("trace-id", "abc", "baggage", "a=1", "baggage", "b=2"),
+			},
+			givenCause: errors.New("unexpected end of JSON input"),
+			expKey:     sarama.StringEncoder("TEMP_001"),
+			expHeaders: headersOf(
+				"trace-id", "abc",
+				"baggage", "a=1",
+				"baggage", "b=2",
+				HeaderMessageID, "iot.sensor/3/42",
+				HeaderDLQError, "unexpected end of JSON input",
+				HeaderDLQSourceTopic, "iot.sensor",
+				HeaderDLQSourcePartition, "3",
+				HeaderDLQSourceOffset, "42",
+				HeaderDLQAttempts, "7",
+			),
+		},
+		"replayed_message_gets_fresh_headers_and_keeps_its_id": {
+			givenMsg: ConsumerMessage{
+				ID:      ConsumerMessageID{Topic: "iot.sensor", Partition: 0, Offset: 1},
+				Value:   []byte("x"),
+				Headers: headersOf(HeaderMessageID, "iot.sensor/1/7", HeaderDLQError, "old", HeaderDLQAttempts, "35"),
+			},
+			givenCause: errors.New("new"),
+			expHeaders: headersOf(
+				HeaderMessageID, "iot.sensor/1/7",
+				HeaderDLQError, "new",
+				HeaderDLQSourceTopic, "iot.sensor",
+				HeaderDLQSourcePartition, "0",
+				HeaderDLQSourceOffset, "1",
+				HeaderDLQAttempts, "7",
+			),
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			msg := tc.givenMsg
+
+			// When:
+			pm := toDeadLetterMessage("iot.sensor.dlq", msg, tc.givenCause, 7)
+
+			// Then: the original headers come first, as they were
+			require.Equal(t, "iot.sensor.dlq", pm.Topic)
+			require.Equal(t, tc.expKey, pm.Key)
+			require.Equal(t, sarama.ByteEncoder(msg.Value), pm.Value)
+			require.Equal(t, tc.expHeaders, pm.Headers)
+		})
+	}
+}
+
+// headersOf builds record headers from key value pairs
+func headersOf(kv ...string) []sarama.RecordHeader {
+	headers := make([]sarama.RecordHeader, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(kv[i]), Value: []byte(kv[i+1])})
+	}
+	return headers
+}
@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// PartitionLag is how far the group's committed offset of a partition is behind its high water mark
+type PartitionLag struct {
+	Topic           string
+	Partition       int32
+	HighWaterMark   int64
+	CommittedOffset int64 // -1 when the group never committed on the partition
+	Lag             int64
+}
+
+// LagSnapshot is the lag of every partition of the consumed topic at a point in time
+type LagSnapshot struct {
+	GroupID    string
+	UpdatedAt  time.Time
+	Partitions []PartitionLag
+}
+
+// Total sums the lag of all partitions
+func (s LagSnapshot) Total() int64 {
+	var total int64
+	for _, p := range s.Partitions {
+		total += p.Lag
+	}
+	return total
+}
+
+// LagTracker keeps the last lag computed by a BatchConsumer so it can be read from anywhere, e.g. an admin endpoint
+type LagTracker struct {
+	interval time.Duration
+	observe  func(PartitionLag)
+
+	mu       sync.RWMutex
+	snapshot LagSnapshot
+}
+
+// NewLagTracker creates a new LagTracker refreshed every interval, observe is called for every partition
+// on each refresh and can be nil
+func NewLagTracker(interval time.Duration, observe func(PartitionLag)) *LagTracker {
+	return &LagTracker{interval: interval, observe: observe}
+}
+
+// WithLagTracker computes the group's lag periodically while consuming and stores it in the tracker
+func WithLagTracker(tracker *LagTracker) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.lagTracker = tracker
+	}
+}
+
+// Snapshot returns the last computed lag, UpdatedAt is zero until the first one is computed
+func (t *LagTracker) Snapshot() LagSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.snapshot
+}
+
+// partition returns the last computed lag of a partition, false when there is none yet or no tracker
+func (t *LagTracker) partition(topic string, partition int32) (PartitionLag, bool) {
+	if t == nil {
+		return PartitionLag{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, p := range t.snapshot.Partitions {
+		if p.Topic == topic && p.Partition == partition {
+			return p, true
+		}
+	}
+	return PartitionLag{}, false
+}
+
+func (t *LagTracker) update(s LagSnapshot) {
+	t.mu.Lock()
+	t.snapshot = s
+	t.mu.Unlock()
+
+	if t.observe != nil {
+		for _, p := range s.Partitions {
+			t.observe(p)
+		}
+	}
+}
+
+// trackLag refreshes the tracker until ctx is done, failures are logged and retried on the next tick
+func (c *BatchConsumer) trackLag(ctx context.Context) {
+	ticker := time.NewTicker(c.lagTracker.interval)
+	defer ticker.Stop()
+
+	for {
+		s, err := c.computeLag()
+		if err != nil {
+			log.Printf("[Kafka BatchConsumer] Computing consumer lag failed: %v", err)
+		} else {
+			c.lagTracker.update(s)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// computeLag compares the high water marks of the topic with the offsets committed by the group
+func (c *BatchConsumer) computeLag() (LagSnapshot, error) {
+	partitions, err := c.client.Partitions(c.topic)
+	if err != nil {
+		return LagSnapshot{}, pkgerrors.Wrapf(err, "getting partitions of %s", c.topic)
+	}
+
+	coordinator, err := c.client.Coordinator(c.groupID)
+	if err != nil {
+		return LagSnapshot{}, pkgerrors.Wrapf(err, "getting coordinator of group %s", c.groupID)
+	}
+
+	req := &sarama.OffsetFetchRequest{Version: 1, ConsumerGroup: c.groupID}
+	for _, p := range partitions {
+		req.AddPartition(c.topic, p)
+	}
+	resp, err := coordinator.FetchOffset(req)
+	if err != nil {
+		return LagSnapshot{}, pkgerrors.Wrapf(err, "fetching committed offsets of group %s", c.groupID)
+	}
+
+	s := LagSnapshot{
+		GroupID:    c.groupID,
+		UpdatedAt:  time.Now(),
+		Partitions: make([]PartitionLag, 0, len(partitions)),
+	}
+	for _, p := range partitions {
+		committed := int64(-1)
+		if block := resp.GetBlock(c.topic, p); block != nil {
+			if block.Err != sarama.ErrNoError {
+				return LagSnapshot{}, pkgerrors.Wrapf(block.Err, "fetching committed offset of %s[%d]", c.topic, p)
+			}
+			committed = block.Offset
+		}
+
+		hwm, err := c.client.GetOffset(c.topic, p, sarama.OffsetNewest)
+		if err != nil {
+			return LagSnapshot{}, pkgerrors.Wrapf(err, "getting newest offset of %s[%d]", c.topic, p)
+		}
+
+		start := committed
+		if start < 0 {
+			// Nothing committed yet, the group starts from Consumer.Offsets.Initial
+			if start, err = c.client.GetOffset(c.topic, p, c.client.Config().Consumer.Offsets.Initial); err != nil {
+				return LagSnapshot{}, pkgerrors.Wrapf(err, "getting initial offset of %s[%d]", c.topic, p)
+			}
+		}
+
+		s.Partitions = append(s.Partitions, PartitionLag{
+			Topic:           c.topic,
+			Partition:       p,
+			HighWaterMark:   hwm,
+			CommittedOffset: committed,
+			Lag:             partitionLag(hwm, start),
+		})
+	}
+
+	return s, nil
+}
+
+// partitionLag is the number of messages between the next one to consume and the high water mark.
+// Committed offsets can briefly be ahead of a stale high water mark, that's no lag.
+func partitionLag(highWaterMark, next int64) int64 {
+	if next >= highWaterMark {
+		return 0
+	}
+	return highWaterMark - next
+}
@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionLag(t *testing.T) {
+	tcs := map[string]struct {
+		givenHighWaterMark int64
+		givenNext          int64
+		exp                int64
+	}{
+		"behind": {
+			givenHighWaterMark: 120,
+			givenNext:          100,
+			exp:                20,
+		},
+		"caught_up": {
+			givenHighWaterMark: 120,
+			givenNext:          120,
+			exp:                0,
+		},
+		"stale_high_water_mark": {
+			givenHighWaterMark: 120,
+			givenNext:          125,
+			exp:                0,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+
+			// When:
+			lag := partitionLag(tc.givenHighWaterMark, tc.givenNext)
+
+			// Then:
+			require.Equal(t, tc.exp, lag)
+		})
+	}
+}
+
+func TestLagTracker(t *testing.T) {
+	// Given:
+	var observed []PartitionLag
+	tracker := NewLagTracker(time.Second, func(l PartitionLag) {
+		observed = append(observed, l)
+	})
+	require.True(t, tracker.Snapshot().UpdatedAt.IsZero())
+
+	s := LagSnapshot{
+		GroupID:   "iot",
+		UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Partitions: []PartitionLag{
+			{Topic: "iot.sensor", Partition: 0, HighWaterMark: 10, CommittedOffset: 7, Lag: 3},
+			{Topic: "iot.sensor", Partition: 1, HighWaterMark: 5, CommittedOffset: -1, Lag: 5},
+		},
+	}
+
+	// When:
+	tracker.update(s)
+
+	// Then:
+	require.Equal(t, s, tracker.Snapshot())
+	require.Equal(t, int64(8), tracker.Snapshot().Total())
+	require.Equal(t, s.Partitions, observed)
+}
+
+func TestLifecycleCommittedLag(t *testing.T) {
+	tcs := map[string]struct {
+		givenPartition int32
+		expLag         float64
+	}{
+		"tracked_partition": {
+			givenPartition: 0,
+			expLag:         3,
+		},
+		"not_tracked_yet": {
+			givenPartition: 2,
+			expLag:         -1,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			tracker := NewLagTracker(time.Second, nil)
+			tracker.update(LagSnapshot{
+				GroupID:    "iot",
+				Partitions: []PartitionLag{{Topic: "iot.sensor", Partition: 0, HighWaterMark: 10, CommittedOffset: 7, Lag: 3}},
+			})
+			var buf bytes.Buffer
+			lifecycle := newConsumerLifecycle(&consumerConfig{
+				groupID:    "iot",
+				logger:     slog.New(slog.NewJSONHandler(&buf, nil)),
+				runID:      "run-1",
+				lagTracker: tracker,
+			})
+
+			// When:
+			lifecycle.committed("iot.sensor", tc.givenPartition, 8, 5*time.Millisecond)
+
+			// Then:
+			var event map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+			require.Equal(t, "offset_commit", event["msg"])
+			require.Equal(t, float64(8), event["offset"])
+			require.Equal(t, float64(5), event["duration_ms"])
+			require.Equal(t, tc.expLag, event["group_lag"])
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/telemetry"
+)
+
+// RebalanceEvent is a new generation of the consumer group, with the partitions this member claimed in it
+type RebalanceEvent struct {
+	GroupID      string
+	MemberID     string
+	GenerationID int32
+	Claims       map[string][]int32
+}
+
+// WithTelemetry logs the rebalances, the offset commits along with the lag of their partition when a LagTracker
+// is set, and the last committed offsets on shutdown as structured events of the run
+func WithTelemetry(logger *slog.Logger, runID string) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.logger = logger
+		cfg.runID = runID
+	}
+}
+
+// WithRebalanceObserver calls observe every time the group rebalanced, once the claims of this member are known
+func WithRebalanceObserver(observe func(RebalanceEvent)) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.onRebalance = observe
+	}
+}
+
+// consumerLifecycle follows the sessions of a batch consumer: the batches left by the claims of a session
+// to be flushed on its cleanup, while the member still owns the partitions, and the offsets committed so far.
+type consumerLifecycle struct {
+	groupID     string
+	logger      *slog.Logger
+	runID       string
+	onRebalance func(RebalanceEvent)
+	lagTracker  *LagTracker
+
+	mu            sync.Mutex
+	pending       []pendingBatch
+	lastCommitted map[string]int64 // next offset to consume by topic/partition
+}
+
+// pendingBatch is a batch collected by a claim worker but not processed before its session ended
+type pendingBatch struct {
+	topic     string
+	partition int32
+	tracker   *commitTracker
+	msgs      []ConsumerMessage
+}
+
+func newConsumerLifecycle(cfg *consumerConfig) *consumerLifecycle {
+	return &consumerLifecycle{
+		groupID:       cfg.groupID,
+		logger:        cfg.logger,
+		runID:         cfg.runID,
+		onRebalance:   cfg.onRebalance,
+		lagTracker:    cfg.lagTracker,
+		lastCommitted: make(map[string]int64),
+	}
+}
+
+// rebalanced reports the claims of a new session
+func (l *consumerLifecycle) rebalanced(sess sarama.ConsumerGroupSession) {
+	if l == nil {
+		return
+	}
+
+	claims := sess.Claims()
+	log.Printf("[Kafka Consumer] Member %s of generation %d claimed %v", sess.MemberID(), sess.GenerationID(), claims)
+	if l.logger != nil {
+		telemetry.LogRebalance(l.logger, l.runID, sess.MemberID(), claims)
+	}
+	if l.onRebalance != nil {
+		l.onRebalance(RebalanceEvent{
+			GroupID:      l.groupID,
+			MemberID:     sess.MemberID(),
+			GenerationID: sess.GenerationID(),
+			Claims:       claims,
+		})
+	}
+}
+
+// park keeps a batch for the cleanup of the session
+func (l *consumerLifecycle) park(b pendingBatch) {
+	if l == nil || len(b.msgs) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(l.pending, b)
+}
+
+// takePending hands over the parked batches, in the order they were parked
+func (l *consumerLifecycle) takePending() []pendingBatch {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending := l.pending
+	l.pending = nil
+	return pending
+}
+
+// committed records the offset committed for a partition and how long the commit took
+func (l *consumerLifecycle) committed(topic string, partition int32, offset int64, took time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.lastCommitted[fmt.Sprintf("%s/%d", topic, partition)] = offset
+	l.mu.Unlock()
+
+	if l.logger != nil {
+		// the lag is the one of the last refresh of the tracker, -1 until it computed one for the partition
+		lag := int64(-1)
+		if p, ok := l.lagTracker.partition(topic, partition); ok {
+			lag = p.Lag
+		}
+		telemetry.LogOffsetCommit(l.logger, l.runID, partition, offset, took.Milliseconds(), lag)
+	}
+}
+
+// shutdown logs the last committed offsets, ok tells if the consumer left the group cleanly
+func (l *consumerLifecycle) shutdown(ok bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	offsets := make(map[string]int64, len(l.lastCommitted))
+	for k, v := range l.lastCommitted {
+		offsets[k] = v
+	}
+	l.mu.Unlock()
+
+	log.Printf("[Kafka Consumer] Last committed offsets %v", offsets)
+	if l.logger != nil {
+		telemetry.LogShutdown(l.logger, l.runID, offsets, ok)
+	}
+}
+
+// flushPending processes the batches parked by the claims of the session, the partitions being still owned
+// until the cleanup returns. It is bounded by the batch timeout so the rebalance isn't held for long.
+func (h batchMessageHandler) flushPending(sess sarama.ConsumerGroupSession) error {
+	pending := h.lifecycle.takePending()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(sess.Context()), h.batchTimeout)
+	defer cancel()
+
+	log.Printf("[Kafka Consumer] Flushing %d in-flight batches before releasing the partitions", len(pending))
+	for _, b := range pending {
+		if err := h.processAndCommit(ctx, sess, b.topic, b.partition, b.tracker, b.msgs); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			log.Printf("[Kafka Consumer] Flush timed out, the remaining batches will be consumed again")
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"context"
+	"log"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// OffsetStore reads the offsets the handler stores next to the data it writes, in the same transaction.
+// Offsets are the ones of the next message to consume, like the ones committed to Kafka.
+type OffsetStore interface {
+	GetOffsets(ctx context.Context, groupID, topic string) (map[int32]int64, error)
+}
+
+// WithOffsetStore resumes the assigned partitions from the offsets of the store, it's then up to the
+// handler to save the offsets of every batch it processes. Offsets are still committed to Kafka as well.
+func WithOffsetStore(store OffsetStore) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.offsetStore = store
+	}
+}
+
+// restoreOffsets moves the claimed partitions to the stored offsets when they are ahead of the
+// committed ones, i.e. the data was written but the process stopped before committing to Kafka.
+// Committed offsets ahead of the stored ones are kept, those messages were dead lettered.
+func (h batchMessageHandler) restoreOffsets(sess sarama.ConsumerGroupSession) error {
+	if h.offsetStore == nil {
+		return nil
+	}
+
+	for topic, partitions := range sess.Claims() {
+		offsets, err := h.offsetStore.GetOffsets(sess.Context(), h.groupID, topic)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "getting stored offsets of %s", topic)
+		}
+
+		for _, p := range partitions {
+			offset, ok := offsets[p]
+			if !ok {
+				continue
+			}
+			// Only moves forward, see sarama's partitionOffsetManager.MarkOffset
+			sess.MarkOffset(topic, p, offset, "")
+			log.Printf("[Kafka Consumer] Stored offset of %s[%d] is %d, resuming from it unless the committed one is ahead", topic, p, offset)
+		}
+	}
+	sess.Commit()
+
+	return nil
+}
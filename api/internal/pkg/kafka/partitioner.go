@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Partitioner strategies of a producer
+const (
+	// PartitionerHash hashes the message key, the default
+	PartitionerHash = "hash"
+	// PartitionerConsistentHash jump hashes the message's PartitionKey, e.g. the zone of a device, so
+	// adding partitions only moves the keys which must land on the new ones
+	PartitionerConsistentHash = "consistent"
+	// PartitionerExplicit sends the listed keys to their assigned partition and hashes the others
+	PartitionerExplicit = "explicit"
+)
+
+// WithPartitioner picks the partition of the messages using the given strategy, assignments being only used by
+// PartitionerExplicit
+func WithPartitioner(strategy string, assignments map[string]int32) ProducerOption {
+	return func(cfg *producerConfig) {
+		switch strategy {
+		case PartitionerConsistentHash:
+			cfg.Producer.Partitioner = newConsistentHashPartitioner
+		case PartitionerExplicit:
+			cfg.Producer.Partitioner = func(topic string) sarama.Partitioner {
+				return explicitPartitioner{assignments: assignments, fallback: sarama.NewHashPartitioner(topic)}
+			}
+		default:
+			cfg.Producer.Partitioner = sarama.NewHashPartitioner
+		}
+	}
+}
+
+// ParsePartitionAssignments parses a comma separated list of key=partition, e.g. "TEMP_001=0,HUM_001=1"
+func ParsePartitionAssignments(s string) (map[string]int32, error) {
+	assignments := make(map[string]int32)
+	if s == "" {
+		return assignments, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		key, partition, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid partition assignment %q, expected key=partition", item)
+		}
+		p, err := strconv.ParseInt(partition, 10, 32)
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("invalid partition of assignment %q", item)
+		}
+		assignments[key] = int32(p)
+	}
+	return assignments, nil
+}
+
+// messageMetadata travels with the sarama message to the partitioner
+type messageMetadata struct {
+	partitionKey string
+}
+
+// consistentHashPartitioner jump hashes the partition key of the message, falling back on its key
+type consistentHashPartitioner struct{}
+
+func newConsistentHashPartitioner(_ string) sarama.Partitioner {
+	return consistentHashPartitioner{}
+}
+
+func (consistentHashPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	key, err := partitionKey(msg)
+	if err != nil {
+		return -1, err
+	}
+	h := fnv.New64a()
+	h.Write(key)
+	return jumpHash(h.Sum64(), numPartitions), nil
+}
+
+func (consistentHashPartitioner) RequiresConsistency() bool {
+	return true
+}
+
+// explicitPartitioner sends the assigned keys to their partition
+type explicitPartitioner struct {
+	assignments map[string]int32
+	fallback    sarama.Partitioner
+}
+
+func (p explicitPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if msg.Key != nil {
+		key, err := msg.Key.Encode()
+		if err != nil {
+			return -1, err
+		}
+		if partition, ok := p.assignments[string(key)]; ok {
+			if partition >= numPartitions {
+				return -1, fmt.Errorf("key %s is assigned to partition %d out of the %d of %s", key, partition, numPartitions, msg.Topic)
+			}
+			return partition, nil
+		}
+	}
+	return p.fallback.Partition(msg, numPartitions)
+}
+
+func (p explicitPartitioner) RequiresConsistency() bool {
+	return true
+}
+
+func partitionKey(msg *sarama.ProducerMessage) ([]byte, error) {
+	if md, ok := msg.Metadata.(messageMetadata); ok && md.partitionKey != "" {
+		return []byte(md.partitionKey), nil
+	}
+	if msg.Key == nil {
+		return nil, nil
+	}
+	key, err := msg.Key.Encode()
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "encoding message key")
+	}
+	return key, nil
+}
+
+// jumpHash is Lamping and Veach's jump consistent hash, https://arxiv.org/abs/1406.2294
+func jumpHash(key uint64, buckets int32) int32 {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int32(b)
+}
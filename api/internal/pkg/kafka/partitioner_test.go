@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitioners(t *testing.T) {
+	partitionOf := func(p sarama.Partitioner, opt ProducerMessageOption) int32 {
+		pm, err := prepareProducerMessage("iot.sensor", []byte("{}"), opt)
+		require.NoError(t, err)
+		partition, err := p.Partition(pm, 6)
+		require.NoError(t, err)
+		return partition
+	}
+
+	tcs := map[string]struct {
+		givenPartitioner sarama.PartitionerConstructor
+		givenOpts        []ProducerMessageOption
+		expSamePartition bool
+	}{
+		"hash_same_device": {
+			givenPartitioner: sarama.NewHashPartitioner,
+			givenOpts:        []ProducerMessageOption{{Key: "TEMP_001"}, {Key: "TEMP_001", PartitionKey: "1/1"}},
+			expSamePartition: true,
+		},
+		"consistent_same_zone": {
+			givenPartitioner: newConsistentHashPartitioner,
+			givenOpts:        []ProducerMessageOption{{Key: "TEMP_001", PartitionKey: "1/2"}, {Key: "HUM_001", PartitionKey: "1/2"}},
+			expSamePartition: true,
+		},
+		"consistent_falls_back_on_key": {
+			givenPartitioner: newConsistentHashPartitioner,
+			givenOpts:        []ProducerMessageOption{{Key: "TEMP_001"}, {Key: "TEMP_001"}},
+			expSamePartition: true,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			p := tc.givenPartitioner("iot.sensor")
+
+			// When:
+			first := partitionOf(p, tc.givenOpts[0])
+			second := partitionOf(p, tc.givenOpts[1])
+
+			// Then:
+			require.Equal(t, tc.expSamePartition, first == second)
+		})
+	}
+}
+
+func TestExplicitPartitioner(t *testing.T) {
+	// Given:
+	var cfg producerConfig
+	cfg.Config = sarama.NewConfig()
+	WithPartitioner(PartitionerExplicit, map[string]int32{"TEMP_001": 4, "HUM_001": 9})(&cfg)
+	p := cfg.Producer.Partitioner("iot.sensor")
+
+	// When:
+	assigned, err := p.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("TEMP_001")}, 6)
+	// Then:
+	require.NoError(t, err)
+	require.Equal(t, int32(4), assigned)
+
+	// When: hashed when not assigned
+	hashed, err := p.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("CO2_001")}, 6)
+	// Then:
+	require.NoError(t, err)
+	expHashed, err := sarama.NewHashPartitioner("iot.sensor").Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("CO2_001")}, 6)
+	require.NoError(t, err)
+	require.Equal(t, expHashed, hashed)
+
+	// When: assigned to a partition the topic doesn't have
+	_, err = p.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("HUM_001")}, 6)
+	// Then:
+	require.Error(t, err)
+}
+
+func TestJumpHash_MinimalMoves(t *testing.T) {
+	// Given: 1000 zones over 6 partitions
+	var moved int
+	for i := 0; i < 1000; i++ {
+		key := uint64(i) * 0x9E3779B97F4A7C15
+
+		// When: a partition is added
+		before, after := jumpHash(key, 6), jumpHash(key, 7)
+
+		// Then: keys only move to the new partition
+		require.Less(t, before, int32(6), fmt.Sprint(i))
+		if before != after {
+			require.Equal(t, int32(6), after)
+			moved++
+		}
+	}
+	// about 1/7 of them
+	require.InDelta(t, 1000/7, moved, 50)
+}
@@ -16,16 +16,22 @@ type SyncProducer struct {
 	producer sarama.SyncProducer
 }
 
-// NewSyncProducer creates a newsync producer using the given broker addresses and configuration.
+// NewSyncProducer creates a newsync producer using the given client configuration.
 func NewSyncProducer(ctx context.Context,
-	broker string) (*SyncProducer, error) {
+	clientCfg ClientConfig, opts ...ProducerOption) (*SyncProducer, error) {
 	log.Println("Initializing Kafka SyncProducer")
 
-	baseCfg := sarama.NewConfig()
+	baseCfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
 	cfg := &producerConfig{baseCfg}
 	cfg.Producer.Return.Successes = true // Mandatory forsync producer
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
-	client, err := sarama.NewClient([]string{broker}, cfg.Config)
+	client, err := sarama.NewClient(clientCfg.Brokers, cfg.Config)
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, "client init failed")
 	}
@@ -83,9 +89,11 @@ type ProducerOption func(*producerConfig)
 
 // ProducerMessageOption specifies options for the message
 type ProducerMessageOption struct {
-	Key                   string
+	Key                   string // a random one is generated when empty
+	PartitionKey          string // hashed instead of Key by PartitionerConsistentHash
 	Partition             *int32 // Cannot use int because of forced conversion
 	Headers               map[string]string
+	RecordHeaders         []sarama.RecordHeader // sent as is after Headers, e.g. the headers of a consumed message
 	DisablePayloadLogging bool
 }
 
@@ -108,12 +116,15 @@ func prepareProducerMessage(topic string, payload []byte, opt ProducerMessageOpt
 	}
 
 	pm := &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(payload), Key: sarama.StringEncoder(opt.Key)}
+	if opt.PartitionKey != "" {
+		pm.Metadata = messageMetadata{partitionKey: opt.PartitionKey}
+	}
 
 	if opt.Partition != nil {
 		pm.Partition = *opt.Partition
 	}
 
-	if l := len(opt.Headers); l > 0 {
+	if l := len(opt.Headers) + len(opt.RecordHeaders); l > 0 {
 		pm.Headers = make([]sarama.RecordHeader, 0, l)
 		for k, v := range opt.Headers {
 			pm.Headers = append(pm.Headers, sarama.RecordHeader{
@@ -121,6 +132,7 @@ func prepareProducerMessage(topic string, payload []byte, opt ProducerMessageOpt
 				Value: []byte(v),
 			})
 		}
+		pm.Headers = append(pm.Headers, opt.RecordHeaders...)
 	}
 
 	return pm, nil
@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/cenkalti/backoff/v4"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Headers added to every message sent to a retry topic on top of its original headers
+const (
+	HeaderRetryTopic     = "x-retry-topic"
+	HeaderRetryAttempt   = "x-retry-attempt"
+	HeaderRetryNotBefore = "x-retry-not-before"
+	HeaderRetryError     = "x-retry-error"
+)
+
+// RetryTier is a retry topic whose messages are fed back to their topic once Delay elapsed
+type RetryTier struct {
+	Topic string
+	Delay time.Duration
+}
+
+// RetryPolicy is how failing batches are retried.
+// A batch is first retried in place InPlaceRetries times with an exponential backoff, the messages still failing
+// alone then go through the retry tiers, one per failed delivery and the last one repeating, until they were
+// delivered MaxAttempts times. They are dead lettered after that, or right away without tiers.
+// The bisection isolating the failing messages makes at most MaxBisectAttempts attempts, 0 for no limit, the messages
+// it didn't get to by then are handled as failing alone. A batch failing with ErrBatchFailed isn't bisected at all when
+// there are tiers, all its messages go to their next tier.
+type RetryPolicy struct {
+	InPlaceRetries    int
+	InitialInterval   time.Duration
+	MaxInterval       time.Duration
+	Multiplier        float64
+	MaxElapsedTime    time.Duration
+	MaxAttempts       int
+	MaxBisectAttempts int
+	Tiers             []RetryTier
+}
+
+// ErrBatchFailed is wrapped by the error of a batch handler failing for none of the messages in particular,
+// e.g. the database being unreachable, bisecting the batch would only fail again message by message
+var ErrBatchFailed = errors.New("batch failed")
+
+// DefaultRetryPolicy only retries in place, 35 times which evaluates to around 13hrs
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InPlaceRetries:    35,
+		InitialInterval:   5 * time.Second,
+		MaxInterval:       30 * time.Minute,
+		Multiplier:        1.25,
+		MaxElapsedTime:    12 * time.Hour,
+		MaxAttempts:       1,
+		MaxBisectAttempts: 32,
+	}
+}
+
+// RetryPublisher publishes a failed message to a retry topic
+type RetryPublisher interface {
+	PublishRetry(ctx context.Context, msg ConsumerMessage, cause error, tier RetryTier, attempt int) error
+}
+
+// WithRetryPolicy replaces the default retry policy, the publisher is required when the policy has tiers
+func WithRetryPolicy(policy RetryPolicy, publisher RetryPublisher) ConsumerOption {
+	return func(cfg *consumerConfig) {
+		cfg.retryPolicy = policy
+		cfg.retryPublisher = publisher
+	}
+}
+
+// backOff is the in place backoff of a batch
+func (p RetryPolicy) backOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.RandomizationFactor = 0
+	b.Multiplier = p.Multiplier
+	b.MaxInterval = p.MaxInterval
+	b.MaxElapsedTime = p.MaxElapsedTime
+	return backoff.WithMaxRetries(b, uint64(p.InPlaceRetries))
+}
+
+// nextTier returns the tier a message failing again goes to along with its attempt number,
+// and false when it must be dead lettered
+func (p RetryPolicy) nextTier(msg ConsumerMessage) (RetryTier, int, bool) {
+	if len(p.Tiers) == 0 {
+		return RetryTier{}, 0, false
+	}
+	// previous deliveries through the retry topics, 0 for the first delivery
+	attempt, _ := strconv.Atoi(msg.Header(HeaderRetryAttempt))
+	if attempt+1 >= p.MaxAttempts {
+		return RetryTier{}, 0, false
+	}
+	return p.Tiers[min(attempt, len(p.Tiers)-1)], attempt + 1, true
+}
+
+// ParseRetryTiers parses a comma separated list of topic=delay, e.g. "iot.retry.1m=1m,iot.retry.10m=10m"
+func ParseRetryTiers(s string) ([]RetryTier, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var tiers []RetryTier
+	for _, item := range strings.Split(s, ",") {
+		topic, delay, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || topic == "" {
+			return nil, fmt.Errorf("invalid retry tier %q, expected topic=delay", item)
+		}
+		d, err := time.ParseDuration(delay)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid delay of retry tier %q", item)
+		}
+		tiers = append(tiers, RetryTier{Topic: topic, Delay: d})
+	}
+	return tiers, nil
+}
+
+// RetryProducer publishes retries through a SyncProducer
+type RetryProducer struct {
+	producer sarama.SyncProducer
+}
+
+// NewRetryProducer creates a new RetryProducer
+func NewRetryProducer(producer *SyncProducer) *RetryProducer {
+	return &RetryProducer{producer: producer.producer}
+}
+
+// PublishRetry publishes the message to the tier's topic, to be fed back to its topic after the tier's delay
+func (p *RetryProducer) PublishRetry(ctx context.Context, msg ConsumerMessage, cause error, tier RetryTier, attempt int) error {
+	if _, _, err := p.producer.SendMessage(toRetryMessage(msg, cause, tier, attempt, time.Now())); err != nil {
+		return pkgerrors.Wrapf(err, "publishing retry %s[%d]@%d to %s", msg.ID.Topic, msg.ID.Partition, msg.ID.Offset, tier.Topic)
+	}
+	return nil
+}
+
+// toRetryMessage keeps the key, value and headers as is like toDeadLetterMessage
+func toRetryMessage(msg ConsumerMessage, cause error, tier RetryTier, attempt int, now time.Time) *sarama.ProducerMessage {
+	pm := &sarama.ProducerMessage{
+		Topic:   tier.Topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: make([]sarama.RecordHeader, 0, len(msg.Headers)+5),
+	}
+	if msg.ID.Key != "" {
+		pm.Key = sarama.StringEncoder(msg.ID.Key)
+	}
+
+	for _, h := range msg.Headers {
+		if IsRetryHeader(string(h.Key)) {
+			continue
+		}
+		pm.Headers = append(pm.Headers, h)
+	}
+	if msg.Header(HeaderMessageID) == "" {
+		// the copy gets a new position, the id of the first one is kept to dedupe it
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(HeaderMessageID), Value: []byte(msg.MessageID())})
+	}
+
+	var errMsg string
+	if cause != nil {
+		errMsg = cause.Error()
+	}
+	for _, h := range [][2]string{
+		{HeaderRetryTopic, msg.ID.Topic},
+		{HeaderRetryAttempt, strconv.Itoa(attempt)},
+		{HeaderRetryNotBefore, strconv.FormatInt(now.Add(tier.Delay).UnixMilli(), 10)},
+		{HeaderRetryError, errMsg},
+	} {
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(h[0]), Value: []byte(h[1])})
+	}
+
+	return pm
+}
+
+// IsRetryHeader tells if the header is one added by PublishRetry
+func IsRetryHeader(key string) bool {
+	switch key {
+	case HeaderRetryTopic, HeaderRetryAttempt, HeaderRetryNotBefore, HeaderRetryError:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryNotBefore reads the time a retried message can be fed back at
+func retryNotBefore(msg ConsumerMessage) (time.Time, error) {
+	v := msg.Header(HeaderRetryNotBefore)
+	if v == "" {
+		return time.Time{}, errors.New("missing " + HeaderRetryNotBefore + " header")
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, pkgerrors.Wrapf(err, "parsing %s header", HeaderRetryNotBefore)
+	}
+	return time.UnixMilli(ms), nil
+}
@@ -0,0 +1,174 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/cenkalti/backoff/v4"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// RetryConsumer feeds the messages of the retry topics back to their topic once their delay elapsed.
+// Every message of a retry topic has the same delay, so waiting on a message never delays the ones behind it.
+type RetryConsumer struct {
+	client   sarama.Client
+	consumer sarama.ConsumerGroup
+	topics   []string
+	handler  retryMessageHandler
+}
+
+// NewRetryConsumer creates a new consumer of the retry tiers' topics
+func NewRetryConsumer(
+	ctx context.Context,
+	clientCfg ClientConfig,
+	groupID string,
+	tiers []RetryTier,
+	producer *SyncProducer,
+) (*RetryConsumer, error) {
+	if len(tiers) == 0 {
+		return nil, errors.New("no retry tier")
+	}
+
+	topics := make([]string, len(tiers))
+	for i, t := range tiers {
+		topics[i] = t.Topic
+	}
+
+	cfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(clientCfg.Brokers, cfg)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "client init failed")
+	}
+
+	cg, err := sarama.NewConsumerGroupFromClient(groupID, client)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "creating new consumer group")
+	}
+
+	return &RetryConsumer{
+		client:   client,
+		consumer: cg,
+		topics:   topics,
+		handler:  retryMessageHandler{producer: producer.producer},
+	}, nil
+}
+
+// Consume starts feeding the retried messages back.
+func (c *RetryConsumer) Consume(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := c.consumer.Consume(ctx, c.topics, c.handler); err != nil {
+				errCh <- pkgerrors.WithStack(fmt.Errorf("consuming retries failed: %w", err))
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		log.Println("[Kafka RetryConsumer] shutting down")
+		c.consumer.Close()
+		c.client.Close()
+		return nil
+	}
+}
+
+// retryMessageHandler implements sarama.ConsumerGroupHandler
+type retryMessageHandler struct {
+	producer sarama.SyncProducer
+}
+
+func (h retryMessageHandler) Setup(_ sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h retryMessageHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h retryMessageHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case cm, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			msg := toConsumerMessage(cm)
+			if !h.waitDue(sess.Context(), msg) {
+				// not marked, the next owner of the claim waits for it
+				return nil
+			}
+			if err := h.feedBack(sess.Context(), msg); err != nil {
+				return err
+			}
+			sess.MarkOffset(msg.ID.Topic, msg.ID.Partition, msg.ID.Offset+1, "")
+			sess.Commit()
+		case <-sess.Context().Done():
+			return nil
+		}
+	}
+}
+
+// waitDue waits for the delay of the message to elapse, false means ctx ended first
+func (h retryMessageHandler) waitDue(ctx context.Context, msg ConsumerMessage) bool {
+	notBefore, err := retryNotBefore(msg)
+	if err != nil {
+		log.Printf("[Kafka RetryConsumer] Feeding %s[%d]@%d back right away: %v", msg.ID.Topic, msg.ID.Partition, msg.ID.Offset, err)
+		return true
+	}
+
+	wait := time.Until(notBefore)
+	if wait <= 0 {
+		return true
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// feedBack publishes the message to its original topic with its key and headers, the retry headers
+// included so the next failure goes to the next tier
+func (h retryMessageHandler) feedBack(ctx context.Context, msg ConsumerMessage) error {
+	topic := msg.Header(HeaderRetryTopic)
+	if topic == "" {
+		log.Printf("[Kafka RetryConsumer] Dropping %s[%d]@%d, missing %s header", msg.ID.Topic, msg.ID.Partition, msg.ID.Offset, HeaderRetryTopic)
+		return nil
+	}
+
+	pm := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: msg.Headers,
+	}
+	if msg.ID.Key != "" {
+		pm.Key = sarama.StringEncoder(msg.ID.Key)
+	}
+
+	err := backoff.Retry(func() error {
+		_, _, err := h.producer.SendMessage(pm)
+		return err
+	}, backoff.WithContext(deadLetterBackoff(), ctx))
+	if err != nil {
+		return pkgerrors.Wrapf(err, "feeding %s[%d]@%d back to %s", msg.ID.Topic, msg.ID.Partition, msg.ID.Offset, topic)
+	}
+	return nil
+}
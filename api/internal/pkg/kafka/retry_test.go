@@ -0,0 +1,308 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_NextTier(t *testing.T) {
+	tiers := []RetryTier{{Topic: "iot.retry.1m", Delay: time.Minute}, {Topic: "iot.retry.10m", Delay: 10 * time.Minute}}
+	tcs := map[string]struct {
+		givenPolicy  RetryPolicy
+		givenHeaders []sarama.RecordHeader
+		expTier      RetryTier
+		expAttempt   int
+		expOK        bool
+	}{
+		"no_tiers": {
+			givenPolicy: DefaultRetryPolicy(),
+		},
+		"first_failure": {
+			givenPolicy: RetryPolicy{MaxAttempts: 4, Tiers: tiers},
+			expTier:     tiers[0],
+			expAttempt:  1,
+			expOK:       true,
+		},
+		"second_failure": {
+			givenPolicy:  RetryPolicy{MaxAttempts: 4, Tiers: tiers},
+			givenHeaders: headersOf(HeaderRetryAttempt, "1"),
+			expTier:      tiers[1],
+			expAttempt:   2,
+			expOK:        true,
+		},
+		"last_tier_repeats": {
+			givenPolicy:  RetryPolicy{MaxAttempts: 4, Tiers: tiers},
+			givenHeaders: headersOf(HeaderRetryAttempt, "2"),
+			expTier:      tiers[1],
+			expAttempt:   3,
+			expOK:        true,
+		},
+		"out_of_attempts": {
+			givenPolicy:  RetryPolicy{MaxAttempts: 4, Tiers: tiers},
+			givenHeaders: headersOf(HeaderRetryAttempt, "3"),
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			msg := ConsumerMessage{Headers: tc.givenHeaders}
+
+			// When:
+			tier, attempt, ok := tc.givenPolicy.nextTier(msg)
+
+			// Then:
+			require.Equal(t, tc.expOK, ok)
+			require.Equal(t, tc.expTier, tier)
+			require.Equal(t, tc.expAttempt, attempt)
+		})
+	}
+}
+
+func TestParseRetryTiers(t *testing.T) {
+	tcs := map[string]struct {
+		given  string
+		exp    []RetryTier
+		expErr bool
+	}{
+		"empty": {},
+		"tiers": {
+			given: "iot.retry.1m=1m, iot.retry.10m=10m",
+			exp:   []RetryTier{{Topic: "iot.retry.1m", Delay: time.Minute}, {Topic: "iot.retry.10m", Delay: 10 * time.Minute}},
+		},
+		"missing_delay": {
+			given:  "iot.retry.1m",
+			expErr: true,
+		},
+		"invalid_delay": {
+			given:  "iot.retry.1m=soon",
+			expErr: true,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			tiers, err := ParseRetryTiers(tc.given)
+
+			// Then:
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, tiers)
+		})
+	}
+}
+
+func TestToRetryMessage(t *testing.T) {
+	// Given: a message already retried once, fed back from the retry topic at offset 9
+	now := time.UnixMilli(1700000000000)
+	msg := ConsumerMessage{
+		ID:    ConsumerMessageID{Topic: "iot.sensor", Partition: 2, Offset: 9, Key: "TEMP_001"},
+		Value: []byte(`{}`),
+		Headers: headersOf("trace-id", "abc", HeaderMessageID, "iot.sensor/2/4",
+			HeaderRetryAttempt, "1", HeaderRetryError, "old"),
+	}
+
+	// When:
+	pm := toRetryMessage(msg, errors.New("db down"), RetryTier{Topic: "iot.retry.10m", Delay: 10 * time.Minute}, 2, now)
+
+	// Then: the original headers keep their order, the retry ones are replaced
+	require.Equal(t, "iot.retry.10m", pm.Topic)
+	require.Equal(t, headersOf(
+		"trace-id", "abc",
+		HeaderMessageID, "iot.sensor/2/4",
+		HeaderRetryTopic, "iot.sensor",
+		HeaderRetryAttempt, "2",
+		HeaderRetryNotBefore, "1700000600000",
+		HeaderRetryError, "db down",
+	), pm.Headers)
+
+	notBefore, err := retryNotBefore(ConsumerMessage{Headers: pm.Headers})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(10*time.Minute), notBefore)
+}
+
+func TestProcessWithBisection_RetryTiers(t *testing.T) {
+	msgs := []ConsumerMessage{
+		{ID: ConsumerMessageID{Offset: 0}},
+		{ID: ConsumerMessageID{Offset: 1}, Headers: headersOf(HeaderRetryAttempt, "1")},
+	}
+	errDB := errors.New("db down")
+
+	// Given: everything fails, the second message already went through the only tier
+	origBackoff := deadLetterBackoff
+	deadLetterBackoff = func() backoff.BackOff { return &backoff.StopBackOff{} }
+	defer func() { deadLetterBackoff = origBackoff }()
+	retries := &fakeRetry{}
+	dlq := &fakeDeadLetter{}
+	h := batchMessageHandler{
+		handler:        func(context.Context, []ConsumerMessage) error { return errDB },
+		retryPolicy:    RetryPolicy{MaxAttempts: 2, Tiers: []RetryTier{{Topic: "iot.retry.1m", Delay: time.Minute}}},
+		retryPublisher: retries,
+		deadLetter:     dlq,
+	}
+
+	// When:
+	succeeded, dropped, err := h.processWithBisection(context.Background(), msgs, 1, errDB)
+
+	// Then:
+	require.NoError(t, err)
+	require.Empty(t, succeeded)
+	require.Equal(t, msgs, dropped)
+	require.Equal(t, []ConsumerMessage{msgs[0]}, retries.published)
+	require.Equal(t, []droppedMessage{{msg: msgs[1], err: errDB, attempts: 3}}, dlq.published)
+}
+
+type fakeRetry struct {
+	published []ConsumerMessage
+}
+
+func (f *fakeRetry) PublishRetry(_ context.Context, msg ConsumerMessage, _ error, _ RetryTier, _ int) error {
+	f.published = append(f.published, msg)
+	return nil
+}
+
+func TestProcessAndCommit_RetryInPlace(t *testing.T) {
+	msgs := []ConsumerMessage{
+		{ID: ConsumerMessageID{Topic: "iot.sensor", Partition: 1, Offset: 0}},
+		{ID: ConsumerMessageID{Topic: "iot.sensor", Partition: 1, Offset: 1}},
+	}
+	tracker := newCommitTracker()
+	for _, m := range msgs {
+		tracker.add(m.ID.Offset)
+	}
+
+	// Given: the handler fails twice, the backoff is longer than the batch timeout
+	var batches [][]ConsumerMessage
+	h := batchMessageHandler{
+		batchTimeout: 20 * time.Millisecond,
+		retryPolicy: RetryPolicy{
+			InPlaceRetries:  3,
+			InitialInterval: 30 * time.Millisecond,
+			MaxInterval:     30 * time.Millisecond,
+			Multiplier:      1,
+			MaxElapsedTime:  time.Minute,
+		},
+		handler: func(_ context.Context, b []ConsumerMessage) error {
+			batches = append(batches, b)
+			if len(batches) < 3 {
+				return errors.New("db down")
+			}
+			return nil
+		},
+	}
+	sess := &fakeSession{ctx: context.Background()}
+
+	// When:
+	err := h.processAndCommit(context.Background(), sess, "iot.sensor", 1, tracker, msgs)
+
+	// Then: the third attempt of the whole batch succeeds, nothing is bisected
+	require.NoError(t, err)
+	require.Equal(t, [][]ConsumerMessage{msgs, msgs, msgs}, batches)
+	require.Equal(t, []int64{2}, sess.markedOffsets())
+}
+
+func TestProcessWithBisection_MaxBisectAttempts(t *testing.T) {
+	msgs := make([]ConsumerMessage, 4)
+	for i := range msgs {
+		msgs[i] = ConsumerMessage{ID: ConsumerMessageID{Offset: int64(i)}}
+	}
+	errDB := errors.New("db down")
+
+	tcs := map[string]struct {
+		givenMaxBisectAttempts int
+		expCalls               int
+		expAttempts            []int
+	}{
+		"no_limit": {
+			expCalls:    7,
+			expAttempts: []int{4, 4, 4, 4},
+		},
+		"first_split_only": {
+			givenMaxBisectAttempts: 1,
+			expCalls:               1,
+			expAttempts:            []int{2, 2, 2, 2},
+		},
+		"first_message_isolated": {
+			givenMaxBisectAttempts: 3,
+			expCalls:               3,
+			expAttempts:            []int{4, 3, 2, 2},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given: everything fails, there are no tiers
+			origBackoff := deadLetterBackoff
+			deadLetterBackoff = func() backoff.BackOff { return &backoff.StopBackOff{} }
+			defer func() { deadLetterBackoff = origBackoff }()
+			var calls int
+			dlq := &fakeDeadLetter{}
+			h := batchMessageHandler{
+				handler: func(context.Context, []ConsumerMessage) error {
+					calls++
+					return errDB
+				},
+				retryPolicy: RetryPolicy{MaxBisectAttempts: tc.givenMaxBisectAttempts},
+				deadLetter:  dlq,
+			}
+
+			// When:
+			succeeded, dropped, err := h.processWithBisection(context.Background(), msgs, 1, errDB)
+
+			// Then: the messages left once the attempts are spent are dead lettered in offset order
+			require.NoError(t, err)
+			require.Empty(t, succeeded)
+			require.Equal(t, msgs, dropped)
+			require.Equal(t, tc.expCalls, calls)
+			attempts := make([]int, len(dlq.published))
+			for i, d := range dlq.published {
+				require.Equal(t, msgs[i], d.msg)
+				attempts[i] = d.attempts
+			}
+			require.Equal(t, tc.expAttempts, attempts)
+		})
+	}
+}
+
+func TestProcessAndCommit_BatchFailed(t *testing.T) {
+	msgs := make([]ConsumerMessage, 3)
+	tracker := newCommitTracker()
+	for i := range msgs {
+		msgs[i] = ConsumerMessage{ID: ConsumerMessageID{Topic: "iot.sensor", Partition: 1, Offset: int64(i)}}
+		tracker.add(int64(i))
+	}
+
+	// Given: the database is unreachable
+	origBackoff := deadLetterBackoff
+	deadLetterBackoff = func() backoff.BackOff { return &backoff.StopBackOff{} }
+	defer func() { deadLetterBackoff = origBackoff }()
+	var calls int
+	retries := &fakeRetry{}
+	h := batchMessageHandler{
+		batchTimeout: time.Minute,
+		handler: func(context.Context, []ConsumerMessage) error {
+			calls++
+			return fmt.Errorf("%w: db down", ErrBatchFailed)
+		},
+		retryPolicy:    RetryPolicy{MaxAttempts: 2, Tiers: []RetryTier{{Topic: "iot.retry.1m", Delay: time.Minute}}},
+		retryPublisher: retries,
+	}
+	sess := &fakeSession{ctx: context.Background()}
+
+	// When:
+	err := h.processAndCommit(context.Background(), sess, "iot.sensor", 1, tracker, msgs)
+
+	// Then: the batch goes to the retry topic without being bisected
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, msgs, retries.published)
+	require.Equal(t, []int64{3}, sess.markedOffsets())
+}
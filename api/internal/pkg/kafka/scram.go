@@ -0,0 +1,36 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"github.com/xdg-go/scram"
+)
+
+var (
+	scramSHA256 scram.HashGeneratorFcn = sha256.New
+	scramSHA512 scram.HashGeneratorFcn = sha512.New
+)
+
+// scramClient implements sarama.SCRAMClient
+type scramClient struct {
+	hashGen      scram.HashGeneratorFcn
+	conversation *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGen.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
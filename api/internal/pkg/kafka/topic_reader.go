@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// topicReaderIdleTimeout stops reading a partition which has no more deliverable messages
+// below its high watermark, e.g. when the last offsets are transaction markers
+const topicReaderIdleTimeout = 5 * time.Second
+
+// TopicReader reads a whole topic outside of any consumer group, so nothing is committed.
+// It is meant for inspecting topics such as the dead letter one.
+type TopicReader struct {
+	client   sarama.Client
+	consumer sarama.Consumer
+	topic    string
+}
+
+// NewTopicReader creates a new TopicReader for the given topic
+func NewTopicReader(ctx context.Context, topic string, clientCfg ClientConfig) (*TopicReader, error) {
+	if topic == "" {
+		return nil, errors.New("topic is empty")
+	}
+
+	cfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(clientCfg.Brokers, cfg)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "client init failed")
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, pkgerrors.Wrap(err, "creating new consumer")
+	}
+
+	return &TopicReader{
+		client:   client,
+		consumer: consumer,
+		topic:    topic,
+	}, nil
+}
+
+// ReadAll calls fn for every message currently in the topic, partition by partition in offset order.
+// Messages produced after the call are not read. It stops at the first error returned by fn.
+func (r *TopicReader) ReadAll(ctx context.Context, fn func(msg ConsumerMessage) error) error {
+	partitions, err := r.client.Partitions(r.topic)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "getting partitions of %s", r.topic)
+	}
+
+	for _, p := range partitions {
+		if err := r.readPartition(ctx, p, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TopicReader) readPartition(ctx context.Context, partition int32, fn func(msg ConsumerMessage) error) error {
+	oldest, err := r.client.GetOffset(r.topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "getting oldest offset of %s[%d]", r.topic, partition)
+	}
+	newest, err := r.client.GetOffset(r.topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "getting newest offset of %s[%d]", r.topic, partition)
+	}
+	if oldest >= newest {
+		return nil
+	}
+
+	pc, err := r.consumer.ConsumePartition(r.topic, partition, oldest)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "consuming %s[%d]", r.topic, partition)
+	}
+	defer pc.Close()
+
+	idle := time.NewTimer(topicReaderIdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case cm, ok := <-pc.Messages():
+			if !ok {
+				return nil
+			}
+			if err := fn(toConsumerMessage(cm)); err != nil {
+				return err
+			}
+			// newest is the offset of the next message to be produced
+			if cm.Offset+1 >= newest {
+				return nil
+			}
+			idle.Reset(topicReaderIdleTimeout)
+		case <-idle.C:
+			log.Printf("[Kafka TopicReader] No message for %v on %s[%d], stopping before offset %d", topicReaderIdleTimeout, r.topic, partition, newest)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close shuts down the reader.
+func (r *TopicReader) Close() error {
+	if err := r.consumer.Close(); err != nil {
+		return pkgerrors.Wrap(err, "could not stop consumer")
+	}
+	if !r.client.Closed() {
+		if err := r.client.Close(); err != nil {
+			return pkgerrors.Wrap(err, "could not stop consumer client")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	pkgerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Topic config keys compared against the specs
+const (
+	topicConfigRetention   = "retention.ms"
+	topicConfigCompression = "compression.type"
+)
+
+// TopicSpec declares a topic, a zero Retention or Compression leaves the setting to the broker's default
+type TopicSpec struct {
+	Name              string        `yaml:"name"`
+	Partitions        int32         `yaml:"partitions"`
+	ReplicationFactor int16         `yaml:"replication_factor"`
+	Retention         time.Duration `yaml:"retention"`
+	Compression       string        `yaml:"compression"` // compression.type of the topic, e.g. producer or lz4
+}
+
+// TopicDrift is a setting of an existing topic which differs from its spec
+type TopicDrift struct {
+	Topic    string
+	Setting  string
+	Expected string
+	Actual   string
+}
+
+func (d TopicDrift) String() string {
+	return fmt.Sprintf("%s: %s is %s, expected %s", d.Topic, d.Setting, d.Actual, d.Expected)
+}
+
+// TopicReport is the outcome of provisioning or checking the topics
+type TopicReport struct {
+	Created []string
+	Missing []string // only filled when checking
+	Drifts  []TopicDrift
+}
+
+// InSync tells if every topic exists as specified
+func (r TopicReport) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Drifts) == 0
+}
+
+// TopicAdmin provisions the topics from their specs.
+// Existing topics are never altered, a drift from their spec is only reported since shrinking partitions isn't possible
+// and adding some would move the keys to other partitions.
+type TopicAdmin struct {
+	admin sarama.ClusterAdmin
+}
+
+// NewTopicAdmin creates a new TopicAdmin
+func NewTopicAdmin(ctx context.Context, clientCfg ClientConfig) (*TopicAdmin, error) {
+	cfg, err := clientCfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdmin(clientCfg.Brokers, cfg)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "cluster admin init failed")
+	}
+
+	return &TopicAdmin{admin: admin}, nil
+}
+
+// Provision creates the missing topics and reports the drift of the existing ones, it can be run on every startup
+func (a *TopicAdmin) Provision(ctx context.Context, specs []TopicSpec) (TopicReport, error) {
+	return a.reconcile(specs, true)
+}
+
+// Check only reports the missing topics and the drift of the existing ones
+func (a *TopicAdmin) Check(ctx context.Context, specs []TopicSpec) (TopicReport, error) {
+	return a.reconcile(specs, false)
+}
+
+// Close closes the admin's connections
+func (a *TopicAdmin) Close() error {
+	return pkgerrors.Wrap(a.admin.Close(), "could not stop cluster admin")
+}
+
+func (a *TopicAdmin) reconcile(specs []TopicSpec, create bool) (TopicReport, error) {
+	existing, err := a.admin.ListTopics()
+	if err != nil {
+		return TopicReport{}, pkgerrors.Wrap(err, "listing topics")
+	}
+
+	var report TopicReport
+	for _, spec := range specs {
+		detail, ok := existing[spec.Name]
+		if ok {
+			drifts := topicDrifts(spec, detail)
+			for _, d := range drifts {
+				log.Printf("[Kafka Topics] Topic drifted from its spec, %s", d)
+			}
+			report.Drifts = append(report.Drifts, drifts...)
+			continue
+		}
+
+		if !create {
+			report.Missing = append(report.Missing, spec.Name)
+			continue
+		}
+
+		err := a.admin.CreateTopic(spec.Name, spec.topicDetail(), false)
+		switch {
+		case errors.Is(err, sarama.ErrTopicAlreadyExists):
+			// created meanwhile by another instance
+			log.Printf("[Kafka Topics] Topic %s already exists", spec.Name)
+		case err != nil:
+			return report, pkgerrors.Wrapf(err, "creating topic %s", spec.Name)
+		default:
+			log.Printf("[Kafka Topics] Created topic %s with %d partitions", spec.Name, spec.Partitions)
+			report.Created = append(report.Created, spec.Name)
+		}
+	}
+
+	return report, nil
+}
+
+func (s TopicSpec) topicDetail() *sarama.TopicDetail {
+	entries := make(map[string]*string)
+	if s.Retention > 0 {
+		retention := strconv.FormatInt(s.Retention.Milliseconds(), 10)
+		entries[topicConfigRetention] = &retention
+	}
+	if s.Compression != "" {
+		compression := s.Compression
+		entries[topicConfigCompression] = &compression
+	}
+
+	return &sarama.TopicDetail{
+		NumPartitions:     s.Partitions,
+		ReplicationFactor: s.ReplicationFactor,
+		ConfigEntries:     entries,
+	}
+}
+
+// topicDrifts compares an existing topic to its spec, detail only holds the configs which aren't the broker's default
+func topicDrifts(spec TopicSpec, detail sarama.TopicDetail) []TopicDrift {
+	var drifts []TopicDrift
+	add := func(setting, expected, actual string) {
+		if expected != actual {
+			drifts = append(drifts, TopicDrift{Topic: spec.Name, Setting: setting, Expected: expected, Actual: actual})
+		}
+	}
+	config := func(key string) string {
+		if v := detail.ConfigEntries[key]; v != nil {
+			return *v
+		}
+		return "default"
+	}
+
+	add("partitions", strconv.Itoa(int(spec.Partitions)), strconv.Itoa(int(detail.NumPartitions)))
+	add("replication factor", strconv.Itoa(int(spec.ReplicationFactor)), strconv.Itoa(int(detail.ReplicationFactor)))
+	if spec.Retention > 0 {
+		add(topicConfigRetention, strconv.FormatInt(spec.Retention.Milliseconds(), 10), config(topicConfigRetention))
+	}
+	if spec.Compression != "" {
+		add(topicConfigCompression, spec.Compression, config(topicConfigCompression))
+	}
+	return drifts
+}
+
+// LoadTopicSpecs reads the specs from the topics list of a YAML file
+func LoadTopicSpecs(path string) ([]TopicSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "reading topics file")
+	}
+
+	var file struct {
+		Topics []TopicSpec `yaml:"topics"`
+	}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, pkgerrors.Wrap(err, "parsing topics file")
+	}
+
+	seen := make(map[string]bool, len(file.Topics))
+	for _, s := range file.Topics {
+		switch {
+		case s.Name == "":
+			return nil, errors.New("topic without name")
+		case seen[s.Name]:
+			return nil, fmt.Errorf("topic %s is declared twice", s.Name)
+		case s.Partitions < 1:
+			return nil, fmt.Errorf("topic %s must have at least 1 partition", s.Name)
+		case s.ReplicationFactor < 1:
+			return nil, fmt.Errorf("topic %s must have a replication factor of at least 1", s.Name)
+		case s.Retention < 0:
+			return nil, fmt.Errorf("topic %s has a negative retention", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return file.Topics, nil
+}
@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClusterAdmin struct {
+	sarama.ClusterAdmin
+	topics  map[string]sarama.TopicDetail
+	created map[string]*sarama.TopicDetail
+}
+
+func (a *fakeClusterAdmin) ListTopics() (map[string]sarama.TopicDetail, error) {
+	return a.topics, nil
+}
+
+func (a *fakeClusterAdmin) CreateTopic(topic string, detail *sarama.TopicDetail, validateOnly bool) error {
+	if _, ok := a.topics[topic]; ok {
+		return &sarama.TopicError{Err: sarama.ErrTopicAlreadyExists}
+	}
+	a.created[topic] = detail
+	return nil
+}
+
+func TestTopicAdmin(t *testing.T) {
+	str := func(s string) *string { return &s }
+	specs := []TopicSpec{
+		{Name: "iot.sensor", Partitions: 6, ReplicationFactor: 1, Retention: 24 * time.Hour, Compression: "producer"},
+		{Name: "iot.sensor.dlq", Partitions: 1, ReplicationFactor: 1},
+	}
+
+	tcs := map[string]struct {
+		givenTopics map[string]sarama.TopicDetail
+		givenCheck  bool
+		expReport   TopicReport
+		expCreated  map[string]*sarama.TopicDetail
+	}{
+		"creates_missing": {
+			givenTopics: map[string]sarama.TopicDetail{
+				"iot.sensor.dlq": {NumPartitions: 1, ReplicationFactor: 1},
+			},
+			expReport: TopicReport{Created: []string{"iot.sensor"}},
+			expCreated: map[string]*sarama.TopicDetail{
+				"iot.sensor": {
+					NumPartitions:     6,
+					ReplicationFactor: 1,
+					ConfigEntries:     map[string]*string{"retention.ms": str("86400000"), "compression.type": str("producer")},
+				},
+			},
+		},
+		"in_sync": {
+			givenTopics: map[string]sarama.TopicDetail{
+				"iot.sensor": {
+					NumPartitions:     6,
+					ReplicationFactor: 1,
+					ConfigEntries:     map[string]*string{"retention.ms": str("86400000"), "compression.type": str("producer")},
+				},
+				"iot.sensor.dlq": {NumPartitions: 1, ReplicationFactor: 1, ConfigEntries: map[string]*string{"retention.ms": str("1000")}},
+			},
+			expCreated: map[string]*sarama.TopicDetail{},
+		},
+		"drift_is_only_reported": {
+			givenTopics: map[string]sarama.TopicDetail{
+				"iot.sensor":     {NumPartitions: 3, ReplicationFactor: 1, ConfigEntries: map[string]*string{"compression.type": str("producer")}},
+				"iot.sensor.dlq": {NumPartitions: 1, ReplicationFactor: 1},
+			},
+			expReport: TopicReport{Drifts: []TopicDrift{
+				{Topic: "iot.sensor", Setting: "partitions", Expected: "6", Actual: "3"},
+				{Topic: "iot.sensor", Setting: "retention.ms", Expected: "86400000", Actual: "default"},
+			}},
+			expCreated: map[string]*sarama.TopicDetail{},
+		},
+		"check_does_not_create": {
+			givenTopics: map[string]sarama.TopicDetail{},
+			givenCheck:  true,
+			expReport:   TopicReport{Missing: []string{"iot.sensor", "iot.sensor.dlq"}},
+			expCreated:  map[string]*sarama.TopicDetail{},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			fake := &fakeClusterAdmin{topics: tc.givenTopics, created: map[string]*sarama.TopicDetail{}}
+			admin := &TopicAdmin{admin: fake}
+
+			// When:
+			var report TopicReport
+			var err error
+			if tc.givenCheck {
+				report, err = admin.Check(context.Background(), specs)
+			} else {
+				report, err = admin.Provision(context.Background(), specs)
+			}
+
+			// Then:
+			require.NoError(t, err)
+			require.Equal(t, tc.expReport, report)
+			require.Equal(t, len(tc.expReport.Missing) == 0 && len(tc.expReport.Drifts) == 0, report.InSync())
+			require.Equal(t, tc.expCreated, fake.created)
+		})
+	}
+}
+
+func TestLoadTopicSpecs(t *testing.T) {
+	tcs := map[string]struct {
+		givenYAML string
+		expResult []TopicSpec
+		expErr    string
+	}{
+		"valid": {
+			givenYAML: "topics:\n  - {name: iot.sensor, partitions: 6, replication_factor: 3, retention: 168h, compression: lz4}\n",
+			expResult: []TopicSpec{{Name: "iot.sensor", Partitions: 6, ReplicationFactor: 3, Retention: 168 * time.Hour, Compression: "lz4"}},
+		},
+		"duplicate": {
+			givenYAML: "topics:\n  - {name: iot.sensor, partitions: 6, replication_factor: 1}\n  - {name: iot.sensor, partitions: 3, replication_factor: 1}\n",
+			expErr:    "topic iot.sensor is declared twice",
+		},
+		"no_partition": {
+			givenYAML: "topics:\n  - {name: iot.sensor, replication_factor: 1}\n",
+			expErr:    "topic iot.sensor must have at least 1 partition",
+		},
+		"no_replication_factor": {
+			givenYAML: "topics:\n  - {name: iot.sensor, partitions: 6}\n",
+			expErr:    "topic iot.sensor must have a replication factor of at least 1",
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			path := filepath.Join(t.TempDir(), "topics.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.givenYAML), 0o600))
+
+			// When:
+			specs, err := LoadTopicSpecs(path)
+
+			// Then:
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expResult, specs)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,10 +34,12 @@ type Metrics struct {
 	iotBatchSize           *prometheus.HistogramVec
 	iotDeviceCount         prometheus.Gauge
 	iotSensorReadings      prometheus.Counter
+	iotOrderingViolations  prometheus.Counter
 
 	// Kafka metrics
 	kafkaMessagesConsumed prometheus.Counter
-	kafkaConsumerLag      prometheus.Gauge
+	kafkaConsumerLag      *prometheus.GaugeVec
+	kafkaRebalances       *prometheus.CounterVec
 	kafkaProducerMessages prometheus.Counter
 	kafkaProducerErrors   prometheus.Counter
 
@@ -129,6 +132,11 @@ func NewMetrics(namespace string) *Metrics {
 			Name:      "iot_devices_total",
 			Help:      "Total number of IoT devices",
 		}),
+		iotOrderingViolations: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "iot_ordering_violations_total",
+			Help:      "Total number of readings consumed after a newer reading of the same device",
+		}),
 		iotSensorReadings: promauto.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "iot_sensor_readings_total",
@@ -141,11 +149,16 @@ func NewMetrics(namespace string) *Metrics {
 			Name:      "kafka_messages_consumed_total",
 			Help:      "Total number of Kafka messages consumed",
 		}),
-		kafkaConsumerLag: promauto.NewGauge(prometheus.GaugeOpts{
+		kafkaConsumerLag: promauto.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: namespace,
 			Name:      "kafka_consumer_lag",
-			Help:      "Current Kafka consumer lag",
-		}),
+			Help:      "Current Kafka consumer lag per partition",
+		}, []string{"group", "topic", "partition"}),
+		kafkaRebalances: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_consumer_rebalances_total",
+			Help:      "Total number of consumer group rebalances seen by this member",
+		}, []string{"group"}),
 		kafkaProducerMessages: promauto.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "kafka_producer_messages_total",
@@ -300,13 +313,21 @@ func (m *Metrics) RecordSensorReading() {
 	m.iotSensorReadings.Inc()
 }
 
+func (m *Metrics) RecordOrderingViolations(count int) {
+	m.iotOrderingViolations.Add(float64(count))
+}
+
 // Kafka metrics methods
 func (m *Metrics) RecordKafkaMessageConsumed() {
 	m.kafkaMessagesConsumed.Inc()
 }
 
-func (m *Metrics) SetKafkaConsumerLag(lag int64) {
-	m.kafkaConsumerLag.Set(float64(lag))
+func (m *Metrics) SetKafkaConsumerLag(group, topic string, partition int32, lag int64) {
+	m.kafkaConsumerLag.WithLabelValues(group, topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+func (m *Metrics) RecordKafkaRebalance(group string) {
+	m.kafkaRebalances.WithLabelValues(group).Inc()
 }
 
 func (m *Metrics) RecordKafkaMessageProduced() {
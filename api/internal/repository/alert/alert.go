@@ -0,0 +1,107 @@
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+// GetAlerts retrieves the alerts matching the filters, newest first
+func (r impl) GetAlerts(ctx context.Context, input model.GetAlertsInput) ([]model.Alert, error) {
+	qms := []qm.QueryMod{qm.OrderBy(dbmodel.AlertColumns.ID + " DESC")}
+	if len(input.Statuses) > 0 {
+		statuses := make([]string, len(input.Statuses))
+		for i, s := range input.Statuses {
+			statuses[i] = s.ToString()
+		}
+		qms = append(qms, dbmodel.AlertWhere.Status.IN(statuses))
+	}
+	if input.RuleID != 0 {
+		qms = append(qms, dbmodel.AlertWhere.RuleID.EQ(input.RuleID))
+	}
+	if input.DeviceID != "" {
+		qms = append(qms, dbmodel.AlertWhere.DeviceID.EQ(input.DeviceID))
+	}
+	if len(input.DeviceIDs) > 0 {
+		qms = append(qms, dbmodel.AlertWhere.DeviceID.IN(input.DeviceIDs))
+	}
+	if input.Limit > 0 {
+		qms = append(qms, qm.Limit(input.Limit))
+	}
+
+	rows, err := dbmodel.Alerts(qms...).All(ctx, r.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+
+	return toModelAlerts(rows), nil
+}
+
+// GetAlert retrieves an alert by id
+func (r impl) GetAlert(ctx context.Context, id int64) (model.Alert, error) {
+	row, err := dbmodel.FindAlert(ctx, r.dbConn, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Alert{}, ErrNotFound
+		}
+		return model.Alert{}, fmt.Errorf("failed to query alert %d: %w", id, err)
+	}
+
+	return toModelAlert(row), nil
+}
+
+// CreateAlerts inserts alerts, the ids must be set by the caller
+func (r impl) CreateAlerts(ctx context.Context, alerts []model.Alert) error {
+	for _, a := range alerts {
+		row := dbmodel.Alert{
+			ID:             a.ID,
+			RuleID:         a.RuleID,
+			DeviceID:       a.DeviceID,
+			Metric:         string(a.Metric),
+			Value:          a.Value,
+			Threshold:      a.Threshold,
+			Status:         a.Status.ToString(),
+			TriggeredAt:    a.TriggeredAt,
+			AcknowledgedAt: nullTime(a.AcknowledgedAt),
+			ResolvedAt:     nullTime(a.ResolvedAt),
+			CreatedAt:      nullTime(a.CreatedAt),
+			UpdatedAt:      nullTime(a.UpdatedAt),
+		}
+		if err := row.Insert(ctx, r.dbConn, boil.Infer()); err != nil {
+			return fmt.Errorf("failed to insert alert %d: %w", a.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateAlertStatus saves the status and the state timestamps of an alert
+func (r impl) UpdateAlertStatus(ctx context.Context, alert model.Alert) error {
+	row := dbmodel.Alert{
+		ID:             alert.ID,
+		Status:         alert.Status.ToString(),
+		AcknowledgedAt: nullTime(alert.AcknowledgedAt),
+		ResolvedAt:     nullTime(alert.ResolvedAt),
+	}
+
+	rowsAff, err := row.Update(ctx, r.dbConn, boil.Whitelist(
+		dbmodel.AlertColumns.Status,
+		dbmodel.AlertColumns.AcknowledgedAt,
+		dbmodel.AlertColumns.ResolvedAt,
+		dbmodel.AlertColumns.UpdatedAt,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to update alert %d: %w", alert.ID, err)
+	}
+	if rowsAff == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+func toModelRules(orms dbmodel.AlertRuleSlice) []model.AlertRule {
+	rules := make([]model.AlertRule, len(orms))
+	for i, o := range orms {
+		rules[i] = toModelRule(o)
+	}
+	return rules
+}
+
+func toModelRule(o *dbmodel.AlertRule) model.AlertRule {
+	return model.AlertRule{
+		ID:         o.ID,
+		Name:       o.Name,
+		DeviceID:   o.DeviceID,
+		DeviceType: o.DeviceType,
+		Floor:      o.FloorID.Int,
+		Zone:       o.ZoneID.Int,
+		Metric:     model.AlertMetric(o.Metric),
+		Operator:   model.AlertOperator(o.Operator),
+		Threshold:  o.Threshold,
+		Duration:   time.Duration(o.DurationSeconds) * time.Second,
+		Enabled:    o.Enabled,
+		CreatedAt:  o.CreatedAt.Time,
+		UpdatedAt:  o.UpdatedAt.Time,
+	}
+}
+
+func toModelAlerts(orms dbmodel.AlertSlice) []model.Alert {
+	alerts := make([]model.Alert, len(orms))
+	for i, o := range orms {
+		alerts[i] = toModelAlert(o)
+	}
+	return alerts
+}
+
+func toModelAlert(o *dbmodel.Alert) model.Alert {
+	return model.Alert{
+		ID:             o.ID,
+		RuleID:         o.RuleID,
+		DeviceID:       o.DeviceID,
+		Metric:         model.AlertMetric(o.Metric),
+		Value:          o.Value,
+		Threshold:      o.Threshold,
+		Status:         model.AlertStatus(o.Status),
+		TriggeredAt:    o.TriggeredAt,
+		AcknowledgedAt: o.AcknowledgedAt.Time,
+		ResolvedAt:     o.ResolvedAt.Time,
+		CreatedAt:      o.CreatedAt.Time,
+		UpdatedAt:      o.UpdatedAt.Time,
+	}
+}
+
+// nullInt stores the zero value as NULL so optional foreign keys stay valid
+func nullInt(v int) null.Int {
+	return null.NewInt(v, v != 0)
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) null.Time {
+	return null.NewTime(t, !t.IsZero())
+}
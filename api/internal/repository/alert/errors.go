@@ -0,0 +1,8 @@
+package alert
+
+import "errors"
+
+var (
+	// ErrNotFound means the item was not found
+	ErrNotFound = errors.New("not found")
+)
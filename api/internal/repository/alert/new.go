@@ -0,0 +1,31 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// Repository provides the specification of the alert rules and alerts storage
+type Repository interface {
+	GetRules(ctx context.Context, enabledOnly bool) ([]model.AlertRule, error)
+	GetRule(ctx context.Context, id int64) (model.AlertRule, error)
+	CreateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error)
+	UpdateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error)
+	GetAlerts(ctx context.Context, input model.GetAlertsInput) ([]model.Alert, error)
+	GetAlert(ctx context.Context, id int64) (model.Alert, error)
+	CreateAlerts(ctx context.Context, alerts []model.Alert) error
+	UpdateAlertStatus(ctx context.Context, alert model.Alert) error
+}
+
+// New returns an implementation instance satisfying Repository
+func New(dbConn boil.ContextExecutor) Repository {
+	return impl{
+		dbConn: dbConn,
+	}
+}
+
+type impl struct {
+	dbConn boil.ContextExecutor
+}
@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+// GetRules retrieves the alert rules, oldest first
+func (r impl) GetRules(ctx context.Context, enabledOnly bool) ([]model.AlertRule, error) {
+	qms := []qm.QueryMod{qm.OrderBy(dbmodel.AlertRuleColumns.ID)}
+	if enabledOnly {
+		qms = append(qms, dbmodel.AlertRuleWhere.Enabled.EQ(true))
+	}
+
+	rows, err := dbmodel.AlertRules(qms...).All(ctx, r.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+
+	return toModelRules(rows), nil
+}
+
+// GetRule retrieves an alert rule by id
+func (r impl) GetRule(ctx context.Context, id int64) (model.AlertRule, error) {
+	row, err := dbmodel.FindAlertRule(ctx, r.dbConn, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.AlertRule{}, ErrNotFound
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to query alert rule %d: %w", id, err)
+	}
+
+	return toModelRule(row), nil
+}
+
+// CreateRule inserts an alert rule, the id must be set by the caller
+func (r impl) CreateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	row := dbmodel.AlertRule{ID: rule.ID}
+	setRule(&row, rule)
+
+	if err := row.Insert(ctx, r.dbConn, boil.Infer()); err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to insert alert rule %s: %w", rule.Name, err)
+	}
+
+	return toModelRule(&row), nil
+}
+
+// UpdateRule overwrites everything but the id and creation time of an alert rule
+func (r impl) UpdateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	row, err := dbmodel.FindAlertRule(ctx, r.dbConn, rule.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.AlertRule{}, ErrNotFound
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to query alert rule %d: %w", rule.ID, err)
+	}
+
+	setRule(row, rule)
+
+	if _, err := row.Update(ctx, r.dbConn, boil.Blacklist(
+		dbmodel.AlertRuleColumns.ID,
+		dbmodel.AlertRuleColumns.CreatedAt,
+	)); err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to update alert rule %d: %w", rule.ID, err)
+	}
+
+	return toModelRule(row), nil
+}
+
+// setRule copies the editable fields of the rule onto the row
+func setRule(row *dbmodel.AlertRule, rule model.AlertRule) {
+	row.Name = rule.Name
+	row.DeviceID = rule.DeviceID
+	row.DeviceType = rule.DeviceType
+	row.FloorID = nullInt(rule.Floor)
+	row.ZoneID = nullInt(rule.Zone)
+	row.Metric = string(rule.Metric)
+	row.Operator = string(rule.Operator)
+	row.Threshold = rule.Threshold
+	row.DurationSeconds = int(rule.Duration.Seconds())
+	row.Enabled = rule.Enabled
+}
@@ -0,0 +1,178 @@
+package cassalert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// GetAlerts filters in memory, alerts is keyed by id only. A single status uses the status index.
+func (c *cassandraImpl) GetAlerts(ctx context.Context, input model.GetAlertsInput) ([]model.Alert, error) {
+	builder := qb.Select("alerts").Columns(alertColumns...)
+	binds := qb.M{}
+	if len(input.Statuses) == 1 {
+		builder = builder.Where(qb.Eq("status"))
+		binds["status"] = string(input.Statuses[0])
+	}
+	stmt, names := builder.ToCql()
+
+	q := c.session.Query(stmt, names).BindMap(binds).WithContext(ctx)
+	defer q.Release()
+
+	statuses := map[model.AlertStatus]bool{}
+	for _, s := range input.Statuses {
+		statuses[s] = true
+	}
+	deviceIDs := map[string]bool{}
+	for _, id := range input.DeviceIDs {
+		deviceIDs[id] = true
+	}
+
+	var alerts []model.Alert
+	iter := q.Iter()
+	for {
+		var a model.Alert
+		if !iter.Scan(alertDest(&a)...) {
+			break
+		}
+		if len(statuses) > 0 && !statuses[a.Status] {
+			continue
+		}
+		if input.RuleID != 0 && a.RuleID != input.RuleID {
+			continue
+		}
+		if input.DeviceID != "" && a.DeviceID != input.DeviceID {
+			continue
+		}
+		if len(deviceIDs) > 0 && !deviceIDs[a.DeviceID] {
+			continue
+		}
+		alerts = append(alerts, a)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID > alerts[j].ID })
+	if input.Limit > 0 && len(alerts) > input.Limit {
+		alerts = alerts[:input.Limit]
+	}
+
+	return alerts, nil
+}
+
+func (c *cassandraImpl) GetAlert(ctx context.Context, id int64) (model.Alert, error) {
+	stmt, names := qb.Select("alerts").
+		Columns(alertColumns...).
+		Where(qb.Eq("id")).
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"id": id}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var a model.Alert
+	if err := q.Scan(alertDest(&a)...); err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return model.Alert{}, alert.ErrNotFound
+		}
+		return model.Alert{}, fmt.Errorf("failed to query alert %d: %w", id, err)
+	}
+
+	return a, nil
+}
+
+func (c *cassandraImpl) CreateAlerts(ctx context.Context, alerts []model.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	// values are bound in the order of alertColumns
+	stmt, _ := qb.Insert("alerts").
+		Columns(alertColumns...).
+		ToCql()
+
+	err := cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		for _, a := range alerts {
+			b.Query(fmt.Sprintf("alerts:%d", a.ID), stmt,
+				a.ID,
+				a.RuleID,
+				a.DeviceID,
+				string(a.Metric),
+				a.Value,
+				a.Threshold,
+				string(a.Status),
+				a.TriggeredAt,
+				nullTime(a.AcknowledgedAt),
+				nullTime(a.ResolvedAt),
+				a.CreatedAt,
+				a.UpdatedAt,
+			)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert alerts: %w", err)
+	}
+
+	return nil
+}
+
+func (c *cassandraImpl) UpdateAlertStatus(ctx context.Context, a model.Alert) error {
+	stmt, names := qb.Update("alerts").
+		Set("status", "acknowledged_at", "resolved_at", "updated_at").
+		Where(qb.Eq("id")).
+		Existing().
+		ToCql()
+
+	err := cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional(fmt.Sprintf("alerts:%d", a.ID), alert.ErrNotFound, stmt, cassandra.BindMap(names, qb.M{
+			"id":              a.ID,
+			"status":          string(a.Status),
+			"acknowledged_at": nullTime(a.AcknowledgedAt),
+			"resolved_at":     nullTime(a.ResolvedAt),
+			"updated_at":      time.Now(),
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, alert.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to update alert %d: %w", a.ID, err)
+	}
+
+	return nil
+}
+
+func alertDest(a *model.Alert) []interface{} {
+	return []interface{}{
+		&a.ID,
+		&a.RuleID,
+		&a.DeviceID,
+		&a.Metric,
+		&a.Value,
+		&a.Threshold,
+		&a.Status,
+		&a.TriggeredAt,
+		&a.AcknowledgedAt,
+		&a.ResolvedAt,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	}
+}
+
+// nullTime writes the zero time as null instead of the epoch
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,24 @@
+package cassalert
+
+import (
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+	"github.com/scylladb/gocqlx/v2"
+)
+
+var (
+	ruleColumns = []string{"id", "name", "device_id", "device_type", "floor_id", "zone_id", "metric", "operator",
+		"threshold", "duration_seconds", "enabled", "created_at", "updated_at"}
+	alertColumns = []string{"id", "rule_id", "device_id", "metric", "value", "threshold", "status",
+		"triggered_at", "acknowledged_at", "resolved_at", "created_at", "updated_at"}
+)
+
+type cassandraImpl struct {
+	session *gocqlx.Session
+}
+
+// NewCassandra returns a Cassandra implementation satisfying alert.Repository
+func NewCassandra(session gocqlx.Session) alert.Repository {
+	return &cassandraImpl{
+		session: &session,
+	}
+}
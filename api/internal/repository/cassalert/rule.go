@@ -0,0 +1,157 @@
+package cassalert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+func (c *cassandraImpl) GetRules(ctx context.Context, enabledOnly bool) ([]model.AlertRule, error) {
+	stmt, names := qb.Select("alert_rules").
+		Columns(ruleColumns...).
+		ToCql()
+
+	q := c.session.Query(stmt, names).WithContext(ctx)
+	defer q.Release()
+
+	var rules []model.AlertRule
+	iter := q.Iter()
+	for {
+		var rule model.AlertRule
+		var durationSeconds int
+		if !iter.Scan(ruleDest(&rule, &durationSeconds)...) {
+			break
+		}
+		if enabledOnly && !rule.Enabled {
+			continue
+		}
+		rule.Duration = time.Duration(durationSeconds) * time.Second
+		rules = append(rules, rule)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+
+	// snowflake ids grow with time, same order as postgres
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules, nil
+}
+
+func (c *cassandraImpl) GetRule(ctx context.Context, id int64) (model.AlertRule, error) {
+	stmt, names := qb.Select("alert_rules").
+		Columns(ruleColumns...).
+		Where(qb.Eq("id")).
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"id": id}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var rule model.AlertRule
+	var durationSeconds int
+	if err := q.Scan(ruleDest(&rule, &durationSeconds)...); err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return model.AlertRule{}, alert.ErrNotFound
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to query alert rule %d: %w", id, err)
+	}
+	rule.Duration = time.Duration(durationSeconds) * time.Second
+
+	return rule, nil
+}
+
+func (c *cassandraImpl) CreateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	stmt, names := qb.Insert("alert_rules").
+		Columns(ruleColumns...).
+		ToCql()
+
+	err := cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Query(fmt.Sprintf("alert_rules:%d", rule.ID), stmt, cassandra.BindMap(names, ruleBinds(rule))...)
+	})
+	if err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to insert alert rule %s: %w", rule.Name, err)
+	}
+
+	return rule, nil
+}
+
+// UpdateRule returns the rule as it is once updated, which is before the update is
+// written in a DoInTx scope so it is read first
+func (c *cassandraImpl) UpdateRule(ctx context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	current, err := c.GetRule(ctx, rule.ID)
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+	rule.CreatedAt = current.CreatedAt
+	rule.UpdatedAt = time.Now()
+
+	stmt, names := qb.Update("alert_rules").
+		Set("name", "device_id", "device_type", "floor_id", "zone_id", "metric", "operator",
+			"threshold", "duration_seconds", "enabled", "updated_at").
+		Where(qb.Eq("id")).
+		Existing().
+		ToCql()
+
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional(fmt.Sprintf("alert_rules:%d", rule.ID), alert.ErrNotFound, stmt,
+			cassandra.BindMap(names, ruleBinds(rule))...)
+	})
+	if err != nil {
+		if errors.Is(err, alert.ErrNotFound) {
+			return model.AlertRule{}, err
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to update alert rule %d: %w", rule.ID, err)
+	}
+
+	return rule, nil
+}
+
+func ruleDest(rule *model.AlertRule, durationSeconds *int) []interface{} {
+	return []interface{}{
+		&rule.ID,
+		&rule.Name,
+		&rule.DeviceID,
+		&rule.DeviceType,
+		&rule.Floor,
+		&rule.Zone,
+		&rule.Metric,
+		&rule.Operator,
+		&rule.Threshold,
+		durationSeconds,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	}
+}
+
+func ruleBinds(rule model.AlertRule) qb.M {
+	return qb.M{
+		"id":               rule.ID,
+		"name":             rule.Name,
+		"device_id":        rule.DeviceID,
+		"device_type":      rule.DeviceType,
+		"floor_id":         rule.Floor,
+		"zone_id":          rule.Zone,
+		"metric":           string(rule.Metric),
+		"operator":         string(rule.Operator),
+		"threshold":        rule.Threshold,
+		"duration_seconds": int(rule.Duration.Seconds()),
+		"enabled":          rule.Enabled,
+		"created_at":       rule.CreatedAt,
+		"updated_at":       rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,39 @@
+package cassiot
+
+import (
+	"context"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+)
+
+// AggregateReadings reads the partitions of the readings over the time range and buckets
+// the readings in memory, producing the same result as time_bucket on postgres.
+// Cassandra has no GROUP BY over a time expression so this is the equivalent.
+func (c *cassandraImpl) AggregateReadings(ctx context.Context, input model.AggregateReadingsInput) ([]model.ReadingAggregate, error) {
+	agg, err := iotsystem.NewReadingAggregator(input.Interval, input.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.readReadings(ctx, model.GetReadingsInput{
+		DeviceID:  input.DeviceID,
+		Floor:     input.Floor,
+		Zone:      input.Zone,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+	}, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		// The end of the range is excluded, unlike the readings API
+		if !input.EndTime.IsZero() && !row.Timestamp.Before(input.EndTime) {
+			continue
+		}
+		agg.Add(row.toModel())
+	}
+
+	return agg.Result(), nil
+}
@@ -0,0 +1,177 @@
+package cassiot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// Layout is how the reading tables are partitioned
+type Layout string
+
+const (
+	// LayoutDevice keeps the whole history of a device in one partition
+	LayoutDevice Layout = "device"
+	// LayoutDay splits the history of a device into a partition per UTC day, the
+	// table of 0006_daily_readings.up.cql
+	LayoutDay Layout = "day"
+	// LayoutLegacy is the sensor_readings table of 0001_data.up.cql keyed by device and
+	// timestamp only, readings are only copied out of it
+	LayoutLegacy Layout = "legacy"
+)
+
+// Valid tells whether the layout is known
+func (l Layout) Valid() bool {
+	return l == LayoutDevice || l == LayoutDay
+}
+
+// bucketFanout is the number of day partitions read in parallel
+const bucketFanout = 8
+
+// dayOf returns the day bucket of a timestamp, the UTC day it falls in
+func dayOf(ts time.Time) time.Time {
+	y, m, d := ts.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// bucketDays lists the days holding readings of the partition key of a bucketed table
+// between start and end, newest first. A zero bound leaves that side open.
+func (c *cassandraImpl) bucketDays(ctx context.Context, table readingTable, key interface{},
+	start, end time.Time) ([]time.Time, error) {
+	builder := qb.Select("reading_buckets").
+		Columns("day_bucket").
+		Where(qb.Eq("source"), qb.Eq("key"))
+	binds := qb.M{"source": table.name, "key": fmt.Sprint(key)}
+	if !start.IsZero() {
+		builder = builder.Where(qb.GtOrEqNamed("day_bucket", "start_day"))
+		binds["start_day"] = dayOf(start)
+	}
+	if !end.IsZero() {
+		builder = builder.Where(qb.LtOrEqNamed("day_bucket", "end_day"))
+		binds["end_day"] = dayOf(end)
+	}
+	stmt, names := builder.ToCql()
+
+	var days []time.Time
+	if err := c.session.Query(stmt, names).BindMap(binds).WithContext(ctx).SelectRelease(&days); err != nil {
+		return nil, fmt.Errorf("failed to list the days of %s %v: %w", table.name, key, err)
+	}
+
+	return days, nil
+}
+
+// scanKey reads the readings of a partition key like scanPartition. The day partitions of a
+// bucketed table are read bucketFanout at a time in parallel, they hold disjoint days so
+// their readings are concatenated newest day first. It stops once limit readings are read
+// unless limit is 0.
+func (c *cassandraImpl) scanKey(ctx context.Context, table readingTable, key interface{},
+	input model.GetReadingsInput, after *readingCursor, limit int) ([]readingRow, error) {
+	if !table.bucketed {
+		return c.scanPartition(ctx, table, key, time.Time{}, input, after, limit)
+	}
+
+	days, err := c.bucketDays(ctx, table, key, input.StartTime, upperBound(input, after))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []readingRow
+	for len(days) > 0 {
+		window := days[:min(bucketFanout, len(days))]
+		days = days[len(window):]
+
+		results := make([][]readingRow, len(window))
+		errs := make([]error, len(window))
+		var wg sync.WaitGroup
+		wg.Add(len(window))
+		for i, day := range window {
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = c.scanPartition(ctx, table, key, day, input, after, limit)
+			}()
+		}
+		wg.Wait()
+
+		for i := range window {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			rows = append(rows, results[i]...)
+		}
+		if limit > 0 && len(rows) >= limit {
+			return rows[:limit], nil
+		}
+	}
+
+	return rows, nil
+}
+
+// countKey counts the readings of a partition key matching the input, one day partition
+// after the other for a bucketed table
+func (c *cassandraImpl) countKey(ctx context.Context, table readingTable, key interface{},
+	input model.GetReadingsInput) (int64, error) {
+	if !table.bucketed {
+		return c.countPartition(ctx, table, key, time.Time{}, input)
+	}
+
+	days, err := c.bucketDays(ctx, table, key, input.StartTime, input.EndTime)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, day := range days {
+		n, err := c.countPartition(ctx, table, key, day, input)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+// readingDeviceIDs lists the devices having readings
+func (c *cassandraImpl) readingDeviceIDs(ctx context.Context) ([]string, error) {
+	byDevice := c.tables[0]
+	if !byDevice.bucketed {
+		stmt, names := qb.Select(byDevice.name).
+			Distinct("device_id").
+			ToCql()
+
+		var deviceIDs []string
+		if err := c.session.Query(stmt, names).
+			WithContext(ctx).
+			SelectRelease(&deviceIDs); err != nil {
+			return nil, fmt.Errorf("failed to list reading devices: %w", err)
+		}
+		return deviceIDs, nil
+	}
+
+	// DISTINCT needs the whole partition key, (device_id, day_bucket) would list a device
+	// per day so its buckets are listed instead
+	stmt, names := qb.Select("reading_buckets").
+		Distinct("source", "key").
+		ToCql()
+
+	q := c.session.Query(stmt, names).WithContext(ctx)
+	defer q.Release()
+
+	var deviceIDs []string
+	iter := q.Iter()
+	var source, key string
+	for iter.Scan(&source, &key) {
+		if source == byDevice.name {
+			deviceIDs = append(deviceIDs, key)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list reading devices: %w", err)
+	}
+
+	return deviceIDs, nil
+}
@@ -0,0 +1,137 @@
+package cassiot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/scylladb/gocqlx/v2"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// CopyReadings copies every reading stored in the device table of layout from into the device
+// table of layout to, device by device and batchSize readings at a time, calling progress once a device
+// is copied. Rows keep their ids, message ids and write times, so an interrupted copy can be
+// run again.
+func CopyReadings(ctx context.Context, session gocqlx.Session, from, to Layout, batchSize int,
+	progress func(deviceID string, copied int)) error {
+	if !from.Valid() || !to.Valid() {
+		return fmt.Errorf("unknown layout: %s or %s", from, to)
+	}
+	if from == to {
+		return errors.New("the readings are already in this layout")
+	}
+	if batchSize <= 0 {
+		return errors.New("the batch size must be positive")
+	}
+	src, dst := newCassandra(session, from), newCassandra(session, to)
+	// the query tables are the same in every layout, only the device table is copied
+	dst.tables = dst.tables[:1]
+
+	deviceIDs, err := src.readingDeviceIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, deviceID := range deviceIDs {
+		var after *readingCursor
+		copied := 0
+		for {
+			rows, err := src.scanKey(ctx, src.tables[0], deviceID, model.GetReadingsInput{}, after, batchSize)
+			if err != nil {
+				return err
+			}
+			for i := range rows {
+				rows[i].DayBucket = dayOf(rows[i].Timestamp)
+			}
+			if err := dst.writeRows(ctx, rows, true); err != nil {
+				return fmt.Errorf("failed to copy readings of device %s: %w", deviceID, err)
+			}
+
+			copied += len(rows)
+			if len(rows) < batchSize {
+				break
+			}
+			cursor := cursorOf(rows[len(rows)-1])
+			after = &cursor
+		}
+		progress(deviceID, copied)
+	}
+
+	return nil
+}
+
+// legacyColumns are the columns of the sensor_readings table of 0001_data.up.cql
+var legacyColumns = []string{"id", "device_id", "device_name", "device_type", "location",
+	"floor_id", "zone_id", "temperature", "humidity", "co2", "timestamp", "created_at",
+	"heat_index", "air_quality_index", "durable_write_ts"}
+
+// CopyLegacyReadings copies the readings of the sensor_readings table of 0001_data.up.cql into the
+// tables of layout to, batchSize readings at a time, calling progress once a device is copied. The
+// copies get the message id legacy:<id> like 0005_reading_message_id.up.sql gives them on postgres
+// and keep their ids and write times, so an interrupted copy can be run again.
+func CopyLegacyReadings(ctx context.Context, session gocqlx.Session, to Layout, batchSize int,
+	progress func(deviceID string, copied int)) error {
+	if !to.Valid() {
+		return fmt.Errorf("unknown layout: %s", to)
+	}
+	if batchSize <= 0 {
+		return errors.New("the batch size must be positive")
+	}
+	dst := newCassandra(session, to)
+
+	stmt, names := qb.Select("sensor_readings").
+		Columns(legacyColumns...).
+		ToCql()
+	q := session.Query(stmt, names).PageSize(batchSize).WithContext(ctx)
+	defer q.Release()
+
+	var (
+		rows     []readingRow
+		deviceID string
+		copied   int
+	)
+	flush := func() error {
+		if err := dst.writeRows(ctx, rows, true); err != nil {
+			return fmt.Errorf("failed to copy legacy readings of device %s: %w", deviceID, err)
+		}
+		copied += len(rows)
+		rows = rows[:0]
+		return nil
+	}
+
+	// A table scan returns the partitions one after the other
+	iter := q.Iter()
+	var row readingRow
+	for iter.StructScan(&row) {
+		if deviceID != "" && row.DeviceID != deviceID {
+			if err := flush(); err != nil {
+				return err
+			}
+			progress(deviceID, copied)
+			copied = 0
+		}
+		deviceID = row.DeviceID
+		row.MessageID = "legacy:" + row.ID.String()
+		row.DayBucket = dayOf(row.Timestamp)
+		rows = append(rows, row)
+		if len(rows) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		row = readingRow{}
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to read legacy readings: %w", err)
+	}
+	if deviceID != "" {
+		if err := flush(); err != nil {
+			return err
+		}
+		progress(deviceID, copied)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package cassiot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+var deviceColumns = []string{"id", "device_id", "name", "type", "location", "floor_id", "zone_id",
+	"is_active", "created_at", "updated_at"}
+
+func (c *cassandraImpl) GetDevice(ctx context.Context, deviceID string) (model.IoTDevice, error) {
+	stmt, names := qb.Select("iot_devices").
+		Columns(deviceColumns...).
+		Where(qb.Eq("device_id")).
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"device_id": deviceID}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var device model.IoTDevice
+	var id gocql.UUID
+	err := q.Scan(&id,
+		&device.DeviceID,
+		&device.Name,
+		&device.Type,
+		&device.Location,
+		&device.Floor,
+		&device.Zone,
+		&device.IsActive,
+		&device.CreatedAt,
+		&device.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return model.IoTDevice{}, iotsystem.ErrNotFound
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to query device %s: %w", deviceID, err)
+	}
+
+	return device, nil
+}
+
+func (c *cassandraImpl) CreateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	now := time.Now()
+	device.IsActive = true
+	device.CreatedAt = now
+	device.UpdatedAt = now
+
+	// device_id is the partition key, IF NOT EXISTS keeps an existing device from being overwritten
+	stmt, names := qb.Insert("iot_devices").
+		Columns(deviceColumns...).
+		Unique().
+		ToCql()
+
+	err := cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional("iot_devices:"+device.DeviceID, iotsystem.ErrAlreadyExists, stmt, cassandra.BindMap(names, qb.M{
+			"id":         gocql.TimeUUID(),
+			"device_id":  device.DeviceID,
+			"name":       device.Name,
+			"type":       device.Type,
+			"location":   device.Location,
+			"floor_id":   device.Floor,
+			"zone_id":    device.Zone,
+			"is_active":  device.IsActive,
+			"created_at": device.CreatedAt,
+			"updated_at": device.UpdatedAt,
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrAlreadyExists) {
+			return model.IoTDevice{}, err
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to insert device %s: %w", device.DeviceID, err)
+	}
+
+	return device, nil
+}
+
+// UpdateDevice returns the device as it is once updated, which is before the update is
+// written in a DoInTx scope so it is read first
+func (c *cassandraImpl) UpdateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	current, err := c.GetDevice(ctx, device.DeviceID)
+	if err != nil {
+		return model.IoTDevice{}, err
+	}
+	current.Name = device.Name
+	current.Type = device.Type
+	current.Location = device.Location
+	current.Floor = device.Floor
+	current.Zone = device.Zone
+	current.UpdatedAt = time.Now()
+
+	stmt, names := qb.Update("iot_devices").
+		Set("name", "type", "location", "floor_id", "zone_id", "updated_at").
+		Where(qb.Eq("device_id")).
+		Existing().
+		ToCql()
+
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional("iot_devices:"+device.DeviceID, iotsystem.ErrNotFound, stmt, cassandra.BindMap(names, qb.M{
+			"device_id":  current.DeviceID,
+			"name":       current.Name,
+			"type":       current.Type,
+			"location":   current.Location,
+			"floor_id":   current.Floor,
+			"zone_id":    current.Zone,
+			"updated_at": current.UpdatedAt,
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrNotFound) {
+			return model.IoTDevice{}, err
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to update device %s: %w", device.DeviceID, err)
+	}
+
+	return current, nil
+}
+
+func (c *cassandraImpl) SetDeviceActive(ctx context.Context, deviceID string, active bool) error {
+	stmt, names := qb.Update("iot_devices").
+		Set("is_active", "updated_at").
+		Where(qb.Eq("device_id")).
+		Existing().
+		ToCql()
+
+	err := cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional("iot_devices:"+deviceID, iotsystem.ErrNotFound, stmt, cassandra.BindMap(names, qb.M{
+			"device_id":  deviceID,
+			"is_active":  active,
+			"updated_at": time.Now(),
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, iotsystem.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to set device %s active=%t: %w", deviceID, active, err)
+	}
+
+	return nil
+}
@@ -1,26 +1,53 @@
 package cassiot
 
 import (
-	"sync"
+	"encoding/base64"
+	"encoding/json"
 	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
 )
 
-// Per run/process, keep this around (e.g., as a field on cassandraImpl)
-type TsDeduper struct {
-	mu   sync.Mutex
-	last map[string]int64 // device_id -> last used ms since epoch
+// readingCursor is the keyset position of the last reading of a page. Readings are ordered by
+// (timestamp DESC, device_id DESC, message_id ASC), the clustering of the reading tables.
+type readingCursor struct {
+	Timestamp time.Time `json:"ts"`
+	DeviceID  string    `json:"device"`
+	MessageID string    `json:"message"`
+}
+
+// cursorOf returns the cursor pointing after the row
+func cursorOf(row readingRow) readingCursor {
+	return readingCursor{Timestamp: row.Timestamp, DeviceID: row.DeviceID, MessageID: row.MessageID}
+}
+
+// precedes tells whether the row comes after the cursor in page order
+func (c readingCursor) precedes(row readingRow) bool {
+	switch {
+	case !row.Timestamp.Equal(c.Timestamp):
+		return row.Timestamp.Before(c.Timestamp)
+	case row.DeviceID != c.DeviceID:
+		return row.DeviceID < c.DeviceID
+	default:
+		return row.MessageID > c.MessageID
+	}
 }
 
-func NewTsDeduper() *TsDeduper { return &TsDeduper{last: make(map[string]int64)} }
+// encodeCursor turns the cursor into an opaque string
+func encodeCursor(c readingCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
 
-// Next returns a time that is unique & monotonic-per-device at ms resolution.
-func (d *TsDeduper) Next(deviceID string, candidate time.Time) time.Time {
-	ms := candidate.UnixMilli()
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	if last, ok := d.last[deviceID]; ok && ms <= last {
-		ms = last + 1 // bump by 1ms (timestamp type only stores ms)
+// decodeCursor parses a cursor built by encodeCursor
+func decodeCursor(cursor string) (readingCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return readingCursor{}, iotsystem.ErrInvalidCursor
+	}
+	var c readingCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.DeviceID == "" {
+		return readingCursor{}, iotsystem.ErrInvalidCursor
 	}
-	d.last[deviceID] = ms
-	return time.UnixMilli(ms)
+	return c, nil
 }
@@ -0,0 +1,186 @@
+package cassiot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingCursor(t *testing.T) {
+	tcs := map[string]struct {
+		givenCursor string
+		expCursor   readingCursor
+		expErr      error
+	}{
+		"round_trip": {
+			givenCursor: encodeCursor(readingCursor{
+				Timestamp: time.Date(2025, 1, 1, 10, 30, 0, 123000000, time.UTC),
+				DeviceID:  "TEMP:001",
+				MessageID: "m:1",
+			}),
+			expCursor: readingCursor{
+				Timestamp: time.Date(2025, 1, 1, 10, 30, 0, 123000000, time.UTC),
+				DeviceID:  "TEMP:001",
+				MessageID: "m:1",
+			},
+		},
+		"not_base64": {
+			givenCursor: "%%%",
+			expErr:      iotsystem.ErrInvalidCursor,
+		},
+		"not_json": {
+			givenCursor: "MTIzOjQyOlRFTVBfMDAx", // "123:42:TEMP_001", a postgres cursor
+			expErr:      iotsystem.ErrInvalidCursor,
+		},
+		"missing_device": {
+			givenCursor: encodeCursor(readingCursor{Timestamp: time.Now(), MessageID: "m1"}),
+			expErr:      iotsystem.ErrInvalidCursor,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			cursor, err := decodeCursor(tc.givenCursor)
+
+			// Then:
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expCursor, cursor)
+		})
+	}
+}
+
+func TestSortReadingRows(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	rows := []readingRow{
+		{DeviceID: "T1", Timestamp: base, MessageID: "m2"},
+		{DeviceID: "T2", Timestamp: base.Add(time.Minute), MessageID: "m1"},
+		{DeviceID: "T1", Timestamp: base, MessageID: "m1"},
+		{DeviceID: "T2", Timestamp: base, MessageID: "m3"},
+	}
+
+	// When:
+	sortReadingRows(rows)
+
+	// Then: newest first, then device id descending and message id ascending like the clustering
+	require.Equal(t, []readingRow{
+		{DeviceID: "T2", Timestamp: base.Add(time.Minute), MessageID: "m1"},
+		{DeviceID: "T2", Timestamp: base, MessageID: "m3"},
+		{DeviceID: "T1", Timestamp: base, MessageID: "m1"},
+		{DeviceID: "T1", Timestamp: base, MessageID: "m2"},
+	}, rows)
+	for i := 1; i < len(rows); i++ {
+		require.True(t, cursorOf(rows[i-1]).precedes(rows[i]))
+		require.False(t, cursorOf(rows[i]).precedes(rows[i-1]))
+	}
+}
+
+func TestReadingPartition(t *testing.T) {
+	tcs := map[string]struct {
+		givenInput model.GetReadingsInput
+		expTable   string
+		expKey     interface{}
+	}{
+		"device_first": {
+			givenInput: model.GetReadingsInput{DeviceID: "T1", DeviceType: "temperature", Floor: 1},
+			expTable:   "readings_by_device",
+			expKey:     "T1",
+		},
+		"type_before_location": {
+			givenInput: model.GetReadingsInput{DeviceType: "temperature", Location: "Main Building"},
+			expTable:   "readings_by_type",
+			expKey:     "temperature",
+		},
+		"zone_before_floor": {
+			givenInput: model.GetReadingsInput{Floor: 1, Zone: 2},
+			expTable:   "readings_by_zone",
+			expKey:     2,
+		},
+		"floor": {
+			givenInput: model.GetReadingsInput{Floor: 1, StartTime: time.Now()},
+			expTable:   "readings_by_floor",
+			expKey:     1,
+		},
+		"unfiltered": {
+			givenInput: model.GetReadingsInput{StartTime: time.Now()},
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			table, key, ok := readingPartition(readingTablesOf(LayoutDevice), tc.givenInput)
+
+			// Then:
+			require.Equal(t, tc.expTable != "", ok)
+			require.Equal(t, tc.expTable, table.name)
+			require.Equal(t, tc.expKey, key)
+		})
+	}
+}
+
+func TestBindStruct(t *testing.T) {
+	row := newReadingRow(model.SensorReading{
+		DeviceID:  "T1",
+		Floor:     1,
+		Zone:      2,
+		CO2:       1500,
+		Timestamp: time.Date(2025, 1, 1, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600)),
+		MessageID: "m1",
+	})
+
+	// 23:30 at UTC-2 is on the next UTC day
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tcs := map[string]struct {
+		givenLayout Layout
+		expTables   []string
+		expBucketed []bool
+	}{
+		"device": {
+			givenLayout: LayoutDevice,
+			expTables:   []string{"readings_by_device", "readings_by_type", "readings_by_location", "readings_by_zone", "readings_by_floor"},
+			expBucketed: []bool{false, true, true, true, true},
+		},
+		"day": {
+			givenLayout: LayoutDay,
+			expTables:   []string{"readings_by_device_daily", "readings_by_type", "readings_by_location", "readings_by_zone", "readings_by_floor"},
+			expBucketed: []bool{true, true, true, true, true},
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			var names []string
+			var bucketed []bool
+			for _, table := range readingTablesOf(tc.givenLayout) {
+				names = append(names, table.name)
+				bucketed = append(bucketed, table.bucketed)
+				expPrefix := []interface{}{row.ID, "T1"}
+				if table.bucketed {
+					expPrefix = append([]interface{}{day}, expPrefix...)
+				}
+
+				// When:
+				values := cassandra.BindStruct(table.insert.names, row)
+				copied := cassandra.BindStruct(table.copy.names, row)
+
+				// Then: every bind marker of the inserts gets the column of the row
+				require.Len(t, values, len(expPrefix)+len(readingColumns)-2)
+				require.Equal(t, expPrefix, values[:len(expPrefix)])
+				require.Equal(t, []interface{}{2, "m1"}, values[len(values)-2:])
+				require.Equal(t, append(values, row.DurableWriteTS), copied)
+			}
+			require.Equal(t, tc.expTables, names)
+			require.Equal(t, tc.expBucketed, bucketed)
+		})
+	}
+}
@@ -3,24 +3,33 @@ package cassiot
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gocql/gocql"
 	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
 	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
 	"github.com/scylladb/gocqlx/v2"
 	"github.com/scylladb/gocqlx/v2/qb"
 )
 
 type cassandraImpl struct {
-	session   *gocqlx.Session
-	tsDeduper *TsDeduper
+	session *gocqlx.Session
+	// tables are the reading tables of the layout, readings_by_device first
+	tables []readingTable
 }
 
-func NewCassandra(session gocqlx.Session) iotsystem.Repository {
+// NewCassandra returns a Cassandra implementation satisfying iotsystem.Repository, storing
+// the readings in the tables of the layout
+func NewCassandra(session gocqlx.Session, layout Layout) iotsystem.Repository {
+	return newCassandra(session, layout)
+}
+
+func newCassandra(session gocqlx.Session, layout Layout) *cassandraImpl {
 	return &cassandraImpl{
-		session:   &session,
-		tsDeduper: NewTsDeduper(),
+		session: &session,
+		tables:  readingTablesOf(layout),
 	}
 }
 
@@ -71,144 +80,182 @@ func (c *cassandraImpl) GetDevices(ctx context.Context) ([]model.IoTDevice, erro
 		return nil, fmt.Errorf("error during scan: %w", err)
 	}
 
+	// iot_devices is partitioned by device id so rows come back in token order
+	sort.Slice(devices, func(i, j int) bool { return devices[i].DeviceID < devices[j].DeviceID })
+
 	return devices, nil
 }
 
+// BatchInsertReadings inserts multiple sensor readings in batches keeping their timestamps,
+// joining the batch of the DoInTx scope of ctx when there is one.
+// message_id is part of the primary key, so a redelivered message overwrites its own row
+// instead of needing a lightweight transaction. Every reading is written to readings_by_device
+// and to its query tables, a batch failing halfway is retried as a whole and overwrites
+// the rows it already wrote.
 func (c *cassandraImpl) BatchInsertReadings(ctx context.Context, readings []model.SensorReading) error {
-	// Create raw CQL query to use toTimestamp(now())
-	stmt := `INSERT INTO sensor_readings (
-        id, device_id, device_name, device_type, location,
-        floor_id, zone_id, temperature, humidity, co2, timestamp,
-        created_at, heat_index, air_quality_index, durable_write_ts
-    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, toTimestamp(now()))`
-
-	batch := c.session.Session.NewBatch(gocql.UnloggedBatch)
-	batch.SetConsistency(gocql.One)
-
-	for _, reading := range readings {
-		heatIndex := 0.5 * (reading.Temperature + 61.0 + ((reading.Temperature - 68.0) * 1.2) + (reading.Humidity * 0.094))
-		// Calculate air quality index
-		airQualityIndex := 1
-		switch {
-		case reading.CO2 >= 5000:
-			airQualityIndex = 4
-		case reading.CO2 >= 2000:
-			airQualityIndex = 3
-		case reading.CO2 >= 1000:
-			airQualityIndex = 2
-		}
-
-		ts := c.tsDeduper.Next(reading.DeviceID, reading.Timestamp)
-		batch.Query(stmt,
-			gocql.TimeUUID(),
-			reading.DeviceID,
-			reading.DeviceName,
-			reading.DeviceType,
-			reading.Location,
-			reading.Floor,
-			reading.Zone,
-			reading.Temperature,
-			reading.Humidity,
-			reading.CO2,
-			ts,
-			time.Now(),
-			heatIndex,
-			airQualityIndex,
-		)
+	rows := make([]readingRow, len(readings))
+	for i, reading := range readings {
+		rows[i] = newReadingRow(reading)
 	}
 
-	if err := c.session.ExecuteBatch(batch); err != nil {
+	if err := c.writeRows(ctx, rows, false); err != nil {
 		return fmt.Errorf("failed to batch insert readings: %w", err)
 	}
 
 	return nil
 }
 
-func (c *cassandraImpl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, error) {
-	builder := qb.Select("sensor_readings").
-		Columns("id", "device_id", "device_name", "device_type", "location",
-			"floor_id", "zone_id", "temperature", "humidity", "co2",
-			"timestamp", "created_at")
-
-	if input.DeviceID != "" {
-		builder = builder.Where(qb.Eq("device_id"))
-	}
-	if !input.StartTime.IsZero() {
-		builder = builder.Where(qb.GtOrEq("timestamp"))
-	}
-	if !input.EndTime.IsZero() {
-		builder = builder.Where(qb.LtOrEq("timestamp"))
+// writeRows writes the rows to every reading table, along with the days of the partitions
+// of the bucketed tables, batched by partition. A copied row keeps its durable write time.
+func (c *cassandraImpl) writeRows(ctx context.Context, rows []readingRow, copied bool) error {
+	if len(rows) == 0 {
+		return nil
 	}
 
-	stmt, names := builder.ToCql()
-	q := c.session.Query(stmt, names)
-	defer q.Release()
+	return cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		type bucket struct {
+			source, key string
+			day         time.Time
+		}
+		buckets := map[bucket]bool{}
+		for _, row := range rows {
+			for _, table := range c.tables {
+				insert := table.insert
+				if copied {
+					insert = table.copy
+				}
+				key := fmt.Sprint(cassandra.BindStruct([]string{table.column}, row)[0])
+				partition := table.name + ":" + key
+				if table.bucketed {
+					partition += ":" + row.DayBucket.Format(time.DateOnly)
+					buckets[bucket{source: table.name, key: key, day: row.DayBucket}] = true
+				}
+				b.Query(partition, insert.stmt, cassandra.BindStruct(insert.names, row)...)
+			}
+		}
+		for bk := range buckets {
+			b.Query("reading_buckets:"+bk.source+":"+bk.key,
+				"INSERT INTO reading_buckets (source, key, day_bucket) VALUES (?, ?, ?)", bk.source, bk.key, bk.day)
+		}
+	})
+}
 
-	if input.DeviceID != "" {
-		q.BindMap(qb.M{"device_id": input.DeviceID})
+// GetReadings retrieves sensor readings with filters, one page at a time. A filtered query
+// reads the partition key of its most selective filter, an unfiltered one reads every
+// device and merges them, up to maxMergedDevices. Pages are walked by seeking on
+// (timestamp, device_id, message_id), the returned cursor is empty once the last page is reached.
+// The paging state of gocql can't be used as the cursor as it resumes a single query, while
+// a page may merge a query per device or per day bucket.
+func (c *cassandraImpl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, string, error) {
+	var after *readingCursor
+	if input.Cursor != "" {
+		cursor, err := decodeCursor(input.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &cursor
 	}
-	if !input.StartTime.IsZero() {
-		q.BindMap(qb.M{"timestamp": input.StartTime})
+
+	// Fetch one extra row to know whether there is a next page
+	limit := 0
+	if input.Limit > 0 {
+		limit = input.Limit + 1
 	}
-	if !input.EndTime.IsZero() {
-		q.BindMap(qb.M{"timestamp": input.EndTime})
+
+	rows, err := c.readReadings(ctx, input, after, limit)
+	if err != nil {
+		return nil, "", err
 	}
 
-	var results []map[string]interface{}
-	if err := q.SelectRelease(&results); err != nil {
-		return nil, fmt.Errorf("failed to query readings: %w", err)
+	var nextCursor string
+	if input.Limit > 0 && len(rows) > input.Limit {
+		rows = rows[:input.Limit]
+		nextCursor = encodeCursor(cursorOf(rows[len(rows)-1]))
 	}
 
 	var readings []model.SensorReading
-	for _, row := range results {
-		readings = append(readings, model.SensorReading{
-			ID:          row["id"].(int64),
-			DeviceID:    row["device_id"].(string),
-			DeviceName:  row["device_name"].(string),
-			DeviceType:  row["device_type"].(string),
-			Location:    row["location"].(string),
-			Floor:       row["floor_id"].(int),
-			Zone:        row["zone_id"].(int),
-			Temperature: row["temperature"].(float64),
-			Humidity:    row["humidity"].(float64),
-			CO2:         row["co2"].(float64),
-			Timestamp:   row["timestamp"].(time.Time),
-			CreatedAt:   row["created_at"].(time.Time),
-		})
+	for _, row := range rows {
+		readings = append(readings, row.toModel())
 	}
 
-	return readings, nil
+	return readings, nextCursor, nil
+}
+
+// CountReadings counts the readings matching the filters, reading the same partitions as GetReadings
+// without keeping their rows
+func (c *cassandraImpl) CountReadings(ctx context.Context, input model.GetReadingsInput) (int64, error) {
+	if table, key, ok := readingPartition(c.tables, input); ok {
+		return c.countKey(ctx, table, key, input)
+	}
+
+	deviceIDs, err := c.mergedDeviceIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, deviceID := range deviceIDs {
+		n, err := c.countKey(ctx, c.tables[0], deviceID, input)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+// benchmarkColumns are the columns of benchmark_metrics
+var benchmarkColumns = []string{"id", "total_records", "processed_records", "failed_records",
+	"start_time", "end_time", "average_latency", "end_to_end_latency", "throughput",
+	"batch_size", "database_type", "created_at"}
+
+// benchmarkRow is a row of benchmark_metrics
+type benchmarkRow struct {
+	ID               gocql.UUID `db:"id"`
+	TotalRecords     int64      `db:"total_records"`
+	ProcessedRecords int64      `db:"processed_records"`
+	FailedRecords    int64      `db:"failed_records"`
+	StartTime        time.Time  `db:"start_time"`
+	EndTime          time.Time  `db:"end_time"`
+	AverageLatency   float64    `db:"average_latency"`
+	EndToEndLatency  float64    `db:"end_to_end_latency"`
+	Throughput       float64    `db:"throughput"`
+	BatchSize        int        `db:"batch_size"`
+	DatabaseType     string     `db:"database_type"`
+	CreatedAt        time.Time  `db:"created_at"`
 }
 
+// GetBenchmarkMetrics retrieves the latest benchmark runs, newest first.
+// Each run is its own partition so they are sorted here, there are few of them.
 func (c *cassandraImpl) GetBenchmarkMetrics(ctx context.Context, limit int) ([]model.BenchmarkMetrics, error) {
 	stmt, names := qb.Select("benchmark_metrics").
-		Columns("id", "total_records", "processed_records", "failed_records",
-			"start_time", "end_time", "average_latency", "throughput",
-			"batch_size", "database_type", "created_at").
-		Limit(uint(limit)).
-		OrderBy("created_at", qb.DESC).
+		Columns(benchmarkColumns...).
 		ToCql()
 
-	q := c.session.Query(stmt, names)
-	defer q.Release()
-
-	var results []map[string]interface{}
-	if err := q.SelectRelease(&results); err != nil {
+	var rows []benchmarkRow
+	if err := c.session.Query(stmt, names).WithContext(ctx).SelectRelease(&rows); err != nil {
 		return nil, fmt.Errorf("failed to query benchmark metrics: %w", err)
 	}
 
+	sort.Slice(rows, func(i, j int) bool { return rows[i].CreatedAt.After(rows[j].CreatedAt) })
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
 	var metrics []model.BenchmarkMetrics
-	for _, row := range results {
+	for _, row := range rows {
 		metrics = append(metrics, model.BenchmarkMetrics{
-			TotalRecords:     row["total_records"].(int64),
-			ProcessedRecords: row["processed_records"].(int64),
-			FailedRecords:    row["failed_records"].(int64),
-			StartTime:        row["start_time"].(time.Time),
-			EndTime:          row["end_time"].(time.Time),
-			AverageLatency:   row["average_latency"].(float64),
-			Throughput:       row["throughput"].(float64),
-			BatchSize:        row["batch_size"].(int),
-			DatabaseType:     row["database_type"].(string),
+			TotalRecords:     row.TotalRecords,
+			ProcessedRecords: row.ProcessedRecords,
+			FailedRecords:    row.FailedRecords,
+			StartTime:        row.StartTime,
+			EndTime:          row.EndTime,
+			AverageLatency:   row.AverageLatency,
+			EndToEndLatency:  row.EndToEndLatency,
+			Throughput:       row.Throughput,
+			BatchSize:        row.BatchSize,
+			DatabaseType:     row.DatabaseType,
+			CreatedAt:        row.CreatedAt,
 		})
 	}
 
@@ -217,92 +264,56 @@ func (c *cassandraImpl) GetBenchmarkMetrics(ctx context.Context, limit int) ([]m
 
 func (c *cassandraImpl) SaveBenchmarkMetrics(ctx context.Context, metrics model.BenchmarkMetrics) error {
 	stmt, names := qb.Insert("benchmark_metrics").
-		Columns("id", "total_records", "processed_records", "failed_records",
-			"start_time", "end_time", "average_latency", "end_to_end_latency", "throughput",
-			"batch_size", "database_type", "created_at").
+		Columns(benchmarkColumns...).
 		ToCql()
 
-	q := c.session.Query(stmt, names).BindMap(qb.M{
-		"id":                 gocql.TimeUUID(),
-		"total_records":      metrics.TotalRecords,
-		"processed_records":  metrics.ProcessedRecords,
-		"failed_records":     metrics.FailedRecords,
-		"start_time":         metrics.StartTime,
-		"end_time":           metrics.EndTime,
-		"average_latency":    metrics.AverageLatency,
-		"end_to_end_latency": metrics.EndToEndLatency,
-		"throughput":         metrics.Throughput,
-		"batch_size":         metrics.BatchSize,
-		"database_type":      metrics.DatabaseType,
-		"created_at":         time.Now(),
+	row := benchmarkRow{
+		ID:               gocql.TimeUUID(),
+		TotalRecords:     metrics.TotalRecords,
+		ProcessedRecords: metrics.ProcessedRecords,
+		FailedRecords:    metrics.FailedRecords,
+		StartTime:        metrics.StartTime,
+		EndTime:          metrics.EndTime,
+		AverageLatency:   metrics.AverageLatency,
+		EndToEndLatency:  metrics.EndToEndLatency,
+		Throughput:       metrics.Throughput,
+		BatchSize:        metrics.BatchSize,
+		DatabaseType:     metrics.DatabaseType,
+		CreatedAt:        metrics.CreatedAt,
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	err := cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Query("benchmark_metrics:"+row.ID.String(), stmt, cassandra.BindStruct(names, row)...)
 	})
-
-	if err := q.Exec(); err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to save benchmark metrics: %w", err)
 	}
 
-	q.Release()
-
 	return nil
 }
 
+// GetLatestReadings retrieves the latest reading of each device having readings, ordered by device id
 func (c *cassandraImpl) GetLatestReadings(ctx context.Context) ([]model.SensorReading, error) {
-	// Get all unique device IDs first
-	deviceStmt, deviceNames := qb.Select("iot_devices").
-		Columns("device_id").
-		ToCql()
-
-	deviceQuery := c.session.Query(deviceStmt, deviceNames)
-	defer deviceQuery.Release()
-
-	var deviceResults []map[string]interface{}
-	if err := deviceQuery.SelectRelease(&deviceResults); err != nil {
-		return nil, fmt.Errorf("failed to query devices: %w", err)
+	deviceIDs, err := c.readingDeviceIDs(ctx)
+	if err != nil {
+		return nil, err
 	}
+	sort.Strings(deviceIDs)
 
-	// For each device, get the latest reading
 	var readings []model.SensorReading
-	for _, device := range deviceResults {
-		deviceID := device["device_id"].(string)
-
-		stmt, names := qb.Select("sensor_readings").
-			Columns("id", "device_id", "device_name", "device_type", "location",
-				"floor_id", "zone_id", "temperature", "humidity", "co2",
-				"timestamp", "created_at", "heat_index", "air_quality_index").
-			Where(qb.Eq("device_id")).
-			OrderBy("timestamp", qb.DESC).
-			Limit(1).
-			ToCql()
-
-		q := c.session.Query(stmt, names).BindMap(qb.M{
-			"device_id": deviceID,
-		})
-		defer q.Release()
-
-		var results []map[string]interface{}
-		if err := q.SelectRelease(&results); err != nil {
-			return nil, fmt.Errorf("failed to query latest reading for device %s: %w", deviceID, err)
+	for _, deviceID := range deviceIDs {
+		// Partitions are clustered by timestamp DESC, the first row is the latest
+		rows, err := c.scanKey(ctx, c.tables[0], deviceID, model.GetReadingsInput{}, nil, 1)
+		if err != nil {
+			return nil, err
 		}
-
-		// Skip if no readings for this device
-		if len(results) == 0 {
+		// The partition may be gone since it was listed
+		if len(rows) == 0 {
 			continue
 		}
-
-		row := results[0] // Get the latest reading
-		readings = append(readings, model.SensorReading{
-			DeviceID:    row["device_id"].(string),
-			DeviceName:  row["device_name"].(string),
-			DeviceType:  row["device_type"].(string),
-			Location:    row["location"].(string),
-			Floor:       row["floor_id"].(int),
-			Zone:        row["zone_id"].(int),
-			Temperature: row["temperature"].(float64),
-			Humidity:    row["humidity"].(float64),
-			CO2:         row["co2"].(float64),
-			Timestamp:   row["timestamp"].(time.Time),
-			CreatedAt:   row["created_at"].(time.Time),
-		})
+		readings = append(readings, rows[0].toModel())
 	}
 
 	return readings, nil
@@ -0,0 +1,327 @@
+package cassiot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// readingColumns are the columns shared by readings_by_device and its query tables
+var readingColumns = []string{"id", "device_id", "device_name", "device_type", "location",
+	"floor_id", "zone_id", "temperature", "humidity", "co2", "timestamp", "created_at",
+	"heat_index", "air_quality_index", "message_id"}
+
+// readingSelectColumns are the columns read back, durable_write_ts is only kept by copies
+var readingSelectColumns = []string{"id", "device_id", "device_name", "device_type", "location",
+	"floor_id", "zone_id", "temperature", "humidity", "co2", "timestamp", "created_at",
+	"heat_index", "air_quality_index", "message_id", "durable_write_ts"}
+
+// readingTable is a table holding every reading, partitioned by one of their columns
+// and by day when it is bucketed
+type readingTable struct {
+	name     string
+	column   string
+	bucketed bool
+	// insert writes a new reading, stamping its durable write time
+	insert readingInsert
+	// copy writes a reading keeping its durable write time
+	copy readingInsert
+}
+
+type readingInsert struct {
+	stmt  string
+	names []string
+}
+
+// readingTablesOf returns the reading tables of the layout, the table of record
+// partitioned by device first and the others from the most selective filter. The
+// query tables hold the readings of every device of their key so they are split by
+// day in every layout, the layout only picks the table of record.
+func readingTablesOf(layout Layout) []readingTable {
+	tables := []readingTable{
+		{name: "readings_by_device", column: "device_id"},
+		{name: "readings_by_type", column: "device_type", bucketed: true},
+		{name: "readings_by_location", column: "location", bucketed: true},
+		{name: "readings_by_zone", column: "zone_id", bucketed: true},
+		{name: "readings_by_floor", column: "floor_id", bucketed: true},
+	}
+	if layout == LayoutDay {
+		tables[0].name += "_daily"
+		tables[0].bucketed = true
+	}
+	for i, t := range tables {
+		columns := readingColumns
+		if t.bucketed {
+			columns = append([]string{"day_bucket"}, readingColumns...)
+		}
+		t.insert.stmt, t.insert.names = qb.Insert(t.name).
+			Columns(columns...).
+			LitColumn("durable_write_ts", "toTimestamp(now())").
+			ToCql()
+		t.copy.stmt, t.copy.names = qb.Insert(t.name).
+			Columns(columns...).
+			Columns("durable_write_ts").
+			ToCql()
+		tables[i] = t
+	}
+	return tables
+}
+
+// readingRow is a row of readings_by_device and of its query tables
+type readingRow struct {
+	ID              gocql.UUID `db:"id"`
+	DeviceID        string     `db:"device_id"`
+	DeviceName      string     `db:"device_name"`
+	DeviceType      string     `db:"device_type"`
+	Location        string     `db:"location"`
+	FloorID         int        `db:"floor_id"`
+	ZoneID          int        `db:"zone_id"`
+	Temperature     float64    `db:"temperature"`
+	Humidity        float64    `db:"humidity"`
+	CO2             float64    `db:"co2"`
+	Timestamp       time.Time  `db:"timestamp"`
+	CreatedAt       time.Time  `db:"created_at"`
+	HeatIndex       float64    `db:"heat_index"`
+	AirQualityIndex int        `db:"air_quality_index"`
+	MessageID       string     `db:"message_id"`
+	DurableWriteTS  time.Time  `db:"durable_write_ts"`
+	DayBucket       time.Time  `db:"day_bucket"`
+}
+
+// newReadingRow builds the row of a new reading with its computed columns
+func newReadingRow(reading model.SensorReading) readingRow {
+	// Calculate air quality index
+	airQualityIndex := 1
+	switch {
+	case reading.CO2 >= 5000:
+		airQualityIndex = 4
+	case reading.CO2 >= 2000:
+		airQualityIndex = 3
+	case reading.CO2 >= 1000:
+		airQualityIndex = 2
+	}
+
+	return readingRow{
+		ID:              gocql.TimeUUID(),
+		DeviceID:        reading.DeviceID,
+		DeviceName:      reading.DeviceName,
+		DeviceType:      reading.DeviceType,
+		Location:        reading.Location,
+		FloorID:         reading.Floor,
+		ZoneID:          reading.Zone,
+		Temperature:     reading.Temperature,
+		Humidity:        reading.Humidity,
+		CO2:             reading.CO2,
+		Timestamp:       reading.Timestamp,
+		CreatedAt:       time.Now(),
+		HeatIndex:       0.5 * (reading.Temperature + 61.0 + ((reading.Temperature - 68.0) * 1.2) + (reading.Humidity * 0.094)),
+		AirQualityIndex: airQualityIndex,
+		MessageID:       reading.MessageID,
+		DayBucket:       dayOf(reading.Timestamp),
+	}
+}
+
+// toModel converts the row, leaving out the message id like postgres does
+func (r readingRow) toModel() model.SensorReading {
+	return model.SensorReading{
+		DeviceID:    r.DeviceID,
+		DeviceName:  r.DeviceName,
+		DeviceType:  r.DeviceType,
+		Location:    r.Location,
+		Floor:       r.FloorID,
+		Zone:        r.ZoneID,
+		Temperature: r.Temperature,
+		Humidity:    r.Humidity,
+		CO2:         r.CO2,
+		Timestamp:   r.Timestamp,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+// matches applies the filters of the input other than the time range
+func (r readingRow) matches(input model.GetReadingsInput) bool {
+	return (input.DeviceID == "" || r.DeviceID == input.DeviceID) &&
+		(input.DeviceType == "" || r.DeviceType == input.DeviceType) &&
+		(input.Location == "" || r.Location == input.Location) &&
+		(input.Floor <= 0 || r.FloorID == input.Floor) &&
+		(input.Zone <= 0 || r.ZoneID == input.Zone)
+}
+
+// readingPartition picks the table answering the filters of the input and the partition
+// key to read, ok is false when there is no filter to pick one
+func readingPartition(tables []readingTable, input model.GetReadingsInput) (table readingTable, key interface{}, ok bool) {
+	keys := map[string]interface{}{}
+	if input.DeviceID != "" {
+		keys["device_id"] = input.DeviceID
+	}
+	if input.DeviceType != "" {
+		keys["device_type"] = input.DeviceType
+	}
+	if input.Location != "" {
+		keys["location"] = input.Location
+	}
+	if input.Zone > 0 {
+		keys["zone_id"] = input.Zone
+	}
+	if input.Floor > 0 {
+		keys["floor_id"] = input.Floor
+	}
+
+	for _, t := range tables {
+		if key, ok := keys[t.column]; ok {
+			return t, key, true
+		}
+	}
+	return readingTable{}, nil, false
+}
+
+// scanPartition reads the readings of a partition matching the input in page order,
+// starting after the cursor when there is one. day is the day of the partition of a
+// bucketed table. It stops after limit readings unless limit is 0.
+func (c *cassandraImpl) scanPartition(ctx context.Context, table readingTable, key interface{}, day time.Time,
+	input model.GetReadingsInput, after *readingCursor, limit int) ([]readingRow, error) {
+	var rows []readingRow
+	err := c.eachInPartition(ctx, table, key, day, input, after, limit, func(row readingRow) bool {
+		rows = append(rows, row)
+		return limit == 0 || len(rows) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// countPartition counts the readings of a partition matching the input without keeping them
+func (c *cassandraImpl) countPartition(ctx context.Context, table readingTable, key interface{}, day time.Time,
+	input model.GetReadingsInput) (int64, error) {
+	var count int64
+	err := c.eachInPartition(ctx, table, key, day, input, nil, 0, func(readingRow) bool {
+		count++
+		return true
+	})
+
+	return count, err
+}
+
+// eachInPartition calls visit with the readings of a partition matching the input in page
+// order, starting after the cursor when there is one, until it returns false. The query
+// fetches pageSize rows at a time, the driver's default when 0.
+func (c *cassandraImpl) eachInPartition(ctx context.Context, table readingTable, key interface{}, day time.Time,
+	input model.GetReadingsInput, after *readingCursor, pageSize int, visit func(readingRow) bool) error {
+	builder := qb.Select(table.name).
+		Columns(readingSelectColumns...).
+		Where(qb.Eq(table.column))
+	binds := qb.M{table.column: key}
+
+	if table.bucketed {
+		builder = builder.Where(qb.Eq("day_bucket"))
+		binds["day_bucket"] = day
+	}
+	if !input.StartTime.IsZero() {
+		builder = builder.Where(qb.GtOrEqNamed("timestamp", "start_time"))
+		binds["start_time"] = input.StartTime
+	}
+	// The cursor only bounds the timestamp, the readings of its timestamp left behind
+	// it are skipped below
+	if endTime := upperBound(input, after); !endTime.IsZero() {
+		builder = builder.Where(qb.LtOrEqNamed("timestamp", "end_time"))
+		binds["end_time"] = endTime
+	}
+
+	stmt, names := builder.ToCql()
+	q := c.session.Query(stmt, names).BindMap(binds).WithContext(ctx)
+	if pageSize > 0 {
+		q = q.PageSize(pageSize)
+	}
+	defer q.Release()
+
+	iter := q.Iter()
+	for {
+		var row readingRow
+		if !iter.StructScan(&row) {
+			break
+		}
+		if (after != nil && !after.precedes(row)) || !row.matches(input) {
+			continue
+		}
+		row.DayBucket = day
+		if !visit(row) {
+			break
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to query %s: %w", table.name, err)
+	}
+
+	return nil
+}
+
+// upperBound is the latest timestamp of the readings to read, zero when unbounded
+func upperBound(input model.GetReadingsInput, after *readingCursor) time.Time {
+	if after != nil && (input.EndTime.IsZero() || after.Timestamp.Before(input.EndTime)) {
+		return after.Timestamp
+	}
+	return input.EndTime
+}
+
+// maxMergedDevices bounds the devices an unfiltered read merges, it queries each of them for a whole page
+const maxMergedDevices = 500
+
+// readReadings reads the readings matching the input in page order, starting after the
+// cursor when there is one, and stops after limit readings unless limit is 0. A filtered
+// read goes to the partition key of its most selective filter, an unfiltered one to
+// every device and merges them.
+func (c *cassandraImpl) readReadings(ctx context.Context, input model.GetReadingsInput,
+	after *readingCursor, limit int) ([]readingRow, error) {
+	if table, key, ok := readingPartition(c.tables, input); ok {
+		return c.scanKey(ctx, table, key, input, after, limit)
+	}
+
+	deviceIDs, err := c.mergedDeviceIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rows []readingRow
+	for _, deviceID := range deviceIDs {
+		deviceRows, err := c.scanKey(ctx, c.tables[0], deviceID, input, after, limit)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, deviceRows...)
+	}
+	sortReadingRows(rows)
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	return rows, nil
+}
+
+// mergedDeviceIDs lists the devices an unfiltered read merges, failing with iotsystem.ErrTooManyDevices
+// past maxMergedDevices rather than querying all of them for every page
+func (c *cassandraImpl) mergedDeviceIDs(ctx context.Context) ([]string, error) {
+	deviceIDs, err := c.readingDeviceIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(deviceIDs) > maxMergedDevices {
+		return nil, fmt.Errorf("%w: %d devices have readings, at most %d are merged",
+			iotsystem.ErrTooManyDevices, len(deviceIDs), maxMergedDevices)
+	}
+
+	return deviceIDs, nil
+}
+
+// sortReadingRows sorts rows of different partitions in page order
+func sortReadingRows(rows []readingRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		return cursorOf(rows[i]).precedes(rows[j])
+	})
+}
@@ -0,0 +1,204 @@
+package casstopology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+func (c *cassandraImpl) GetFloors(ctx context.Context) ([]model.Floor, error) {
+	stmt, names := qb.Select("floors").
+		Columns(floorColumns...).
+		ToCql()
+
+	q := c.session.Query(stmt, names).WithContext(ctx)
+	defer q.Release()
+
+	var floors []model.Floor
+	iter := q.Iter()
+	var floor model.Floor
+	for iter.Scan(&floor.ID,
+		&floor.FloorNumber,
+		&floor.Description,
+		&floor.TotalArea,
+		&floor.CreatedAt,
+		&floor.UpdatedAt) {
+		floors = append(floors, floor)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query floors: %w", err)
+	}
+
+	// floors is partitioned by id so rows come back in token order
+	sort.Slice(floors, func(i, j int) bool { return floors[i].FloorNumber < floors[j].FloorNumber })
+
+	return floors, nil
+}
+
+func (c *cassandraImpl) GetFloor(ctx context.Context, floorID int) (model.Floor, error) {
+	stmt, names := qb.Select("floors").
+		Columns(floorColumns...).
+		Where(qb.Eq("id")).
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"id": floorID}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var floor model.Floor
+	err := q.Scan(&floor.ID,
+		&floor.FloorNumber,
+		&floor.Description,
+		&floor.TotalArea,
+		&floor.CreatedAt,
+		&floor.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return model.Floor{}, topology.ErrNotFound
+		}
+		return model.Floor{}, fmt.Errorf("failed to query floor %d: %w", floorID, err)
+	}
+
+	return floor, nil
+}
+
+func (c *cassandraImpl) CreateFloor(ctx context.Context, floor model.Floor) (model.Floor, error) {
+	if err := c.checkFloorNumber(ctx, floor); err != nil {
+		return model.Floor{}, err
+	}
+
+	now := time.Now()
+	floor.CreatedAt = now
+	floor.UpdatedAt = now
+
+	// There is no serial type in Cassandra, the id is allocated from id_sequences so
+	// IF NOT EXISTS only catches a row written without the sequence
+	id, err := c.nextID(ctx, "floors")
+	if err != nil {
+		return model.Floor{}, err
+	}
+	floor.ID = id
+
+	stmt, names := qb.Insert("floors").
+		Columns(floorColumns...).
+		Unique().
+		ToCql()
+
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional(fmt.Sprintf("floors:%d", floor.ID), errIDTaken, stmt, cassandra.BindMap(names, qb.M{
+			"id":           floor.ID,
+			"floor_number": floor.FloorNumber,
+			"description":  floor.Description,
+			"total_area":   floor.TotalArea,
+			"created_at":   floor.CreatedAt,
+			"updated_at":   floor.UpdatedAt,
+		})...)
+	})
+	if err != nil {
+		return model.Floor{}, fmt.Errorf("failed to insert floor %d: %w", floor.FloorNumber, err)
+	}
+
+	return floor, nil
+}
+
+// UpdateFloor returns the floor as it is once updated, which is before the update is
+// written in a DoInTx scope so it is read first
+func (c *cassandraImpl) UpdateFloor(ctx context.Context, floor model.Floor) (model.Floor, error) {
+	if err := c.checkFloorNumber(ctx, floor); err != nil {
+		return model.Floor{}, err
+	}
+
+	current, err := c.GetFloor(ctx, floor.ID)
+	if err != nil {
+		return model.Floor{}, err
+	}
+	current.FloorNumber = floor.FloorNumber
+	current.Description = floor.Description
+	current.TotalArea = floor.TotalArea
+	current.UpdatedAt = time.Now()
+
+	stmt, names := qb.Update("floors").
+		Set("floor_number", "description", "total_area", "updated_at").
+		Where(qb.Eq("id")).
+		Existing().
+		ToCql()
+
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional(fmt.Sprintf("floors:%d", floor.ID), topology.ErrNotFound, stmt, cassandra.BindMap(names, qb.M{
+			"id":           current.ID,
+			"floor_number": current.FloorNumber,
+			"description":  current.Description,
+			"total_area":   current.TotalArea,
+			"updated_at":   current.UpdatedAt,
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return model.Floor{}, err
+		}
+		return model.Floor{}, fmt.Errorf("failed to update floor %d: %w", floor.ID, err)
+	}
+
+	return current, nil
+}
+
+func (c *cassandraImpl) GetFloorDevices(ctx context.Context, floorID int) ([]model.IoTDevice, error) {
+	stmt, names := qb.Select("iot_devices").
+		Columns("device_id", "name", "type", "location", "floor_id", "zone_id",
+			"is_active", "created_at", "updated_at").
+		Where(qb.Eq("floor_id")).
+		AllowFiltering(). // iot_devices is partitioned by device_id
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"floor_id": floorID}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var devices []model.IoTDevice
+	iter := q.Iter()
+	var device model.IoTDevice
+	for iter.Scan(&device.DeviceID,
+		&device.Name,
+		&device.Type,
+		&device.Location,
+		&device.Floor,
+		&device.Zone,
+		&device.IsActive,
+		&device.CreatedAt,
+		&device.UpdatedAt) {
+		devices = append(devices, device)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query devices of floor %d: %w", floorID, err)
+	}
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].DeviceID < devices[j].DeviceID })
+
+	return devices, nil
+}
+
+// checkFloorNumber mirrors the postgres unique constraint on floor_number
+func (c *cassandraImpl) checkFloorNumber(ctx context.Context, floor model.Floor) error {
+	floors, err := c.GetFloors(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range floors {
+		if f.FloorNumber == floor.FloorNumber && f.ID != floor.ID {
+			return topology.ErrAlreadyExists
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package casstopology
+
+import (
+	"errors"
+
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+	"github.com/scylladb/gocqlx/v2"
+)
+
+// maxIDAttempts bounds the retries when concurrent writers allocate an id from the same sequence
+const maxIDAttempts = 5
+
+// errIDTaken is returned when the allocated id is taken, by a row written without the
+// sequence or by concurrent writers exhausting the attempts. Within a DoInTx scope the
+// insert is deferred and DoInTx returns it.
+var errIDTaken = errors.New("the generated id is taken")
+
+var (
+	floorColumns = []string{"id", "floor_number", "description", "total_area", "created_at", "updated_at"}
+	zoneColumns  = []string{"floor_id", "id", "zone_name", "zone_type", "description", "area", "created_at", "updated_at"}
+)
+
+type cassandraImpl struct {
+	session *gocqlx.Session
+}
+
+// NewCassandra returns a Cassandra implementation satisfying topology.Repository
+func NewCassandra(session gocqlx.Session) topology.Repository {
+	return &cassandraImpl{
+		session: &session,
+	}
+}
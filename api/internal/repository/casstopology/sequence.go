@@ -0,0 +1,106 @@
+package casstopology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gocql/gocql"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// nextID allocates the next id of the table from its row in id_sequences, the compare and set
+// of the row makes the id unique across concurrent writers
+func (c *cassandraImpl) nextID(ctx context.Context, table string) (int, error) {
+	stmt, names := qb.Update("id_sequences").
+		Set("next").
+		Where(qb.Eq("name")).
+		If(qb.EqNamed("next", "current")).
+		ToCql()
+
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		current, err := c.currentID(ctx, table)
+		if err != nil {
+			return 0, err
+		}
+
+		applied, err := c.session.Query(stmt, names).
+			BindMap(qb.M{"name": table, "next": current + 1, "current": current}).
+			WithContext(ctx).
+			ExecCASRelease()
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate id of %s: %w", table, err)
+		}
+		if applied {
+			return current, nil
+		}
+	}
+
+	return 0, fmt.Errorf("failed to allocate id of %s: %w after %d attempts", table, errIDTaken, maxIDAttempts)
+}
+
+// currentID returns the next free id of the table, starting its sequence after the highest
+// id of the table when it has none
+func (c *cassandraImpl) currentID(ctx context.Context, table string) (int, error) {
+	stmt, names := qb.Select("id_sequences").
+		Columns("next").
+		Where(qb.Eq("name")).
+		ToCql()
+
+	var next int
+	err := c.session.Query(stmt, names).
+		BindMap(qb.M{"name": table}).
+		WithContext(ctx).
+		GetRelease(&next)
+	if err == nil {
+		return next, nil
+	}
+	if !errors.Is(err, gocql.ErrNotFound) {
+		return 0, fmt.Errorf("failed to get id sequence of %s: %w", table, err)
+	}
+
+	start, err := c.maxID(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+
+	// a concurrent writer may have started it first, theirs is kept and read back
+	insert, insertNames := qb.Insert("id_sequences").
+		Columns("name", "next").
+		Unique().
+		ToCql()
+	if _, err := c.session.Query(insert, insertNames).
+		BindMap(qb.M{"name": table, "next": start + 1}).
+		WithContext(ctx).
+		ExecCASRelease(); err != nil {
+		return 0, fmt.Errorf("failed to start id sequence of %s: %w", table, err)
+	}
+
+	if err := c.session.Query(stmt, names).
+		BindMap(qb.M{"name": table}).
+		WithContext(ctx).
+		GetRelease(&next); err != nil {
+		return 0, fmt.Errorf("failed to get id sequence of %s: %w", table, err)
+	}
+
+	return next, nil
+}
+
+// maxID returns the highest id of the table, 0 when it is empty
+func (c *cassandraImpl) maxID(ctx context.Context, table string) (int, error) {
+	stmt, names := qb.Select(table).
+		Max("id").
+		ToCql()
+
+	var maxID *int
+	if err := c.session.Query(stmt, names).
+		WithContext(ctx).
+		GetRelease(&maxID); err != nil {
+		return 0, fmt.Errorf("failed to get max id of %s: %w", table, err)
+	}
+	if maxID == nil {
+		return 0, nil
+	}
+
+	return *maxID, nil
+}
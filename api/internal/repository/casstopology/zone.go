@@ -0,0 +1,177 @@
+package casstopology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+func (c *cassandraImpl) GetZones(ctx context.Context, floorID int) ([]model.Zone, error) {
+	stmt, names := qb.Select("zones").
+		Columns(zoneColumns...).
+		Where(qb.Eq("floor_id")).
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"floor_id": floorID}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var zones []model.Zone
+	iter := q.Iter()
+	var zone model.Zone
+	for iter.Scan(&zone.FloorID,
+		&zone.ID,
+		&zone.Name,
+		&zone.Type,
+		&zone.Description,
+		&zone.Area,
+		&zone.CreatedAt,
+		&zone.UpdatedAt) {
+		zones = append(zones, zone)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query zones of floor %d: %w", floorID, err)
+	}
+
+	return zones, nil
+}
+
+func (c *cassandraImpl) GetZone(ctx context.Context, floorID, zoneID int) (model.Zone, error) {
+	stmt, names := qb.Select("zones").
+		Columns(zoneColumns...).
+		Where(qb.Eq("floor_id"), qb.Eq("id")).
+		ToCql()
+
+	q := c.session.Query(stmt, names).
+		BindMap(qb.M{"floor_id": floorID, "id": zoneID}).
+		WithContext(ctx)
+	defer q.Release()
+
+	var zone model.Zone
+	err := q.Scan(&zone.FloorID,
+		&zone.ID,
+		&zone.Name,
+		&zone.Type,
+		&zone.Description,
+		&zone.Area,
+		&zone.CreatedAt,
+		&zone.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return model.Zone{}, topology.ErrNotFound
+		}
+		return model.Zone{}, fmt.Errorf("failed to query zone %d of floor %d: %w", zoneID, floorID, err)
+	}
+
+	return zone, nil
+}
+
+func (c *cassandraImpl) CreateZone(ctx context.Context, zone model.Zone) (model.Zone, error) {
+	if err := c.checkZoneName(ctx, zone); err != nil {
+		return model.Zone{}, err
+	}
+
+	now := time.Now()
+	zone.CreatedAt = now
+	zone.UpdatedAt = now
+
+	// zone ids are unique across floors like the postgres serial, they are allocated from
+	// id_sequences so IF NOT EXISTS only catches a row written without the sequence
+	id, err := c.nextID(ctx, "zones")
+	if err != nil {
+		return model.Zone{}, err
+	}
+	zone.ID = id
+
+	stmt, names := qb.Insert("zones").
+		Columns(zoneColumns...).
+		Unique().
+		ToCql()
+
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional(fmt.Sprintf("zones:%d", zone.FloorID), errIDTaken, stmt, cassandra.BindMap(names, qb.M{
+			"floor_id":    zone.FloorID,
+			"id":          zone.ID,
+			"zone_name":   zone.Name,
+			"zone_type":   zone.Type,
+			"description": zone.Description,
+			"area":        zone.Area,
+			"created_at":  zone.CreatedAt,
+			"updated_at":  zone.UpdatedAt,
+		})...)
+	})
+	if err != nil {
+		return model.Zone{}, fmt.Errorf("failed to insert zone %s of floor %d: %w", zone.Name, zone.FloorID, err)
+	}
+
+	return zone, nil
+}
+
+// UpdateZone returns the zone as it is once updated, which is before the update is
+// written in a DoInTx scope so it is read first
+func (c *cassandraImpl) UpdateZone(ctx context.Context, zone model.Zone) (model.Zone, error) {
+	if err := c.checkZoneName(ctx, zone); err != nil {
+		return model.Zone{}, err
+	}
+
+	current, err := c.GetZone(ctx, zone.FloorID, zone.ID)
+	if err != nil {
+		return model.Zone{}, err
+	}
+	current.Name = zone.Name
+	current.Type = zone.Type
+	current.Description = zone.Description
+	current.Area = zone.Area
+	current.UpdatedAt = time.Now()
+
+	stmt, names := qb.Update("zones").
+		Set("zone_name", "zone_type", "description", "area", "updated_at").
+		Where(qb.Eq("floor_id"), qb.Eq("id")).
+		Existing().
+		ToCql()
+
+	// zones is partitioned by floor, the zones of a floor share a batch
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional(fmt.Sprintf("zones:%d", zone.FloorID), topology.ErrNotFound, stmt, cassandra.BindMap(names, qb.M{
+			"floor_id":    current.FloorID,
+			"id":          current.ID,
+			"zone_name":   current.Name,
+			"zone_type":   current.Type,
+			"description": current.Description,
+			"area":        current.Area,
+			"updated_at":  current.UpdatedAt,
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, topology.ErrNotFound) {
+			return model.Zone{}, err
+		}
+		return model.Zone{}, fmt.Errorf("failed to update zone %d of floor %d: %w", zone.ID, zone.FloorID, err)
+	}
+
+	return current, nil
+}
+
+// checkZoneName mirrors the postgres unique constraint on (floor_id, zone_name)
+func (c *cassandraImpl) checkZoneName(ctx context.Context, zone model.Zone) error {
+	zones, err := c.GetZones(ctx, zone.FloorID)
+	if err != nil {
+		return err
+	}
+
+	for _, z := range zones {
+		if z.Name == zone.Name && z.ID != zone.ID {
+			return topology.ErrAlreadyExists
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package cassuser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/generator"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/user"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// Create stores a user, the email must be unique
+func (c *cassandraImpl) Create(ctx context.Context, u *model.User) error {
+	id, err := generator.UserIDSNF.Generate()
+	if err != nil {
+		return err
+	}
+
+	// email is the partition key, IF NOT EXISTS plays the unique constraint of postgres
+	stmt, names := qb.Insert("users").
+		Columns(userColumns...).
+		Unique().
+		ToCql()
+
+	err = cassandra.Write(ctx, c.session.Session, func(b *cassandra.Batch) {
+		b.Conditional("users:"+u.Email, user.ErrAlreadyExists, stmt, cassandra.BindStruct(names, userRow{
+			Email:        u.Email,
+			ID:           id,
+			Username:     u.Name,
+			PasswordHash: u.Password,
+			CreatedAt:    time.Now(),
+		})...)
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrAlreadyExists) {
+			return err
+		}
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	u.ID = id
+
+	return nil
+}
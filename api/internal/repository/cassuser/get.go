@@ -0,0 +1,40 @@
+package cassuser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/user"
+	"github.com/scylladb/gocqlx/v2/qb"
+)
+
+// GetByEmail retrieves a user by email, the email is looked up in lower case like on postgres
+func (c *cassandraImpl) GetByEmail(ctx context.Context, inp user.GetUserInput) (model.User, error) {
+	stmt, names := qb.Select("users").
+		Columns(userColumns...).
+		Where(qb.Eq("email")).
+		ToCql()
+
+	var row userRow
+	err := c.session.Query(stmt, names).
+		BindMap(qb.M{"email": strings.ToLower(inp.Email)}).
+		WithContext(ctx).
+		GetRelease(&row)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return model.User{}, user.ErrNotFound
+		}
+		return model.User{}, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	return model.User{
+		ID:       row.ID,
+		Name:     row.Username,
+		Email:    row.Email,
+		Password: row.PasswordHash,
+	}, nil
+}
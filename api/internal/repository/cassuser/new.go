@@ -0,0 +1,30 @@
+package cassuser
+
+import (
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/repository/user"
+	"github.com/scylladb/gocqlx/v2"
+)
+
+var userColumns = []string{"email", "id", "username", "password_hash", "created_at"}
+
+// userRow is a row of the users table
+type userRow struct {
+	Email        string    `db:"email"`
+	ID           int64     `db:"id"`
+	Username     string    `db:"username"`
+	PasswordHash string    `db:"password_hash"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type cassandraImpl struct {
+	session *gocqlx.Session
+}
+
+// NewCassandra returns a Cassandra implementation satisfying user.Repository
+func NewCassandra(session gocqlx.Session) user.Repository {
+	return &cassandraImpl{
+		session: &session,
+	}
+}
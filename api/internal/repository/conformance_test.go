@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/cassiot"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/generator"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/repositorytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	// The cassandra repos generate ids like at startup
+	require.NoError(t, generator.InitSnowflakeGenerators())
+
+	tcs := map[string]struct {
+		givenBackend repository.Backend
+		// givenEnv points to a scratch database, the backend is skipped when it is unset
+		givenEnv  string
+		configure func(cfg *repository.Config, env string)
+	}{
+		"memory": {
+			givenBackend: repository.BackendMemory,
+		},
+		"postgres": {
+			givenBackend: repository.BackendPostgres,
+			givenEnv:     "CONFORMANCE_PG_URL",
+			configure:    func(cfg *repository.Config, env string) { cfg.PGURL = env },
+		},
+		"cassandra": {
+			givenBackend: repository.BackendCassandra,
+			givenEnv:     "CONFORMANCE_CASSANDRA_HOSTS",
+			configure: func(cfg *repository.Config, env string) {
+				cfg.CassHosts = strings.Split(env, ",")
+				cfg.CassReadingsLayout = cassiot.LayoutDevice
+			},
+		},
+		"cassandra_day_layout": {
+			givenBackend: repository.BackendCassandra,
+			givenEnv:     "CONFORMANCE_CASSANDRA_HOSTS",
+			configure: func(cfg *repository.Config, env string) {
+				cfg.CassHosts = strings.Split(env, ",")
+				cfg.CassReadingsLayout = cassiot.LayoutDay
+			},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			cfg := repository.FromEnv()
+			cfg.Backend = tc.givenBackend
+			cfg.MemorySeed = true
+			if tc.givenEnv != "" {
+				env := os.Getenv(tc.givenEnv)
+				if env == "" {
+					t.Skipf("%s is not set", tc.givenEnv)
+				}
+				tc.configure(&cfg, env)
+			}
+			repo, cleanup, err := repository.NewFromConfig(context.Background(), cfg)
+			require.NoError(t, err)
+			t.Cleanup(cleanup)
+
+			// When/Then:
+			repositorytest.Run(t, func(*testing.T) repository.Registry { return repo })
+		})
+	}
+}
@@ -0,0 +1,1599 @@
+// Code generated by SQLBoiler 4.19.5 (https://github.com/aarondl/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package dbmodel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/aarondl/sqlboiler/v4/queries/qmhelper"
+	"github.com/aarondl/strmangle"
+	"github.com/friendsofgo/errors"
+)
+
+// AlertRule is an object representing the database table.
+type AlertRule struct {
+	ID              int64     `boil:"id" json:"id" toml:"id" yaml:"id"`
+	Name            string    `boil:"name" json:"name" toml:"name" yaml:"name"`
+	DeviceID        string    `boil:"device_id" json:"device_id" toml:"device_id" yaml:"device_id"`
+	DeviceType      string    `boil:"device_type" json:"device_type" toml:"device_type" yaml:"device_type"`
+	FloorID         null.Int  `boil:"floor_id" json:"floor_id,omitempty" toml:"floor_id" yaml:"floor_id,omitempty"`
+	ZoneID          null.Int  `boil:"zone_id" json:"zone_id,omitempty" toml:"zone_id" yaml:"zone_id,omitempty"`
+	Metric          string    `boil:"metric" json:"metric" toml:"metric" yaml:"metric"`
+	Operator        string    `boil:"operator" json:"operator" toml:"operator" yaml:"operator"`
+	Threshold       float64   `boil:"threshold" json:"threshold" toml:"threshold" yaml:"threshold"`
+	DurationSeconds int       `boil:"duration_seconds" json:"duration_seconds" toml:"duration_seconds" yaml:"duration_seconds"`
+	Enabled         bool      `boil:"enabled" json:"enabled" toml:"enabled" yaml:"enabled"`
+	CreatedAt       null.Time `boil:"created_at" json:"created_at,omitempty" toml:"created_at" yaml:"created_at,omitempty"`
+	UpdatedAt       null.Time `boil:"updated_at" json:"updated_at,omitempty" toml:"updated_at" yaml:"updated_at,omitempty"`
+
+	R *alertRuleR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L alertRuleL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var AlertRuleColumns = struct {
+	ID              string
+	Name            string
+	DeviceID        string
+	DeviceType      string
+	FloorID         string
+	ZoneID          string
+	Metric          string
+	Operator        string
+	Threshold       string
+	DurationSeconds string
+	Enabled         string
+	CreatedAt       string
+	UpdatedAt       string
+}{
+	ID:              "id",
+	Name:            "name",
+	DeviceID:        "device_id",
+	DeviceType:      "device_type",
+	FloorID:         "floor_id",
+	ZoneID:          "zone_id",
+	Metric:          "metric",
+	Operator:        "operator",
+	Threshold:       "threshold",
+	DurationSeconds: "duration_seconds",
+	Enabled:         "enabled",
+	CreatedAt:       "created_at",
+	UpdatedAt:       "updated_at",
+}
+
+var AlertRuleTableColumns = struct {
+	ID              string
+	Name            string
+	DeviceID        string
+	DeviceType      string
+	FloorID         string
+	ZoneID          string
+	Metric          string
+	Operator        string
+	Threshold       string
+	DurationSeconds string
+	Enabled         string
+	CreatedAt       string
+	UpdatedAt       string
+}{
+	ID:              "alert_rules.id",
+	Name:            "alert_rules.name",
+	DeviceID:        "alert_rules.device_id",
+	DeviceType:      "alert_rules.device_type",
+	FloorID:         "alert_rules.floor_id",
+	ZoneID:          "alert_rules.zone_id",
+	Metric:          "alert_rules.metric",
+	Operator:        "alert_rules.operator",
+	Threshold:       "alert_rules.threshold",
+	DurationSeconds: "alert_rules.duration_seconds",
+	Enabled:         "alert_rules.enabled",
+	CreatedAt:       "alert_rules.created_at",
+	UpdatedAt:       "alert_rules.updated_at",
+}
+
+// Generated where
+
+type whereHelperint64 struct{ field string }
+
+func (w whereHelperint64) EQ(x int64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperint64) NEQ(x int64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
+func (w whereHelperint64) LT(x int64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperint64) LTE(x int64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LTE, x) }
+func (w whereHelperint64) GT(x int64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperint64) GTE(x int64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GTE, x) }
+func (w whereHelperint64) IN(slice []int64) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelperint64) NIN(slice []int64) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+type whereHelperstring struct{ field string }
+
+func (w whereHelperstring) EQ(x string) qm.QueryMod      { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperstring) NEQ(x string) qm.QueryMod     { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
+func (w whereHelperstring) LT(x string) qm.QueryMod      { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperstring) LTE(x string) qm.QueryMod     { return qmhelper.Where(w.field, qmhelper.LTE, x) }
+func (w whereHelperstring) GT(x string) qm.QueryMod      { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperstring) GTE(x string) qm.QueryMod     { return qmhelper.Where(w.field, qmhelper.GTE, x) }
+func (w whereHelperstring) LIKE(x string) qm.QueryMod    { return qm.Where(w.field+" LIKE ?", x) }
+func (w whereHelperstring) NLIKE(x string) qm.QueryMod   { return qm.Where(w.field+" NOT LIKE ?", x) }
+func (w whereHelperstring) ILIKE(x string) qm.QueryMod   { return qm.Where(w.field+" ILIKE ?", x) }
+func (w whereHelperstring) NILIKE(x string) qm.QueryMod  { return qm.Where(w.field+" NOT ILIKE ?", x) }
+func (w whereHelperstring) SIMILAR(x string) qm.QueryMod { return qm.Where(w.field+" SIMILAR TO ?", x) }
+func (w whereHelperstring) NSIMILAR(x string) qm.QueryMod {
+	return qm.Where(w.field+" NOT SIMILAR TO ?", x)
+}
+func (w whereHelperstring) IN(slice []string) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelperstring) NIN(slice []string) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+type whereHelpernull_Int struct{ field string }
+
+func (w whereHelpernull_Int) EQ(x null.Int) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, false, x)
+}
+func (w whereHelpernull_Int) NEQ(x null.Int) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, true, x)
+}
+func (w whereHelpernull_Int) LT(x null.Int) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpernull_Int) LTE(x null.Int) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelpernull_Int) GT(x null.Int) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpernull_Int) GTE(x null.Int) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+func (w whereHelpernull_Int) IN(slice []int) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelpernull_Int) NIN(slice []int) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+func (w whereHelpernull_Int) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_Int) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
+
+type whereHelperfloat64 struct{ field string }
+
+func (w whereHelperfloat64) EQ(x float64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperfloat64) NEQ(x float64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.NEQ, x)
+}
+func (w whereHelperfloat64) LT(x float64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperfloat64) LTE(x float64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelperfloat64) GT(x float64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperfloat64) GTE(x float64) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+func (w whereHelperfloat64) IN(slice []float64) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelperfloat64) NIN(slice []float64) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+type whereHelperint struct{ field string }
+
+func (w whereHelperint) EQ(x int) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperint) NEQ(x int) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
+func (w whereHelperint) LT(x int) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperint) LTE(x int) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LTE, x) }
+func (w whereHelperint) GT(x int) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperint) GTE(x int) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GTE, x) }
+func (w whereHelperint) IN(slice []int) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
+}
+func (w whereHelperint) NIN(slice []int) qm.QueryMod {
+	values := make([]interface{}, 0, len(slice))
+	for _, value := range slice {
+		values = append(values, value)
+	}
+	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
+}
+
+type whereHelperbool struct{ field string }
+
+func (w whereHelperbool) EQ(x bool) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperbool) NEQ(x bool) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
+func (w whereHelperbool) LT(x bool) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperbool) LTE(x bool) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LTE, x) }
+func (w whereHelperbool) GT(x bool) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperbool) GTE(x bool) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GTE, x) }
+
+type whereHelpernull_Time struct{ field string }
+
+func (w whereHelpernull_Time) EQ(x null.Time) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, false, x)
+}
+func (w whereHelpernull_Time) NEQ(x null.Time) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, true, x)
+}
+func (w whereHelpernull_Time) LT(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpernull_Time) LTE(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelpernull_Time) GT(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpernull_Time) GTE(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+
+func (w whereHelpernull_Time) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_Time) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
+
+var AlertRuleWhere = struct {
+	ID              whereHelperint64
+	Name            whereHelperstring
+	DeviceID        whereHelperstring
+	DeviceType      whereHelperstring
+	FloorID         whereHelpernull_Int
+	ZoneID          whereHelpernull_Int
+	Metric          whereHelperstring
+	Operator        whereHelperstring
+	Threshold       whereHelperfloat64
+	DurationSeconds whereHelperint
+	Enabled         whereHelperbool
+	CreatedAt       whereHelpernull_Time
+	UpdatedAt       whereHelpernull_Time
+}{
+	ID:              whereHelperint64{field: "\"alert_rules\".\"id\""},
+	Name:            whereHelperstring{field: "\"alert_rules\".\"name\""},
+	DeviceID:        whereHelperstring{field: "\"alert_rules\".\"device_id\""},
+	DeviceType:      whereHelperstring{field: "\"alert_rules\".\"device_type\""},
+	FloorID:         whereHelpernull_Int{field: "\"alert_rules\".\"floor_id\""},
+	ZoneID:          whereHelpernull_Int{field: "\"alert_rules\".\"zone_id\""},
+	Metric:          whereHelperstring{field: "\"alert_rules\".\"metric\""},
+	Operator:        whereHelperstring{field: "\"alert_rules\".\"operator\""},
+	Threshold:       whereHelperfloat64{field: "\"alert_rules\".\"threshold\""},
+	DurationSeconds: whereHelperint{field: "\"alert_rules\".\"duration_seconds\""},
+	Enabled:         whereHelperbool{field: "\"alert_rules\".\"enabled\""},
+	CreatedAt:       whereHelpernull_Time{field: "\"alert_rules\".\"created_at\""},
+	UpdatedAt:       whereHelpernull_Time{field: "\"alert_rules\".\"updated_at\""},
+}
+
+// AlertRuleRels is where relationship names are stored.
+var AlertRuleRels = struct {
+	Floor      string
+	Zone       string
+	RuleAlerts string
+}{
+	Floor:      "Floor",
+	Zone:       "Zone",
+	RuleAlerts: "RuleAlerts",
+}
+
+// alertRuleR is where relationships are stored.
+type alertRuleR struct {
+	Floor      *Floor     `boil:"Floor" json:"Floor" toml:"Floor" yaml:"Floor"`
+	Zone       *Zone      `boil:"Zone" json:"Zone" toml:"Zone" yaml:"Zone"`
+	RuleAlerts AlertSlice `boil:"RuleAlerts" json:"RuleAlerts" toml:"RuleAlerts" yaml:"RuleAlerts"`
+}
+
+// NewStruct creates a new relationship struct
+func (*alertRuleR) NewStruct() *alertRuleR {
+	return &alertRuleR{}
+}
+
+func (o *AlertRule) GetFloor() *Floor {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetFloor()
+}
+
+func (r *alertRuleR) GetFloor() *Floor {
+	if r == nil {
+		return nil
+	}
+
+	return r.Floor
+}
+
+func (o *AlertRule) GetZone() *Zone {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetZone()
+}
+
+func (r *alertRuleR) GetZone() *Zone {
+	if r == nil {
+		return nil
+	}
+
+	return r.Zone
+}
+
+func (o *AlertRule) GetRuleAlerts() AlertSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetRuleAlerts()
+}
+
+func (r *alertRuleR) GetRuleAlerts() AlertSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.RuleAlerts
+}
+
+// alertRuleL is where Load methods for each relationship are stored.
+type alertRuleL struct{}
+
+var (
+	alertRuleAllColumns            = []string{"id", "name", "device_id", "device_type", "floor_id", "zone_id", "metric", "operator", "threshold", "duration_seconds", "enabled", "created_at", "updated_at"}
+	alertRuleColumnsWithoutDefault = []string{"id", "name", "metric", "operator", "threshold"}
+	alertRuleColumnsWithDefault    = []string{"device_id", "device_type", "floor_id", "zone_id", "duration_seconds", "enabled", "created_at", "updated_at"}
+	alertRulePrimaryKeyColumns     = []string{"id"}
+	alertRuleGeneratedColumns      = []string{}
+)
+
+type (
+	// AlertRuleSlice is an alias for a slice of pointers to AlertRule.
+	// This should almost always be used instead of []AlertRule.
+	AlertRuleSlice []*AlertRule
+
+	alertRuleQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	alertRuleType                 = reflect.TypeOf(&AlertRule{})
+	alertRuleMapping              = queries.MakeStructMapping(alertRuleType)
+	alertRulePrimaryKeyMapping, _ = queries.BindMapping(alertRuleType, alertRuleMapping, alertRulePrimaryKeyColumns)
+	alertRuleInsertCacheMut       sync.RWMutex
+	alertRuleInsertCache          = make(map[string]insertCache)
+	alertRuleUpdateCacheMut       sync.RWMutex
+	alertRuleUpdateCache          = make(map[string]updateCache)
+	alertRuleUpsertCacheMut       sync.RWMutex
+	alertRuleUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+// One returns a single alertRule record from the query.
+func (q alertRuleQuery) One(ctx context.Context, exec boil.ContextExecutor) (*AlertRule, error) {
+	o := &AlertRule{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "dbmodel: failed to execute a one query for alert_rules")
+	}
+
+	return o, nil
+}
+
+// All returns all AlertRule records from the query.
+func (q alertRuleQuery) All(ctx context.Context, exec boil.ContextExecutor) (AlertRuleSlice, error) {
+	var o []*AlertRule
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "dbmodel: failed to assign all query results to AlertRule slice")
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all AlertRule records in the query.
+func (q alertRuleQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to count alert_rules rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q alertRuleQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "dbmodel: failed to check if alert_rules exists")
+	}
+
+	return count > 0, nil
+}
+
+// Floor pointed to by the foreign key.
+func (o *AlertRule) Floor(mods ...qm.QueryMod) floorQuery {
+	queryMods := []qm.QueryMod{
+		qm.Where("\"id\" = ?", o.FloorID),
+	}
+
+	queryMods = append(queryMods, mods...)
+
+	return Floors(queryMods...)
+}
+
+// Zone pointed to by the foreign key.
+func (o *AlertRule) Zone(mods ...qm.QueryMod) zoneQuery {
+	queryMods := []qm.QueryMod{
+		qm.Where("\"id\" = ?", o.ZoneID),
+	}
+
+	queryMods = append(queryMods, mods...)
+
+	return Zones(queryMods...)
+}
+
+// RuleAlerts retrieves all the alert's Alerts with an executor via rule_id column.
+func (o *AlertRule) RuleAlerts(mods ...qm.QueryMod) alertQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"alerts\".\"rule_id\"=?", o.ID),
+	)
+
+	return Alerts(queryMods...)
+}
+
+// LoadFloor allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for an N-1 relationship.
+func (alertRuleL) LoadFloor(ctx context.Context, e boil.ContextExecutor, singular bool, maybeAlertRule interface{}, mods queries.Applicator) error {
+	var slice []*AlertRule
+	var object *AlertRule
+
+	if singular {
+		var ok bool
+		object, ok = maybeAlertRule.(*AlertRule)
+		if !ok {
+			object = new(AlertRule)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeAlertRule)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeAlertRule))
+			}
+		}
+	} else {
+		s, ok := maybeAlertRule.(*[]*AlertRule)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeAlertRule)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeAlertRule))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &alertRuleR{}
+		}
+		if !queries.IsNil(object.FloorID) {
+			args[object.FloorID] = struct{}{}
+		}
+
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &alertRuleR{}
+			}
+
+			if !queries.IsNil(obj.FloorID) {
+				args[obj.FloorID] = struct{}{}
+			}
+
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`floors`),
+		qm.WhereIn(`floors.id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load Floor")
+	}
+
+	var resultSlice []*Floor
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice Floor")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results of eager load for floors")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for floors")
+	}
+
+	if len(resultSlice) == 0 {
+		return nil
+	}
+
+	if singular {
+		foreign := resultSlice[0]
+		object.R.Floor = foreign
+		if foreign.R == nil {
+			foreign.R = &floorR{}
+		}
+		foreign.R.AlertRules = append(foreign.R.AlertRules, object)
+		return nil
+	}
+
+	for _, local := range slice {
+		for _, foreign := range resultSlice {
+			if queries.Equal(local.FloorID, foreign.ID) {
+				local.R.Floor = foreign
+				if foreign.R == nil {
+					foreign.R = &floorR{}
+				}
+				foreign.R.AlertRules = append(foreign.R.AlertRules, local)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadZone allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for an N-1 relationship.
+func (alertRuleL) LoadZone(ctx context.Context, e boil.ContextExecutor, singular bool, maybeAlertRule interface{}, mods queries.Applicator) error {
+	var slice []*AlertRule
+	var object *AlertRule
+
+	if singular {
+		var ok bool
+		object, ok = maybeAlertRule.(*AlertRule)
+		if !ok {
+			object = new(AlertRule)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeAlertRule)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeAlertRule))
+			}
+		}
+	} else {
+		s, ok := maybeAlertRule.(*[]*AlertRule)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeAlertRule)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeAlertRule))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &alertRuleR{}
+		}
+		if !queries.IsNil(object.ZoneID) {
+			args[object.ZoneID] = struct{}{}
+		}
+
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &alertRuleR{}
+			}
+
+			if !queries.IsNil(obj.ZoneID) {
+				args[obj.ZoneID] = struct{}{}
+			}
+
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`zones`),
+		qm.WhereIn(`zones.id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load Zone")
+	}
+
+	var resultSlice []*Zone
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice Zone")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results of eager load for zones")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for zones")
+	}
+
+	if len(resultSlice) == 0 {
+		return nil
+	}
+
+	if singular {
+		foreign := resultSlice[0]
+		object.R.Zone = foreign
+		if foreign.R == nil {
+			foreign.R = &zoneR{}
+		}
+		foreign.R.AlertRules = append(foreign.R.AlertRules, object)
+		return nil
+	}
+
+	for _, local := range slice {
+		for _, foreign := range resultSlice {
+			if queries.Equal(local.ZoneID, foreign.ID) {
+				local.R.Zone = foreign
+				if foreign.R == nil {
+					foreign.R = &zoneR{}
+				}
+				foreign.R.AlertRules = append(foreign.R.AlertRules, local)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadRuleAlerts allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (alertRuleL) LoadRuleAlerts(ctx context.Context, e boil.ContextExecutor, singular bool, maybeAlertRule interface{}, mods queries.Applicator) error {
+	var slice []*AlertRule
+	var object *AlertRule
+
+	if singular {
+		var ok bool
+		object, ok = maybeAlertRule.(*AlertRule)
+		if !ok {
+			object = new(AlertRule)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeAlertRule)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeAlertRule))
+			}
+		}
+	} else {
+		s, ok := maybeAlertRule.(*[]*AlertRule)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeAlertRule)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeAlertRule))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &alertRuleR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &alertRuleR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`alerts`),
+		qm.WhereIn(`alerts.rule_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load alerts")
+	}
+
+	var resultSlice []*Alert
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice alerts")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on alerts")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for alerts")
+	}
+
+	if singular {
+		object.R.RuleAlerts = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &alertR{}
+			}
+			foreign.R.Rule = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if local.ID == foreign.RuleID {
+				local.R.RuleAlerts = append(local.R.RuleAlerts, foreign)
+				if foreign.R == nil {
+					foreign.R = &alertR{}
+				}
+				foreign.R.Rule = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetFloor of the alertRule to the related item.
+// Sets o.R.Floor to related.
+// Adds o to related.R.AlertRules.
+func (o *AlertRule) SetFloor(ctx context.Context, exec boil.ContextExecutor, insert bool, related *Floor) error {
+	var err error
+	if insert {
+		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
+			return errors.Wrap(err, "failed to insert into foreign table")
+		}
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE \"alert_rules\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, []string{"floor_id"}),
+		strmangle.WhereClause("\"", "\"", 2, alertRulePrimaryKeyColumns),
+	)
+	values := []interface{}{related.ID, o.ID}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, updateQuery)
+		fmt.Fprintln(writer, values)
+	}
+	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	queries.Assign(&o.FloorID, related.ID)
+	if o.R == nil {
+		o.R = &alertRuleR{
+			Floor: related,
+		}
+	} else {
+		o.R.Floor = related
+	}
+
+	if related.R == nil {
+		related.R = &floorR{
+			AlertRules: AlertRuleSlice{o},
+		}
+	} else {
+		related.R.AlertRules = append(related.R.AlertRules, o)
+	}
+
+	return nil
+}
+
+// RemoveFloor relationship.
+// Sets o.R.Floor to nil.
+// Removes o from all passed in related items' relationships struct.
+func (o *AlertRule) RemoveFloor(ctx context.Context, exec boil.ContextExecutor, related *Floor) error {
+	var err error
+
+	queries.SetScanner(&o.FloorID, nil)
+	if _, err = o.Update(ctx, exec, boil.Whitelist("floor_id")); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	if o.R != nil {
+		o.R.Floor = nil
+	}
+	if related == nil || related.R == nil {
+		return nil
+	}
+
+	for i, ri := range related.R.AlertRules {
+		if queries.Equal(o.FloorID, ri.FloorID) {
+			continue
+		}
+
+		ln := len(related.R.AlertRules)
+		if ln > 1 && i < ln-1 {
+			related.R.AlertRules[i] = related.R.AlertRules[ln-1]
+		}
+		related.R.AlertRules = related.R.AlertRules[:ln-1]
+		break
+	}
+	return nil
+}
+
+// SetZone of the alertRule to the related item.
+// Sets o.R.Zone to related.
+// Adds o to related.R.AlertRules.
+func (o *AlertRule) SetZone(ctx context.Context, exec boil.ContextExecutor, insert bool, related *Zone) error {
+	var err error
+	if insert {
+		if err = related.Insert(ctx, exec, boil.Infer()); err != nil {
+			return errors.Wrap(err, "failed to insert into foreign table")
+		}
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE \"alert_rules\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, []string{"zone_id"}),
+		strmangle.WhereClause("\"", "\"", 2, alertRulePrimaryKeyColumns),
+	)
+	values := []interface{}{related.ID, o.ID}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, updateQuery)
+		fmt.Fprintln(writer, values)
+	}
+	if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	queries.Assign(&o.ZoneID, related.ID)
+	if o.R == nil {
+		o.R = &alertRuleR{
+			Zone: related,
+		}
+	} else {
+		o.R.Zone = related
+	}
+
+	if related.R == nil {
+		related.R = &zoneR{
+			AlertRules: AlertRuleSlice{o},
+		}
+	} else {
+		related.R.AlertRules = append(related.R.AlertRules, o)
+	}
+
+	return nil
+}
+
+// RemoveZone relationship.
+// Sets o.R.Zone to nil.
+// Removes o from all passed in related items' relationships struct.
+func (o *AlertRule) RemoveZone(ctx context.Context, exec boil.ContextExecutor, related *Zone) error {
+	var err error
+
+	queries.SetScanner(&o.ZoneID, nil)
+	if _, err = o.Update(ctx, exec, boil.Whitelist("zone_id")); err != nil {
+		return errors.Wrap(err, "failed to update local table")
+	}
+
+	if o.R != nil {
+		o.R.Zone = nil
+	}
+	if related == nil || related.R == nil {
+		return nil
+	}
+
+	for i, ri := range related.R.AlertRules {
+		if queries.Equal(o.ZoneID, ri.ZoneID) {
+			continue
+		}
+
+		ln := len(related.R.AlertRules)
+		if ln > 1 && i < ln-1 {
+			related.R.AlertRules[i] = related.R.AlertRules[ln-1]
+		}
+		related.R.AlertRules = related.R.AlertRules[:ln-1]
+		break
+	}
+	return nil
+}
+
+// AddRuleAlerts adds the given related objects to the existing relationships
+// of the alert_rule, optionally inserting them as new records.
+// Appends related to o.R.RuleAlerts.
+// Sets related.R.Rule appropriately.
+func (o *AlertRule) AddRuleAlerts(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*Alert) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			rel.RuleID = o.ID
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"alerts\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 1, []string{"rule_id"}),
+				strmangle.WhereClause("\"", "\"", 2, alertPrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			rel.RuleID = o.ID
+		}
+	}
+
+	if o.R == nil {
+		o.R = &alertRuleR{
+			RuleAlerts: related,
+		}
+	} else {
+		o.R.RuleAlerts = append(o.R.RuleAlerts, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &alertR{
+				Rule: o,
+			}
+		} else {
+			rel.R.Rule = o
+		}
+	}
+	return nil
+}
+
+// AlertRules retrieves all the records using an executor.
+func AlertRules(mods ...qm.QueryMod) alertRuleQuery {
+	mods = append(mods, qm.From("\"alert_rules\""))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"\"alert_rules\".*"})
+	}
+
+	return alertRuleQuery{q}
+}
+
+// FindAlertRule retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindAlertRule(ctx context.Context, exec boil.ContextExecutor, iD int64, selectCols ...string) (*AlertRule, error) {
+	alertRuleObj := &AlertRule{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"alert_rules\" where \"id\"=$1", sel,
+	)
+
+	q := queries.Raw(query, iD)
+
+	err := q.Bind(ctx, exec, alertRuleObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "dbmodel: unable to select from alert_rules")
+	}
+
+	return alertRuleObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *AlertRule) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("dbmodel: no alert_rules provided for insertion")
+	}
+
+	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if queries.MustTime(o.CreatedAt).IsZero() {
+			queries.SetScanner(&o.CreatedAt, currTime)
+		}
+		if queries.MustTime(o.UpdatedAt).IsZero() {
+			queries.SetScanner(&o.UpdatedAt, currTime)
+		}
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(alertRuleColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	alertRuleInsertCacheMut.RLock()
+	cache, cached := alertRuleInsertCache[key]
+	alertRuleInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			alertRuleAllColumns,
+			alertRuleColumnsWithDefault,
+			alertRuleColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(alertRuleType, alertRuleMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(alertRuleType, alertRuleMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"alert_rules\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"alert_rules\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "dbmodel: unable to insert into alert_rules")
+	}
+
+	if !cached {
+		alertRuleInsertCacheMut.Lock()
+		alertRuleInsertCache[key] = cache
+		alertRuleInsertCacheMut.Unlock()
+	}
+
+	return nil
+}
+
+// Update uses an executor to update the AlertRule.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *AlertRule) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		queries.SetScanner(&o.UpdatedAt, currTime)
+	}
+
+	var err error
+	key := makeCacheKey(columns, nil)
+	alertRuleUpdateCacheMut.RLock()
+	cache, cached := alertRuleUpdateCache[key]
+	alertRuleUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			alertRuleAllColumns,
+			alertRulePrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("dbmodel: unable to update alert_rules, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"alert_rules\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 1, wl),
+			strmangle.WhereClause("\"", "\"", len(wl)+1, alertRulePrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(alertRuleType, alertRuleMapping, append(wl, alertRulePrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to update alert_rules row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by update for alert_rules")
+	}
+
+	if !cached {
+		alertRuleUpdateCacheMut.Lock()
+		alertRuleUpdateCache[key] = cache
+		alertRuleUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q alertRuleQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to update all for alert_rules")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to retrieve rows affected for alert_rules")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o AlertRuleSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("dbmodel: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), alertRulePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"alert_rules\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), len(colNames)+1, alertRulePrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to update all in alertRule slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to retrieve rows affected all in update all alertRule")
+	}
+	return rowsAff, nil
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *AlertRule) Upsert(ctx context.Context, exec boil.ContextExecutor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns, opts ...UpsertOptionFunc) error {
+	if o == nil {
+		return errors.New("dbmodel: no alert_rules provided for upsert")
+	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if queries.MustTime(o.CreatedAt).IsZero() {
+			queries.SetScanner(&o.CreatedAt, currTime)
+		}
+		queries.SetScanner(&o.UpdatedAt, currTime)
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(alertRuleColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	alertRuleUpsertCacheMut.RLock()
+	cache, cached := alertRuleUpsertCache[key]
+	alertRuleUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			alertRuleAllColumns,
+			alertRuleColumnsWithDefault,
+			alertRuleColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		update := updateColumns.UpdateColumnSet(
+			alertRuleAllColumns,
+			alertRulePrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("dbmodel: unable to upsert alert_rules, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(alertRuleAllColumns, strmangle.SetIntersect(insert, update))
+
+		conflict := conflictColumns
+		if len(conflict) == 0 && updateOnConflict && len(update) != 0 {
+			if len(alertRulePrimaryKeyColumns) == 0 {
+				return errors.New("dbmodel: unable to upsert alert_rules, could not build conflict column list")
+			}
+
+			conflict = make([]string, len(alertRulePrimaryKeyColumns))
+			copy(conflict, alertRulePrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQueryPostgres(dialect, "\"alert_rules\"", updateOnConflict, ret, update, conflict, insert, opts...)
+
+		cache.valueMapping, err = queries.BindMapping(alertRuleType, alertRuleMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(alertRuleType, alertRuleMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(returns...)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "dbmodel: unable to upsert alert_rules")
+	}
+
+	if !cached {
+		alertRuleUpsertCacheMut.Lock()
+		alertRuleUpsertCache[key] = cache
+		alertRuleUpsertCacheMut.Unlock()
+	}
+
+	return nil
+}
+
+// Delete deletes a single AlertRule record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *AlertRule) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("dbmodel: no AlertRule provided for delete")
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), alertRulePrimaryKeyMapping)
+	sql := "DELETE FROM \"alert_rules\" WHERE \"id\"=$1"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to delete from alert_rules")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by delete for alert_rules")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q alertRuleQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("dbmodel: no alertRuleQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to delete all from alert_rules")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by deleteall for alert_rules")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o AlertRuleSlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), alertRulePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"alert_rules\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, alertRulePrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to delete all from alertRule slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by deleteall for alert_rules")
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *AlertRule) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindAlertRule(ctx, exec, o.ID)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *AlertRuleSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := AlertRuleSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), alertRulePrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"alert_rules\".* FROM \"alert_rules\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, alertRulePrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "dbmodel: unable to reload all in AlertRuleSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// AlertRuleExists checks if the AlertRule row exists.
+func AlertRuleExists(ctx context.Context, exec boil.ContextExecutor, iD int64) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"alert_rules\" where \"id\"=$1 limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, iD)
+	}
+	row := exec.QueryRowContext(ctx, sql, iD)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "dbmodel: unable to check if alert_rules exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the AlertRule row exists.
+func (o *AlertRule) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return AlertRuleExists(ctx, exec, o.ID)
+}
@@ -4,7 +4,10 @@
 package dbmodel
 
 var TableNames = struct {
+	AlertRules       string
+	Alerts           string
 	BenchmarkMetrics string
+	ConsumerOffsets  string
 	Floors           string
 	IotDevices       string
 	SchemaMigrations string
@@ -12,7 +15,10 @@ var TableNames = struct {
 	Users            string
 	Zones            string
 }{
+	AlertRules:       "alert_rules",
+	Alerts:           "alerts",
 	BenchmarkMetrics: "benchmark_metrics",
+	ConsumerOffsets:  "consumer_offsets",
 	Floors:           "floors",
 	IotDevices:       "iot_devices",
 	SchemaMigrations: "schema_migrations",
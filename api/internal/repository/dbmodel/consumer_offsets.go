@@ -0,0 +1,715 @@
+// Code generated by SQLBoiler 4.19.5 (https://github.com/aarondl/sqlboiler). DO NOT EDIT.
+// This file is meant to be re-generated in place and/or deleted at any time.
+
+package dbmodel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/aarondl/sqlboiler/v4/queries/qmhelper"
+	"github.com/aarondl/strmangle"
+	"github.com/friendsofgo/errors"
+)
+
+// ConsumerOffset is an object representing the database table.
+type ConsumerOffset struct {
+	GroupID    string    `boil:"group_id" json:"group_id" toml:"group_id" yaml:"group_id"`
+	Topic      string    `boil:"topic" json:"topic" toml:"topic" yaml:"topic"`
+	Partition  int       `boil:"partition" json:"partition" toml:"partition" yaml:"partition"`
+	NextOffset int64     `boil:"next_offset" json:"next_offset" toml:"next_offset" yaml:"next_offset"`
+	UpdatedAt  null.Time `boil:"updated_at" json:"updated_at,omitempty" toml:"updated_at" yaml:"updated_at,omitempty"`
+
+	R *consumerOffsetR `boil:"-" json:"-" toml:"-" yaml:"-"`
+	L consumerOffsetL  `boil:"-" json:"-" toml:"-" yaml:"-"`
+}
+
+var ConsumerOffsetColumns = struct {
+	GroupID    string
+	Topic      string
+	Partition  string
+	NextOffset string
+	UpdatedAt  string
+}{
+	GroupID:    "group_id",
+	Topic:      "topic",
+	Partition:  "partition",
+	NextOffset: "next_offset",
+	UpdatedAt:  "updated_at",
+}
+
+var ConsumerOffsetTableColumns = struct {
+	GroupID    string
+	Topic      string
+	Partition  string
+	NextOffset string
+	UpdatedAt  string
+}{
+	GroupID:    "consumer_offsets.group_id",
+	Topic:      "consumer_offsets.topic",
+	Partition:  "consumer_offsets.partition",
+	NextOffset: "consumer_offsets.next_offset",
+	UpdatedAt:  "consumer_offsets.updated_at",
+}
+
+// Generated where
+
+var ConsumerOffsetWhere = struct {
+	GroupID    whereHelperstring
+	Topic      whereHelperstring
+	Partition  whereHelperint
+	NextOffset whereHelperint64
+	UpdatedAt  whereHelpernull_Time
+}{
+	GroupID:    whereHelperstring{field: "\"consumer_offsets\".\"group_id\""},
+	Topic:      whereHelperstring{field: "\"consumer_offsets\".\"topic\""},
+	Partition:  whereHelperint{field: "\"consumer_offsets\".\"partition\""},
+	NextOffset: whereHelperint64{field: "\"consumer_offsets\".\"next_offset\""},
+	UpdatedAt:  whereHelpernull_Time{field: "\"consumer_offsets\".\"updated_at\""},
+}
+
+// ConsumerOffsetRels is where relationship names are stored.
+var ConsumerOffsetRels = struct {
+}{}
+
+// consumerOffsetR is where relationships are stored.
+type consumerOffsetR struct {
+}
+
+// NewStruct creates a new relationship struct
+func (*consumerOffsetR) NewStruct() *consumerOffsetR {
+	return &consumerOffsetR{}
+}
+
+// consumerOffsetL is where Load methods for each relationship are stored.
+type consumerOffsetL struct{}
+
+var (
+	consumerOffsetAllColumns            = []string{"group_id", "topic", "partition", "next_offset", "updated_at"}
+	consumerOffsetColumnsWithoutDefault = []string{"group_id", "topic", "partition", "next_offset"}
+	consumerOffsetColumnsWithDefault    = []string{"updated_at"}
+	consumerOffsetPrimaryKeyColumns     = []string{"group_id", "topic", "partition"}
+	consumerOffsetGeneratedColumns      = []string{}
+)
+
+type (
+	// ConsumerOffsetSlice is an alias for a slice of pointers to ConsumerOffset.
+	// This should almost always be used instead of []ConsumerOffset.
+	ConsumerOffsetSlice []*ConsumerOffset
+
+	consumerOffsetQuery struct {
+		*queries.Query
+	}
+)
+
+// Cache for insert, update and upsert
+var (
+	consumerOffsetType                 = reflect.TypeOf(&ConsumerOffset{})
+	consumerOffsetMapping              = queries.MakeStructMapping(consumerOffsetType)
+	consumerOffsetPrimaryKeyMapping, _ = queries.BindMapping(consumerOffsetType, consumerOffsetMapping, consumerOffsetPrimaryKeyColumns)
+	consumerOffsetInsertCacheMut       sync.RWMutex
+	consumerOffsetInsertCache          = make(map[string]insertCache)
+	consumerOffsetUpdateCacheMut       sync.RWMutex
+	consumerOffsetUpdateCache          = make(map[string]updateCache)
+	consumerOffsetUpsertCacheMut       sync.RWMutex
+	consumerOffsetUpsertCache          = make(map[string]insertCache)
+)
+
+var (
+	// Force time package dependency for automated UpdatedAt/CreatedAt.
+	_ = time.Second
+	// Force qmhelper dependency for where clause generation (which doesn't
+	// always happen)
+	_ = qmhelper.Where
+)
+
+// One returns a single consumerOffset record from the query.
+func (q consumerOffsetQuery) One(ctx context.Context, exec boil.ContextExecutor) (*ConsumerOffset, error) {
+	o := &ConsumerOffset{}
+
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Bind(ctx, exec, o)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "dbmodel: failed to execute a one query for consumer_offsets")
+	}
+
+	return o, nil
+}
+
+// All returns all ConsumerOffset records from the query.
+func (q consumerOffsetQuery) All(ctx context.Context, exec boil.ContextExecutor) (ConsumerOffsetSlice, error) {
+	var o []*ConsumerOffset
+
+	err := q.Bind(ctx, exec, &o)
+	if err != nil {
+		return nil, errors.Wrap(err, "dbmodel: failed to assign all query results to ConsumerOffset slice")
+	}
+
+	return o, nil
+}
+
+// Count returns the count of all ConsumerOffset records in the query.
+func (q consumerOffsetQuery) Count(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to count consumer_offsets rows")
+	}
+
+	return count, nil
+}
+
+// Exists checks if the row exists in the table.
+func (q consumerOffsetQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	var count int64
+
+	queries.SetSelect(q.Query, nil)
+	queries.SetCount(q.Query)
+	queries.SetLimit(q.Query, 1)
+
+	err := q.Query.QueryRowContext(ctx, exec).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "dbmodel: failed to check if consumer_offsets exists")
+	}
+
+	return count > 0, nil
+}
+
+// ConsumerOffsets retrieves all the records using an executor.
+func ConsumerOffsets(mods ...qm.QueryMod) consumerOffsetQuery {
+	mods = append(mods, qm.From("\"consumer_offsets\""))
+	q := NewQuery(mods...)
+	if len(queries.GetSelect(q)) == 0 {
+		queries.SetSelect(q, []string{"\"consumer_offsets\".*"})
+	}
+
+	return consumerOffsetQuery{q}
+}
+
+// FindConsumerOffset retrieves a single record by ID with an executor.
+// If selectCols is empty Find will return all columns.
+func FindConsumerOffset(ctx context.Context, exec boil.ContextExecutor, groupID string, topic string, partition int, selectCols ...string) (*ConsumerOffset, error) {
+	consumerOffsetObj := &ConsumerOffset{}
+
+	sel := "*"
+	if len(selectCols) > 0 {
+		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
+	}
+	query := fmt.Sprintf(
+		"select %s from \"consumer_offsets\" where \"group_id\"=$1 AND \"topic\"=$2 AND \"partition\"=$3", sel,
+	)
+
+	q := queries.Raw(query, groupID, topic, partition)
+
+	err := q.Bind(ctx, exec, consumerOffsetObj)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, errors.Wrap(err, "dbmodel: unable to select from consumer_offsets")
+	}
+
+	return consumerOffsetObj, nil
+}
+
+// Insert a single record using an executor.
+// See boil.Columns.InsertColumnSet documentation to understand column list inference for inserts.
+func (o *ConsumerOffset) Insert(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) error {
+	if o == nil {
+		return errors.New("dbmodel: no consumer_offsets provided for insertion")
+	}
+
+	var err error
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		if queries.MustTime(o.UpdatedAt).IsZero() {
+			queries.SetScanner(&o.UpdatedAt, currTime)
+		}
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(consumerOffsetColumnsWithDefault, o)
+
+	key := makeCacheKey(columns, nzDefaults)
+	consumerOffsetInsertCacheMut.RLock()
+	cache, cached := consumerOffsetInsertCache[key]
+	consumerOffsetInsertCacheMut.RUnlock()
+
+	if !cached {
+		wl, returnColumns := columns.InsertColumnSet(
+			consumerOffsetAllColumns,
+			consumerOffsetColumnsWithDefault,
+			consumerOffsetColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		cache.valueMapping, err = queries.BindMapping(consumerOffsetType, consumerOffsetMapping, wl)
+		if err != nil {
+			return err
+		}
+		cache.retMapping, err = queries.BindMapping(consumerOffsetType, consumerOffsetMapping, returnColumns)
+		if err != nil {
+			return err
+		}
+		if len(wl) != 0 {
+			cache.query = fmt.Sprintf("INSERT INTO \"consumer_offsets\" (\"%s\") %%sVALUES (%s)%%s", strings.Join(wl, "\",\""), strmangle.Placeholders(dialect.UseIndexPlaceholders, len(wl), 1, 1))
+		} else {
+			cache.query = "INSERT INTO \"consumer_offsets\" %sDEFAULT VALUES%s"
+		}
+
+		var queryOutput, queryReturning string
+
+		if len(cache.retMapping) != 0 {
+			queryReturning = fmt.Sprintf(" RETURNING \"%s\"", strings.Join(returnColumns, "\",\""))
+		}
+
+		cache.query = fmt.Sprintf(cache.query, queryOutput, queryReturning)
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.retMapping)...)
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "dbmodel: unable to insert into consumer_offsets")
+	}
+
+	if !cached {
+		consumerOffsetInsertCacheMut.Lock()
+		consumerOffsetInsertCache[key] = cache
+		consumerOffsetInsertCacheMut.Unlock()
+	}
+
+	return nil
+}
+
+// Update uses an executor to update the ConsumerOffset.
+// See boil.Columns.UpdateColumnSet documentation to understand column list inference for updates.
+// Update does not automatically update the record in case of default values. Use .Reload() to refresh the records.
+func (o *ConsumerOffset) Update(ctx context.Context, exec boil.ContextExecutor, columns boil.Columns) (int64, error) {
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		queries.SetScanner(&o.UpdatedAt, currTime)
+	}
+
+	var err error
+	key := makeCacheKey(columns, nil)
+	consumerOffsetUpdateCacheMut.RLock()
+	cache, cached := consumerOffsetUpdateCache[key]
+	consumerOffsetUpdateCacheMut.RUnlock()
+
+	if !cached {
+		wl := columns.UpdateColumnSet(
+			consumerOffsetAllColumns,
+			consumerOffsetPrimaryKeyColumns,
+		)
+
+		if !columns.IsWhitelist() {
+			wl = strmangle.SetComplement(wl, []string{"created_at"})
+		}
+		if len(wl) == 0 {
+			return 0, errors.New("dbmodel: unable to update consumer_offsets, could not build whitelist")
+		}
+
+		cache.query = fmt.Sprintf("UPDATE \"consumer_offsets\" SET %s WHERE %s",
+			strmangle.SetParamNames("\"", "\"", 1, wl),
+			strmangle.WhereClause("\"", "\"", len(wl)+1, consumerOffsetPrimaryKeyColumns),
+		)
+		cache.valueMapping, err = queries.BindMapping(consumerOffsetType, consumerOffsetMapping, append(wl, consumerOffsetPrimaryKeyColumns...))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, values)
+	}
+	var result sql.Result
+	result, err = exec.ExecContext(ctx, cache.query, values...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to update consumer_offsets row")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by update for consumer_offsets")
+	}
+
+	if !cached {
+		consumerOffsetUpdateCacheMut.Lock()
+		consumerOffsetUpdateCache[key] = cache
+		consumerOffsetUpdateCacheMut.Unlock()
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values.
+func (q consumerOffsetQuery) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	queries.SetUpdate(q.Query, cols)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to update all for consumer_offsets")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to retrieve rows affected for consumer_offsets")
+	}
+
+	return rowsAff, nil
+}
+
+// UpdateAll updates all rows with the specified column values, using an executor.
+func (o ConsumerOffsetSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, cols M) (int64, error) {
+	ln := int64(len(o))
+	if ln == 0 {
+		return 0, nil
+	}
+
+	if len(cols) == 0 {
+		return 0, errors.New("dbmodel: update all requires at least one column argument")
+	}
+
+	colNames := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+
+	i := 0
+	for name, value := range cols {
+		colNames[i] = name
+		args[i] = value
+		i++
+	}
+
+	// Append all of the primary key values for each column
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), consumerOffsetPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := fmt.Sprintf("UPDATE \"consumer_offsets\" SET %s WHERE %s",
+		strmangle.SetParamNames("\"", "\"", 1, colNames),
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), len(colNames)+1, consumerOffsetPrimaryKeyColumns, len(o)))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to update all in consumerOffset slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to retrieve rows affected all in update all consumerOffset")
+	}
+	return rowsAff, nil
+}
+
+// Upsert attempts an insert using an executor, and does an update or ignore on conflict.
+// See boil.Columns documentation for how to properly use updateColumns and insertColumns.
+func (o *ConsumerOffset) Upsert(ctx context.Context, exec boil.ContextExecutor, updateOnConflict bool, conflictColumns []string, updateColumns, insertColumns boil.Columns, opts ...UpsertOptionFunc) error {
+	if o == nil {
+		return errors.New("dbmodel: no consumer_offsets provided for upsert")
+	}
+	if !boil.TimestampsAreSkipped(ctx) {
+		currTime := time.Now().In(boil.GetLocation())
+
+		queries.SetScanner(&o.UpdatedAt, currTime)
+	}
+
+	nzDefaults := queries.NonZeroDefaultSet(consumerOffsetColumnsWithDefault, o)
+
+	// Build cache key in-line uglily - mysql vs psql problems
+	buf := strmangle.GetBuffer()
+	if updateOnConflict {
+		buf.WriteByte('t')
+	} else {
+		buf.WriteByte('f')
+	}
+	buf.WriteByte('.')
+	for _, c := range conflictColumns {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(updateColumns.Kind))
+	for _, c := range updateColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(insertColumns.Kind))
+	for _, c := range insertColumns.Cols {
+		buf.WriteString(c)
+	}
+	buf.WriteByte('.')
+	for _, c := range nzDefaults {
+		buf.WriteString(c)
+	}
+	key := buf.String()
+	strmangle.PutBuffer(buf)
+
+	consumerOffsetUpsertCacheMut.RLock()
+	cache, cached := consumerOffsetUpsertCache[key]
+	consumerOffsetUpsertCacheMut.RUnlock()
+
+	var err error
+
+	if !cached {
+		insert, _ := insertColumns.InsertColumnSet(
+			consumerOffsetAllColumns,
+			consumerOffsetColumnsWithDefault,
+			consumerOffsetColumnsWithoutDefault,
+			nzDefaults,
+		)
+
+		update := updateColumns.UpdateColumnSet(
+			consumerOffsetAllColumns,
+			consumerOffsetPrimaryKeyColumns,
+		)
+
+		if updateOnConflict && len(update) == 0 {
+			return errors.New("dbmodel: unable to upsert consumer_offsets, could not build update column list")
+		}
+
+		ret := strmangle.SetComplement(consumerOffsetAllColumns, strmangle.SetIntersect(insert, update))
+
+		conflict := conflictColumns
+		if len(conflict) == 0 && updateOnConflict && len(update) != 0 {
+			if len(consumerOffsetPrimaryKeyColumns) == 0 {
+				return errors.New("dbmodel: unable to upsert consumer_offsets, could not build conflict column list")
+			}
+
+			conflict = make([]string, len(consumerOffsetPrimaryKeyColumns))
+			copy(conflict, consumerOffsetPrimaryKeyColumns)
+		}
+		cache.query = buildUpsertQueryPostgres(dialect, "\"consumer_offsets\"", updateOnConflict, ret, update, conflict, insert, opts...)
+
+		cache.valueMapping, err = queries.BindMapping(consumerOffsetType, consumerOffsetMapping, insert)
+		if err != nil {
+			return err
+		}
+		if len(ret) != 0 {
+			cache.retMapping, err = queries.BindMapping(consumerOffsetType, consumerOffsetMapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+	var returns []interface{}
+	if len(cache.retMapping) != 0 {
+		returns = queries.PtrsFromMapping(value, cache.retMapping)
+	}
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, cache.query)
+		fmt.Fprintln(writer, vals)
+	}
+	if len(cache.retMapping) != 0 {
+		err = exec.QueryRowContext(ctx, cache.query, vals...).Scan(returns...)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil // Postgres doesn't return anything when there's no update
+		}
+	} else {
+		_, err = exec.ExecContext(ctx, cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Wrap(err, "dbmodel: unable to upsert consumer_offsets")
+	}
+
+	if !cached {
+		consumerOffsetUpsertCacheMut.Lock()
+		consumerOffsetUpsertCache[key] = cache
+		consumerOffsetUpsertCacheMut.Unlock()
+	}
+
+	return nil
+}
+
+// Delete deletes a single ConsumerOffset record with an executor.
+// Delete will match against the primary key column to find the record to delete.
+func (o *ConsumerOffset) Delete(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if o == nil {
+		return 0, errors.New("dbmodel: no ConsumerOffset provided for delete")
+	}
+
+	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), consumerOffsetPrimaryKeyMapping)
+	sql := "DELETE FROM \"consumer_offsets\" WHERE \"group_id\"=$1 AND \"topic\"=$2 AND \"partition\"=$3"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args...)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to delete from consumer_offsets")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by delete for consumer_offsets")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all matching rows.
+func (q consumerOffsetQuery) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if q.Query == nil {
+		return 0, errors.New("dbmodel: no consumerOffsetQuery provided for delete all")
+	}
+
+	queries.SetDelete(q.Query)
+
+	result, err := q.Query.ExecContext(ctx, exec)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to delete all from consumer_offsets")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by deleteall for consumer_offsets")
+	}
+
+	return rowsAff, nil
+}
+
+// DeleteAll deletes all rows in the slice, using an executor.
+func (o ConsumerOffsetSlice) DeleteAll(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if len(o) == 0 {
+		return 0, nil
+	}
+
+	var args []interface{}
+	for _, obj := range o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), consumerOffsetPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "DELETE FROM \"consumer_offsets\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, consumerOffsetPrimaryKeyColumns, len(o))
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, args)
+	}
+	result, err := exec.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: unable to delete all from consumerOffset slice")
+	}
+
+	rowsAff, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "dbmodel: failed to get rows affected by deleteall for consumer_offsets")
+	}
+
+	return rowsAff, nil
+}
+
+// Reload refetches the object from the database
+// using the primary keys with an executor.
+func (o *ConsumerOffset) Reload(ctx context.Context, exec boil.ContextExecutor) error {
+	ret, err := FindConsumerOffset(ctx, exec, o.GroupID, o.Topic, o.Partition)
+	if err != nil {
+		return err
+	}
+
+	*o = *ret
+	return nil
+}
+
+// ReloadAll refetches every row with matching primary key column values
+// and overwrites the original object slice with the newly updated slice.
+func (o *ConsumerOffsetSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) error {
+	if o == nil || len(*o) == 0 {
+		return nil
+	}
+
+	slice := ConsumerOffsetSlice{}
+	var args []interface{}
+	for _, obj := range *o {
+		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), consumerOffsetPrimaryKeyMapping)
+		args = append(args, pkeyArgs...)
+	}
+
+	sql := "SELECT \"consumer_offsets\".* FROM \"consumer_offsets\" WHERE " +
+		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), 1, consumerOffsetPrimaryKeyColumns, len(*o))
+
+	q := queries.Raw(sql, args...)
+
+	err := q.Bind(ctx, exec, &slice)
+	if err != nil {
+		return errors.Wrap(err, "dbmodel: unable to reload all in ConsumerOffsetSlice")
+	}
+
+	*o = slice
+
+	return nil
+}
+
+// ConsumerOffsetExists checks if the ConsumerOffset row exists.
+func ConsumerOffsetExists(ctx context.Context, exec boil.ContextExecutor, groupID string, topic string, partition int) (bool, error) {
+	var exists bool
+	sql := "select exists(select 1 from \"consumer_offsets\" where \"group_id\"=$1 AND \"topic\"=$2 AND \"partition\"=$3 limit 1)"
+
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, sql)
+		fmt.Fprintln(writer, groupID, topic, partition)
+	}
+	row := exec.QueryRowContext(ctx, sql, groupID, topic, partition)
+
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "dbmodel: unable to check if consumer_offsets exists")
+	}
+
+	return exists, nil
+}
+
+// Exists checks if the ConsumerOffset row exists.
+func (o *ConsumerOffset) Exists(ctx context.Context, exec boil.ContextExecutor) (bool, error) {
+	return ConsumerOffsetExists(ctx, exec, o.GroupID, o.Topic, o.Partition)
+}
@@ -181,10 +181,12 @@ var FloorWhere = struct {
 
 // FloorRels is where relationship names are stored.
 var FloorRels = struct {
+	AlertRules     string
 	IotDevices     string
 	SensorReadings string
 	Zones          string
 }{
+	AlertRules:     "AlertRules",
 	IotDevices:     "IotDevices",
 	SensorReadings: "SensorReadings",
 	Zones:          "Zones",
@@ -192,6 +194,7 @@ var FloorRels = struct {
 
 // floorR is where relationships are stored.
 type floorR struct {
+	AlertRules     AlertRuleSlice     `boil:"AlertRules" json:"AlertRules" toml:"AlertRules" yaml:"AlertRules"`
 	IotDevices     IotDeviceSlice     `boil:"IotDevices" json:"IotDevices" toml:"IotDevices" yaml:"IotDevices"`
 	SensorReadings SensorReadingSlice `boil:"SensorReadings" json:"SensorReadings" toml:"SensorReadings" yaml:"SensorReadings"`
 	Zones          ZoneSlice          `boil:"Zones" json:"Zones" toml:"Zones" yaml:"Zones"`
@@ -202,6 +205,22 @@ func (*floorR) NewStruct() *floorR {
 	return &floorR{}
 }
 
+func (o *Floor) GetAlertRules() AlertRuleSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetAlertRules()
+}
+
+func (r *floorR) GetAlertRules() AlertRuleSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.AlertRules
+}
+
 func (o *Floor) GetIotDevices() IotDeviceSlice {
 	if o == nil {
 		return nil
@@ -352,6 +371,20 @@ func (q floorQuery) Exists(ctx context.Context, exec boil.ContextExecutor) (bool
 	return count > 0, nil
 }
 
+// AlertRules retrieves all the alert_rule's AlertRules with an executor.
+func (o *Floor) AlertRules(mods ...qm.QueryMod) alertRuleQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"alert_rules\".\"floor_id\"=?", o.ID),
+	)
+
+	return AlertRules(queryMods...)
+}
+
 // IotDevices retrieves all the iot_device's IotDevices with an executor.
 func (o *Floor) IotDevices(mods ...qm.QueryMod) iotDeviceQuery {
 	var queryMods []qm.QueryMod
@@ -394,6 +427,112 @@ func (o *Floor) Zones(mods ...qm.QueryMod) zoneQuery {
 	return Zones(queryMods...)
 }
 
+// LoadAlertRules allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (floorL) LoadAlertRules(ctx context.Context, e boil.ContextExecutor, singular bool, maybeFloor interface{}, mods queries.Applicator) error {
+	var slice []*Floor
+	var object *Floor
+
+	if singular {
+		var ok bool
+		object, ok = maybeFloor.(*Floor)
+		if !ok {
+			object = new(Floor)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeFloor)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeFloor))
+			}
+		}
+	} else {
+		s, ok := maybeFloor.(*[]*Floor)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeFloor)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeFloor))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &floorR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &floorR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`alert_rules`),
+		qm.WhereIn(`alert_rules.floor_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load alert_rules")
+	}
+
+	var resultSlice []*AlertRule
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice alert_rules")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on alert_rules")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for alert_rules")
+	}
+
+	if singular {
+		object.R.AlertRules = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &alertRuleR{}
+			}
+			foreign.R.Floor = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.FloorID) {
+				local.R.AlertRules = append(local.R.AlertRules, foreign)
+				if foreign.R == nil {
+					foreign.R = &alertRuleR{}
+				}
+				foreign.R.Floor = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
 // LoadIotDevices allows an eager lookup of values, cached into the
 // loaded structs of the objects. This is for a 1-M or N-M relationship.
 func (floorL) LoadIotDevices(ctx context.Context, e boil.ContextExecutor, singular bool, maybeFloor interface{}, mods queries.Applicator) error {
@@ -712,6 +851,133 @@ func (floorL) LoadZones(ctx context.Context, e boil.ContextExecutor, singular bo
 	return nil
 }
 
+// AddAlertRules adds the given related objects to the existing relationships
+// of the floor, optionally inserting them as new records.
+// Appends related to o.R.AlertRules.
+// Sets related.R.Floor appropriately.
+func (o *Floor) AddAlertRules(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*AlertRule) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.FloorID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"alert_rules\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 1, []string{"floor_id"}),
+				strmangle.WhereClause("\"", "\"", 2, alertRulePrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.FloorID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &floorR{
+			AlertRules: related,
+		}
+	} else {
+		o.R.AlertRules = append(o.R.AlertRules, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &alertRuleR{
+				Floor: o,
+			}
+		} else {
+			rel.R.Floor = o
+		}
+	}
+	return nil
+}
+
+// SetAlertRules removes all previously related items of the
+// floor replacing them completely with the passed
+// in related items, optionally inserting them as new records.
+// Sets o.R.Floor's AlertRules accordingly.
+// Replaces o.R.AlertRules with related.
+// Sets related.R.Floor's AlertRules accordingly.
+func (o *Floor) SetAlertRules(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*AlertRule) error {
+	query := "update \"alert_rules\" set \"floor_id\" = null where \"floor_id\" = $1"
+	values := []interface{}{o.ID}
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, query)
+		fmt.Fprintln(writer, values)
+	}
+	_, err := exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove relationships before set")
+	}
+
+	if o.R != nil {
+		for _, rel := range o.R.AlertRules {
+			queries.SetScanner(&rel.FloorID, nil)
+			if rel.R == nil {
+				continue
+			}
+
+			rel.R.Floor = nil
+		}
+		o.R.AlertRules = nil
+	}
+
+	return o.AddAlertRules(ctx, exec, insert, related...)
+}
+
+// RemoveAlertRules relationships from objects passed in.
+// Removes related items from R.AlertRules (uses pointer comparison, removal does not keep order)
+// Sets related.R.Floor.
+func (o *Floor) RemoveAlertRules(ctx context.Context, exec boil.ContextExecutor, related ...*AlertRule) error {
+	if len(related) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, rel := range related {
+		queries.SetScanner(&rel.FloorID, nil)
+		if rel.R != nil {
+			rel.R.Floor = nil
+		}
+		if _, err = rel.Update(ctx, exec, boil.Whitelist("floor_id")); err != nil {
+			return err
+		}
+	}
+	if o.R == nil {
+		return nil
+	}
+
+	for _, rel := range related {
+		for i, ri := range o.R.AlertRules {
+			if rel != ri {
+				continue
+			}
+
+			ln := len(o.R.AlertRules)
+			if ln > 1 && i < ln-1 {
+				o.R.AlertRules[i] = o.R.AlertRules[ln-1]
+			}
+			o.R.AlertRules = o.R.AlertRules[:ln-1]
+			break
+		}
+	}
+
+	return nil
+}
+
 // AddIotDevices adds the given related objects to the existing relationships
 // of the floor, optionally inserting them as new records.
 // Appends related to o.R.IotDevices.
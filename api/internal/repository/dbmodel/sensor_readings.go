@@ -39,6 +39,7 @@ type SensorReading struct {
 	DurableWriteTS  time.Time    `boil:"durable_write_ts" json:"durable_write_ts" toml:"durable_write_ts" yaml:"durable_write_ts"`
 	HeatIndex       null.Float64 `boil:"heat_index" json:"heat_index,omitempty" toml:"heat_index" yaml:"heat_index,omitempty"`
 	AirQualityIndex null.Int     `boil:"air_quality_index" json:"air_quality_index,omitempty" toml:"air_quality_index" yaml:"air_quality_index,omitempty"`
+	MessageID       string       `boil:"message_id" json:"message_id" toml:"message_id" yaml:"message_id"`
 
 	R *sensorReadingR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L sensorReadingL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -60,6 +61,7 @@ var SensorReadingColumns = struct {
 	DurableWriteTS  string
 	HeatIndex       string
 	AirQualityIndex string
+	MessageID       string
 }{
 	ID:              "id",
 	DeviceID:        "device_id",
@@ -76,6 +78,7 @@ var SensorReadingColumns = struct {
 	DurableWriteTS:  "durable_write_ts",
 	HeatIndex:       "heat_index",
 	AirQualityIndex: "air_quality_index",
+	MessageID:       "message_id",
 }
 
 var SensorReadingTableColumns = struct {
@@ -94,6 +97,7 @@ var SensorReadingTableColumns = struct {
 	DurableWriteTS  string
 	HeatIndex       string
 	AirQualityIndex string
+	MessageID       string
 }{
 	ID:              "sensor_readings.id",
 	DeviceID:        "sensor_readings.device_id",
@@ -110,48 +114,11 @@ var SensorReadingTableColumns = struct {
 	DurableWriteTS:  "sensor_readings.durable_write_ts",
 	HeatIndex:       "sensor_readings.heat_index",
 	AirQualityIndex: "sensor_readings.air_quality_index",
+	MessageID:       "sensor_readings.message_id",
 }
 
 // Generated where
 
-type whereHelpernull_Int struct{ field string }
-
-func (w whereHelpernull_Int) EQ(x null.Int) qm.QueryMod {
-	return qmhelper.WhereNullEQ(w.field, false, x)
-}
-func (w whereHelpernull_Int) NEQ(x null.Int) qm.QueryMod {
-	return qmhelper.WhereNullEQ(w.field, true, x)
-}
-func (w whereHelpernull_Int) LT(x null.Int) qm.QueryMod {
-	return qmhelper.Where(w.field, qmhelper.LT, x)
-}
-func (w whereHelpernull_Int) LTE(x null.Int) qm.QueryMod {
-	return qmhelper.Where(w.field, qmhelper.LTE, x)
-}
-func (w whereHelpernull_Int) GT(x null.Int) qm.QueryMod {
-	return qmhelper.Where(w.field, qmhelper.GT, x)
-}
-func (w whereHelpernull_Int) GTE(x null.Int) qm.QueryMod {
-	return qmhelper.Where(w.field, qmhelper.GTE, x)
-}
-func (w whereHelpernull_Int) IN(slice []int) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
-}
-func (w whereHelpernull_Int) NIN(slice []int) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
-}
-
-func (w whereHelpernull_Int) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
-func (w whereHelpernull_Int) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
-
 var SensorReadingWhere = struct {
 	ID              whereHelperint64
 	DeviceID        whereHelperstring
@@ -168,6 +135,7 @@ var SensorReadingWhere = struct {
 	DurableWriteTS  whereHelpertime_Time
 	HeatIndex       whereHelpernull_Float64
 	AirQualityIndex whereHelpernull_Int
+	MessageID       whereHelperstring
 }{
 	ID:              whereHelperint64{field: "\"sensor_readings\".\"id\""},
 	DeviceID:        whereHelperstring{field: "\"sensor_readings\".\"device_id\""},
@@ -184,6 +152,7 @@ var SensorReadingWhere = struct {
 	DurableWriteTS:  whereHelpertime_Time{field: "\"sensor_readings\".\"durable_write_ts\""},
 	HeatIndex:       whereHelpernull_Float64{field: "\"sensor_readings\".\"heat_index\""},
 	AirQualityIndex: whereHelpernull_Int{field: "\"sensor_readings\".\"air_quality_index\""},
+	MessageID:       whereHelperstring{field: "\"sensor_readings\".\"message_id\""},
 }
 
 // SensorReadingRels is where relationship names are stored.
@@ -242,8 +211,8 @@ func (r *sensorReadingR) GetZone() *Zone {
 type sensorReadingL struct{}
 
 var (
-	sensorReadingAllColumns            = []string{"id", "device_id", "device_name", "device_type", "location", "floor_id", "zone_id", "temperature", "humidity", "co2", "timestamp", "created_at", "durable_write_ts", "heat_index", "air_quality_index"}
-	sensorReadingColumnsWithoutDefault = []string{"device_id", "device_name", "device_type", "location", "floor_id", "zone_id"}
+	sensorReadingAllColumns            = []string{"id", "device_id", "device_name", "device_type", "location", "floor_id", "zone_id", "temperature", "humidity", "co2", "timestamp", "created_at", "durable_write_ts", "heat_index", "air_quality_index", "message_id"}
+	sensorReadingColumnsWithoutDefault = []string{"device_id", "device_name", "device_type", "location", "floor_id", "zone_id", "message_id"}
 	sensorReadingColumnsWithDefault    = []string{"id", "temperature", "humidity", "co2", "timestamp", "created_at", "durable_write_ts", "heat_index", "air_quality_index"}
 	sensorReadingPrimaryKeyColumns     = []string{"id", "timestamp"}
 	sensorReadingGeneratedColumns      = []string{"heat_index", "air_quality_index"}
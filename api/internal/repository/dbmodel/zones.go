@@ -102,10 +102,12 @@ var ZoneWhere = struct {
 // ZoneRels is where relationship names are stored.
 var ZoneRels = struct {
 	Floor          string
+	AlertRules     string
 	IotDevices     string
 	SensorReadings string
 }{
 	Floor:          "Floor",
+	AlertRules:     "AlertRules",
 	IotDevices:     "IotDevices",
 	SensorReadings: "SensorReadings",
 }
@@ -113,6 +115,7 @@ var ZoneRels = struct {
 // zoneR is where relationships are stored.
 type zoneR struct {
 	Floor          *Floor             `boil:"Floor" json:"Floor" toml:"Floor" yaml:"Floor"`
+	AlertRules     AlertRuleSlice     `boil:"AlertRules" json:"AlertRules" toml:"AlertRules" yaml:"AlertRules"`
 	IotDevices     IotDeviceSlice     `boil:"IotDevices" json:"IotDevices" toml:"IotDevices" yaml:"IotDevices"`
 	SensorReadings SensorReadingSlice `boil:"SensorReadings" json:"SensorReadings" toml:"SensorReadings" yaml:"SensorReadings"`
 }
@@ -138,6 +141,22 @@ func (r *zoneR) GetFloor() *Floor {
 	return r.Floor
 }
 
+func (o *Zone) GetAlertRules() AlertRuleSlice {
+	if o == nil {
+		return nil
+	}
+
+	return o.R.GetAlertRules()
+}
+
+func (r *zoneR) GetAlertRules() AlertRuleSlice {
+	if r == nil {
+		return nil
+	}
+
+	return r.AlertRules
+}
+
 func (o *Zone) GetIotDevices() IotDeviceSlice {
 	if o == nil {
 		return nil
@@ -283,6 +302,20 @@ func (o *Zone) Floor(mods ...qm.QueryMod) floorQuery {
 	return Floors(queryMods...)
 }
 
+// AlertRules retrieves all the alert_rule's AlertRules with an executor.
+func (o *Zone) AlertRules(mods ...qm.QueryMod) alertRuleQuery {
+	var queryMods []qm.QueryMod
+	if len(mods) != 0 {
+		queryMods = append(queryMods, mods...)
+	}
+
+	queryMods = append(queryMods,
+		qm.Where("\"alert_rules\".\"zone_id\"=?", o.ID),
+	)
+
+	return AlertRules(queryMods...)
+}
+
 // IotDevices retrieves all the iot_device's IotDevices with an executor.
 func (o *Zone) IotDevices(mods ...qm.QueryMod) iotDeviceQuery {
 	var queryMods []qm.QueryMod
@@ -427,6 +460,112 @@ func (zoneL) LoadFloor(ctx context.Context, e boil.ContextExecutor, singular boo
 	return nil
 }
 
+// LoadAlertRules allows an eager lookup of values, cached into the
+// loaded structs of the objects. This is for a 1-M or N-M relationship.
+func (zoneL) LoadAlertRules(ctx context.Context, e boil.ContextExecutor, singular bool, maybeZone interface{}, mods queries.Applicator) error {
+	var slice []*Zone
+	var object *Zone
+
+	if singular {
+		var ok bool
+		object, ok = maybeZone.(*Zone)
+		if !ok {
+			object = new(Zone)
+			ok = queries.SetFromEmbeddedStruct(&object, &maybeZone)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", object, maybeZone))
+			}
+		}
+	} else {
+		s, ok := maybeZone.(*[]*Zone)
+		if ok {
+			slice = *s
+		} else {
+			ok = queries.SetFromEmbeddedStruct(&slice, maybeZone)
+			if !ok {
+				return errors.New(fmt.Sprintf("failed to set %T from embedded struct %T", slice, maybeZone))
+			}
+		}
+	}
+
+	args := make(map[interface{}]struct{})
+	if singular {
+		if object.R == nil {
+			object.R = &zoneR{}
+		}
+		args[object.ID] = struct{}{}
+	} else {
+		for _, obj := range slice {
+			if obj.R == nil {
+				obj.R = &zoneR{}
+			}
+			args[obj.ID] = struct{}{}
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	argsSlice := make([]interface{}, len(args))
+	i := 0
+	for arg := range args {
+		argsSlice[i] = arg
+		i++
+	}
+
+	query := NewQuery(
+		qm.From(`alert_rules`),
+		qm.WhereIn(`alert_rules.zone_id in ?`, argsSlice...),
+	)
+	if mods != nil {
+		mods.Apply(query)
+	}
+
+	results, err := query.QueryContext(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "failed to eager load alert_rules")
+	}
+
+	var resultSlice []*AlertRule
+	if err = queries.Bind(results, &resultSlice); err != nil {
+		return errors.Wrap(err, "failed to bind eager loaded slice alert_rules")
+	}
+
+	if err = results.Close(); err != nil {
+		return errors.Wrap(err, "failed to close results in eager load on alert_rules")
+	}
+	if err = results.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during iteration of eager loaded relations for alert_rules")
+	}
+
+	if singular {
+		object.R.AlertRules = resultSlice
+		for _, foreign := range resultSlice {
+			if foreign.R == nil {
+				foreign.R = &alertRuleR{}
+			}
+			foreign.R.Zone = object
+		}
+		return nil
+	}
+
+	for _, foreign := range resultSlice {
+		for _, local := range slice {
+			if queries.Equal(local.ID, foreign.ZoneID) {
+				local.R.AlertRules = append(local.R.AlertRules, foreign)
+				if foreign.R == nil {
+					foreign.R = &alertRuleR{}
+				}
+				foreign.R.Zone = local
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
 // LoadIotDevices allows an eager lookup of values, cached into the
 // loaded structs of the objects. This is for a 1-M or N-M relationship.
 func (zoneL) LoadIotDevices(ctx context.Context, e boil.ContextExecutor, singular bool, maybeZone interface{}, mods queries.Applicator) error {
@@ -719,6 +858,133 @@ func (o *Zone) RemoveFloor(ctx context.Context, exec boil.ContextExecutor, relat
 	return nil
 }
 
+// AddAlertRules adds the given related objects to the existing relationships
+// of the zone, optionally inserting them as new records.
+// Appends related to o.R.AlertRules.
+// Sets related.R.Zone appropriately.
+func (o *Zone) AddAlertRules(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*AlertRule) error {
+	var err error
+	for _, rel := range related {
+		if insert {
+			queries.Assign(&rel.ZoneID, o.ID)
+			if err = rel.Insert(ctx, exec, boil.Infer()); err != nil {
+				return errors.Wrap(err, "failed to insert into foreign table")
+			}
+		} else {
+			updateQuery := fmt.Sprintf(
+				"UPDATE \"alert_rules\" SET %s WHERE %s",
+				strmangle.SetParamNames("\"", "\"", 1, []string{"zone_id"}),
+				strmangle.WhereClause("\"", "\"", 2, alertRulePrimaryKeyColumns),
+			)
+			values := []interface{}{o.ID, rel.ID}
+
+			if boil.IsDebug(ctx) {
+				writer := boil.DebugWriterFrom(ctx)
+				fmt.Fprintln(writer, updateQuery)
+				fmt.Fprintln(writer, values)
+			}
+			if _, err = exec.ExecContext(ctx, updateQuery, values...); err != nil {
+				return errors.Wrap(err, "failed to update foreign table")
+			}
+
+			queries.Assign(&rel.ZoneID, o.ID)
+		}
+	}
+
+	if o.R == nil {
+		o.R = &zoneR{
+			AlertRules: related,
+		}
+	} else {
+		o.R.AlertRules = append(o.R.AlertRules, related...)
+	}
+
+	for _, rel := range related {
+		if rel.R == nil {
+			rel.R = &alertRuleR{
+				Zone: o,
+			}
+		} else {
+			rel.R.Zone = o
+		}
+	}
+	return nil
+}
+
+// SetAlertRules removes all previously related items of the
+// zone replacing them completely with the passed
+// in related items, optionally inserting them as new records.
+// Sets o.R.Zone's AlertRules accordingly.
+// Replaces o.R.AlertRules with related.
+// Sets related.R.Zone's AlertRules accordingly.
+func (o *Zone) SetAlertRules(ctx context.Context, exec boil.ContextExecutor, insert bool, related ...*AlertRule) error {
+	query := "update \"alert_rules\" set \"zone_id\" = null where \"zone_id\" = $1"
+	values := []interface{}{o.ID}
+	if boil.IsDebug(ctx) {
+		writer := boil.DebugWriterFrom(ctx)
+		fmt.Fprintln(writer, query)
+		fmt.Fprintln(writer, values)
+	}
+	_, err := exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove relationships before set")
+	}
+
+	if o.R != nil {
+		for _, rel := range o.R.AlertRules {
+			queries.SetScanner(&rel.ZoneID, nil)
+			if rel.R == nil {
+				continue
+			}
+
+			rel.R.Zone = nil
+		}
+		o.R.AlertRules = nil
+	}
+
+	return o.AddAlertRules(ctx, exec, insert, related...)
+}
+
+// RemoveAlertRules relationships from objects passed in.
+// Removes related items from R.AlertRules (uses pointer comparison, removal does not keep order)
+// Sets related.R.Zone.
+func (o *Zone) RemoveAlertRules(ctx context.Context, exec boil.ContextExecutor, related ...*AlertRule) error {
+	if len(related) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, rel := range related {
+		queries.SetScanner(&rel.ZoneID, nil)
+		if rel.R != nil {
+			rel.R.Zone = nil
+		}
+		if _, err = rel.Update(ctx, exec, boil.Whitelist("zone_id")); err != nil {
+			return err
+		}
+	}
+	if o.R == nil {
+		return nil
+	}
+
+	for _, rel := range related {
+		for i, ri := range o.R.AlertRules {
+			if rel != ri {
+				continue
+			}
+
+			ln := len(o.R.AlertRules)
+			if ln > 1 && i < ln-1 {
+				o.R.AlertRules[i] = o.R.AlertRules[ln-1]
+			}
+			o.R.AlertRules = o.R.AlertRules[:ln-1]
+			break
+		}
+	}
+
+	return nil
+}
+
 // AddIotDevices adds the given related objects to the existing relationships
 // of the zone, optionally inserting them as new records.
 // Appends related to o.R.IotDevices.
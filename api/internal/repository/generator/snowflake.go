@@ -5,14 +5,18 @@ import "github.com/nhan1603/IoTsystem/api/internal/pkg/snowflake"
 var (
 	DeviceTokenIDSNF snowflake.SnowflakeGenerator
 	AlertIDSNF       snowflake.SnowflakeGenerator
+	AlertRuleIDSNF   snowflake.SnowflakeGenerator
 	RequestIDSNF     snowflake.SnowflakeGenerator
 	ResponseSNF      snowflake.SnowflakeGenerator
+	UserIDSNF        snowflake.SnowflakeGenerator
 )
 
 func InitSnowflakeGenerators() error {
 	DeviceTokenIDSNF = snowflake.New()
 	AlertIDSNF = snowflake.New()
+	AlertRuleIDSNF = snowflake.New()
 	RequestIDSNF = snowflake.New()
 	ResponseSNF = snowflake.New()
+	UserIDSNF = snowflake.New()
 	return nil
 }
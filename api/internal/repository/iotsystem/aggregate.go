@@ -0,0 +1,105 @@
+package iotsystem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// AggregateReadings buckets the readings with TimescaleDB time_bucket and returns the
+// avg/min/max of every metric per bucket and group, ordered by bucket then group
+func (r impl) AggregateReadings(ctx context.Context, input model.AggregateReadingsInput) ([]model.ReadingAggregate, error) {
+	var groupCols []string
+	switch input.GroupBy {
+	case model.AggregateGroupByDevice:
+		groupCols = []string{"device_id"}
+	case model.AggregateGroupByZone:
+		groupCols = []string{"floor_id", "zone_id"}
+	case model.AggregateGroupByFloor:
+		groupCols = []string{"floor_id"}
+	default:
+		return nil, fmt.Errorf("unknown group by %q", input.GroupBy)
+	}
+
+	interval := input.Interval.Duration()
+	if interval == 0 {
+		return nil, fmt.Errorf("unknown interval %q", input.Interval)
+	}
+
+	args := []interface{}{fmt.Sprintf("%d seconds", int64(interval.Seconds()))}
+	conds := []string{}
+	addCond := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if input.DeviceID != "" {
+		addCond("device_id = $%d", input.DeviceID)
+	}
+	if input.Floor > 0 {
+		addCond("floor_id = $%d", input.Floor)
+	}
+	if input.Zone > 0 {
+		addCond("zone_id = $%d", input.Zone)
+	}
+	if !input.StartTime.IsZero() {
+		addCond("timestamp >= $%d", input.StartTime)
+	}
+	if !input.EndTime.IsZero() {
+		addCond("timestamp < $%d", input.EndTime)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	group := strings.Join(groupCols, ", ")
+
+	query := fmt.Sprintf(`
+		SELECT time_bucket($1::interval, timestamp) AS bucket, %[1]s,
+		       COUNT(*),
+		       AVG(temperature), MIN(temperature), MAX(temperature),
+		       AVG(humidity), MIN(humidity), MAX(humidity),
+		       AVG(co2), MIN(co2), MAX(co2)
+		FROM sensor_readings
+		%[2]s
+		GROUP BY bucket, %[1]s
+		ORDER BY bucket, %[1]s
+	`, group, where)
+
+	rows, err := r.dbConn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate readings: %w", err)
+	}
+	defer rows.Close()
+
+	var aggs []model.ReadingAggregate
+	for rows.Next() {
+		var agg model.ReadingAggregate
+		dest := []interface{}{&agg.Bucket}
+		switch input.GroupBy {
+		case model.AggregateGroupByDevice:
+			dest = append(dest, &agg.DeviceID)
+		case model.AggregateGroupByZone:
+			dest = append(dest, &agg.Floor, &agg.Zone)
+		case model.AggregateGroupByFloor:
+			dest = append(dest, &agg.Floor)
+		}
+		dest = append(dest,
+			&agg.Count,
+			&agg.Temperature.Avg, &agg.Temperature.Min, &agg.Temperature.Max,
+			&agg.Humidity.Avg, &agg.Humidity.Min, &agg.Humidity.Max,
+			&agg.CO2.Avg, &agg.CO2.Min, &agg.CO2.Max,
+		)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+		aggs = append(aggs, agg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read aggregates: %w", err)
+	}
+
+	return aggs, nil
+}
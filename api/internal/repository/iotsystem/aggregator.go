@@ -0,0 +1,123 @@
+package iotsystem
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+type aggregateKey struct {
+	bucket   time.Time
+	deviceID string
+	floor    int
+	zone     int
+}
+
+type metricAccumulator struct {
+	sum, min, max float64
+}
+
+func (m *metricAccumulator) add(v float64, first bool) {
+	m.sum += v
+	if first || v < m.min {
+		m.min = v
+	}
+	if first || v > m.max {
+		m.max = v
+	}
+}
+
+func (m metricAccumulator) toModel(count int64) model.MetricAggregate {
+	return model.MetricAggregate{Avg: m.sum / float64(count), Min: m.min, Max: m.max}
+}
+
+type aggregateAccumulator struct {
+	count                 int64
+	temperature, humidity metricAccumulator
+	co2                   metricAccumulator
+}
+
+// ReadingAggregator buckets readings like time_bucket, whose buckets are aligned on
+// midnight UTC for every supported interval. It serves the backends which can't
+// group by a time expression themselves.
+type ReadingAggregator struct {
+	interval time.Duration
+	groupBy  model.AggregateGroupBy
+	groups   map[aggregateKey]*aggregateAccumulator
+}
+
+// NewReadingAggregator validates the interval and grouping of the aggregation
+func NewReadingAggregator(interval model.AggregateInterval, groupBy model.AggregateGroupBy) (*ReadingAggregator, error) {
+	switch groupBy {
+	case model.AggregateGroupByDevice, model.AggregateGroupByZone, model.AggregateGroupByFloor:
+	default:
+		return nil, fmt.Errorf("unknown group by %q", groupBy)
+	}
+	if interval.Duration() == 0 {
+		return nil, fmt.Errorf("unknown interval %q", interval)
+	}
+
+	return &ReadingAggregator{
+		interval: interval.Duration(),
+		groupBy:  groupBy,
+		groups:   map[aggregateKey]*aggregateAccumulator{},
+	}, nil
+}
+
+// Add accounts the reading in the bucket of its group
+func (a *ReadingAggregator) Add(r model.SensorReading) {
+	key := aggregateKey{bucket: r.Timestamp.UTC().Truncate(a.interval)}
+	switch a.groupBy {
+	case model.AggregateGroupByDevice:
+		key.deviceID = r.DeviceID
+	case model.AggregateGroupByZone:
+		key.floor, key.zone = r.Floor, r.Zone
+	case model.AggregateGroupByFloor:
+		key.floor = r.Floor
+	}
+
+	acc, ok := a.groups[key]
+	if !ok {
+		acc = &aggregateAccumulator{}
+		a.groups[key] = acc
+	}
+	acc.count++
+	acc.temperature.add(r.Temperature, !ok)
+	acc.humidity.add(r.Humidity, !ok)
+	acc.co2.add(r.CO2, !ok)
+}
+
+// Result returns the aggregates ordered by bucket then group
+func (a *ReadingAggregator) Result() []model.ReadingAggregate {
+	aggs := make([]model.ReadingAggregate, 0, len(a.groups))
+	for key, acc := range a.groups {
+		aggs = append(aggs, model.ReadingAggregate{
+			Bucket:      key.bucket,
+			DeviceID:    key.deviceID,
+			Floor:       key.floor,
+			Zone:        key.zone,
+			Count:       acc.count,
+			Temperature: acc.temperature.toModel(acc.count),
+			Humidity:    acc.humidity.toModel(acc.count),
+			CO2:         acc.co2.toModel(acc.count),
+		})
+	}
+
+	sort.Slice(aggs, func(i, j int) bool {
+		x, y := aggs[i], aggs[j]
+		switch {
+		case !x.Bucket.Equal(y.Bucket):
+			return x.Bucket.Before(y.Bucket)
+		case x.DeviceID != y.DeviceID:
+			return x.DeviceID < y.DeviceID
+		case x.Floor != y.Floor:
+			return x.Floor < y.Floor
+		default:
+			return x.Zone < y.Zone
+		}
+	})
+
+	return aggs
+}
@@ -0,0 +1,111 @@
+package iotsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingAggregator(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	readings := []model.SensorReading{
+		{DeviceID: "TEMP_002", Floor: 1, Zone: 2, Temperature: 30, Humidity: 40, CO2: 500, Timestamp: base.Add(10 * time.Second)},
+		{DeviceID: "TEMP_001", Floor: 1, Zone: 1, Temperature: 20, Humidity: 50, CO2: 400, Timestamp: base.Add(20 * time.Second)},
+		{DeviceID: "TEMP_001", Floor: 1, Zone: 1, Temperature: 22, Humidity: 54, CO2: 600, Timestamp: base.Add(50 * time.Second)},
+		{DeviceID: "TEMP_001", Floor: 1, Zone: 1, Temperature: 25, Humidity: 45, CO2: 700, Timestamp: base.Add(70 * time.Second)},
+	}
+
+	tcs := map[string]struct {
+		givenInterval model.AggregateInterval
+		givenGroupBy  model.AggregateGroupBy
+		expResult     []model.ReadingAggregate
+		expErr        bool
+	}{
+		"device_per_minute": {
+			givenInterval: model.AggregateIntervalMinute,
+			givenGroupBy:  model.AggregateGroupByDevice,
+			expResult: []model.ReadingAggregate{
+				{
+					Bucket: base, DeviceID: "TEMP_001", Count: 2,
+					Temperature: model.MetricAggregate{Avg: 21, Min: 20, Max: 22},
+					Humidity:    model.MetricAggregate{Avg: 52, Min: 50, Max: 54},
+					CO2:         model.MetricAggregate{Avg: 500, Min: 400, Max: 600},
+				},
+				{
+					Bucket: base, DeviceID: "TEMP_002", Count: 1,
+					Temperature: model.MetricAggregate{Avg: 30, Min: 30, Max: 30},
+					Humidity:    model.MetricAggregate{Avg: 40, Min: 40, Max: 40},
+					CO2:         model.MetricAggregate{Avg: 500, Min: 500, Max: 500},
+				},
+				{
+					Bucket: base.Add(time.Minute), DeviceID: "TEMP_001", Count: 1,
+					Temperature: model.MetricAggregate{Avg: 25, Min: 25, Max: 25},
+					Humidity:    model.MetricAggregate{Avg: 45, Min: 45, Max: 45},
+					CO2:         model.MetricAggregate{Avg: 700, Min: 700, Max: 700},
+				},
+			},
+		},
+		"floor_per_hour": {
+			givenInterval: model.AggregateIntervalHour,
+			givenGroupBy:  model.AggregateGroupByFloor,
+			expResult: []model.ReadingAggregate{
+				{
+					Bucket: base, Floor: 1, Count: 4,
+					Temperature: model.MetricAggregate{Avg: 24.25, Min: 20, Max: 30},
+					Humidity:    model.MetricAggregate{Avg: 47.25, Min: 40, Max: 54},
+					CO2:         model.MetricAggregate{Avg: 550, Min: 400, Max: 700},
+				},
+			},
+		},
+		"zone_per_day": {
+			givenInterval: model.AggregateIntervalDay,
+			givenGroupBy:  model.AggregateGroupByZone,
+			expResult: []model.ReadingAggregate{
+				{
+					Bucket: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Floor: 1, Zone: 1, Count: 3,
+					Temperature: model.MetricAggregate{Avg: 67.0 / 3, Min: 20, Max: 25},
+					Humidity:    model.MetricAggregate{Avg: 149.0 / 3, Min: 45, Max: 54},
+					CO2:         model.MetricAggregate{Avg: 1700.0 / 3, Min: 400, Max: 700},
+				},
+				{
+					Bucket: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Floor: 1, Zone: 2, Count: 1,
+					Temperature: model.MetricAggregate{Avg: 30, Min: 30, Max: 30},
+					Humidity:    model.MetricAggregate{Avg: 40, Min: 40, Max: 40},
+					CO2:         model.MetricAggregate{Avg: 500, Min: 500, Max: 500},
+				},
+			},
+		},
+		"unknown_interval": {
+			givenInterval: "2m",
+			givenGroupBy:  model.AggregateGroupByDevice,
+			expErr:        true,
+		},
+		"unknown_group_by": {
+			givenInterval: model.AggregateIntervalMinute,
+			givenGroupBy:  "building",
+			expErr:        true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// Given:
+			agg, err := NewReadingAggregator(tc.givenInterval, tc.givenGroupBy)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// When:
+			for _, r := range readings {
+				agg.Add(r)
+			}
+
+			// Then:
+			require.Equal(t, tc.expResult, agg.Result())
+		})
+	}
+}
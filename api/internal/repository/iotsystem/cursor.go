@@ -0,0 +1,51 @@
+package iotsystem
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadingCursor is the keyset position of the last reading of a page.
+// Readings are ordered by (timestamp DESC, device_id DESC, id DESC), the id breaks
+// the ties of distinct readings of a device sharing a timestamp.
+type ReadingCursor struct {
+	Timestamp time.Time
+	DeviceID  string
+	ID        int64
+}
+
+// EncodeReadingCursor builds the opaque cursor pointing after the given reading
+func EncodeReadingCursor(c ReadingCursor) string {
+	raw := strconv.FormatInt(c.Timestamp.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10) + ":" + c.DeviceID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeReadingCursor parses a cursor built by EncodeReadingCursor
+func DecodeReadingCursor(cursor string) (ReadingCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ReadingCursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(b), ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return ReadingCursor{}, ErrInvalidCursor
+	}
+
+	ns, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ReadingCursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ReadingCursor{}, ErrInvalidCursor
+	}
+
+	return ReadingCursor{
+		Timestamp: time.Unix(0, ns).UTC(),
+		DeviceID:  parts[2],
+		ID:        id,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package iotsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingCursor(t *testing.T) {
+	tcs := map[string]struct {
+		givenCursor string
+		expCursor   ReadingCursor
+		expErr      error
+	}{
+		"round_trip": {
+			givenCursor: EncodeReadingCursor(ReadingCursor{
+				Timestamp: time.Date(2025, 1, 1, 10, 30, 0, 123456000, time.UTC),
+				DeviceID:  "TEMP:001",
+				ID:        42,
+			}),
+			expCursor: ReadingCursor{
+				Timestamp: time.Date(2025, 1, 1, 10, 30, 0, 123456000, time.UTC),
+				DeviceID:  "TEMP:001",
+				ID:        42,
+			},
+		},
+		"not_base64": {
+			givenCursor: "%%%",
+			expErr:      ErrInvalidCursor,
+		},
+		"missing_device": {
+			givenCursor: "MTIzOjQyOg", // "123:42:"
+			expErr:      ErrInvalidCursor,
+		},
+		"invalid_timestamp": {
+			givenCursor: "YWJjOjQyOlRFTVBfMDAx", // "abc:42:TEMP_001"
+			expErr:      ErrInvalidCursor,
+		},
+		"missing_id": {
+			givenCursor: "MTIzOlRFTVBfMDAx", // "123:TEMP_001", before ids were part of the cursor
+			expErr:      ErrInvalidCursor,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			cursor, err := DecodeReadingCursor(tc.givenCursor)
+
+			// Then:
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expCursor, cursor)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package iotsystem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/lib/pq"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+// pgUniqueViolation is the postgres error code of a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// GetDevice retrieves a device, active or not, by its device id
+func (r impl) GetDevice(ctx context.Context, deviceID string) (model.IoTDevice, error) {
+	row, err := dbmodel.IotDevices(dbmodel.IotDeviceWhere.DeviceID.EQ(deviceID)).One(ctx, r.dbConn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.IoTDevice{}, ErrNotFound
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to query device %s: %w", deviceID, err)
+	}
+
+	return toModelDevice(row), nil
+}
+
+// CreateDevice registers a new active device
+func (r impl) CreateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	row := dbmodel.IotDevice{
+		DeviceID: device.DeviceID,
+		Name:     device.Name,
+		Type:     device.Type,
+		Location: device.Location,
+		FloorID:  device.Floor,
+		ZoneID:   device.Zone,
+		IsActive: null.BoolFrom(true),
+	}
+
+	if err := row.Insert(ctx, r.dbConn, boil.Infer()); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return model.IoTDevice{}, ErrAlreadyExists
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to insert device %s: %w", device.DeviceID, err)
+	}
+
+	return toModelDevice(&row), nil
+}
+
+// UpdateDevice overwrites the descriptive fields and placement of a device
+func (r impl) UpdateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	row, err := dbmodel.IotDevices(dbmodel.IotDeviceWhere.DeviceID.EQ(device.DeviceID)).One(ctx, r.dbConn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.IoTDevice{}, ErrNotFound
+		}
+		return model.IoTDevice{}, fmt.Errorf("failed to query device %s: %w", device.DeviceID, err)
+	}
+
+	row.Name = device.Name
+	row.Type = device.Type
+	row.Location = device.Location
+	row.FloorID = device.Floor
+	row.ZoneID = device.Zone
+
+	if _, err := row.Update(ctx, r.dbConn, boil.Whitelist(
+		dbmodel.IotDeviceColumns.Name,
+		dbmodel.IotDeviceColumns.Type,
+		dbmodel.IotDeviceColumns.Location,
+		dbmodel.IotDeviceColumns.FloorID,
+		dbmodel.IotDeviceColumns.ZoneID,
+		dbmodel.IotDeviceColumns.UpdatedAt,
+	)); err != nil {
+		return model.IoTDevice{}, fmt.Errorf("failed to update device %s: %w", device.DeviceID, err)
+	}
+
+	return toModelDevice(row), nil
+}
+
+// SetDeviceActive activates or deactivates a device
+func (r impl) SetDeviceActive(ctx context.Context, deviceID string, active bool) error {
+	rowsAff, err := dbmodel.IotDevices(dbmodel.IotDeviceWhere.DeviceID.EQ(deviceID)).UpdateAll(ctx, r.dbConn, dbmodel.M{
+		dbmodel.IotDeviceColumns.IsActive:  active,
+		dbmodel.IotDeviceColumns.UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set device %s active=%t: %w", deviceID, active, err)
+	}
+	if rowsAff == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func toModelDevice(row *dbmodel.IotDevice) model.IoTDevice {
+	return model.IoTDevice{
+		ID:        row.ID,
+		DeviceID:  row.DeviceID,
+		Name:      row.Name,
+		Type:      row.Type,
+		Location:  row.Location,
+		Floor:     row.FloorID,
+		Zone:      row.ZoneID,
+		IsActive:  row.IsActive.Bool,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,14 @@
+package iotsystem
+
+import "errors"
+
+var (
+	// ErrInvalidCursor means the pagination cursor could not be decoded
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrTooManyDevices means an unfiltered read would have to merge the readings of too many devices
+	ErrTooManyDevices = errors.New("too many devices")
+	// ErrNotFound means the item was not found
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means an item with the same key already exists
+	ErrAlreadyExists = errors.New("already exists")
+)
@@ -15,7 +15,13 @@ import (
 // Repository defines IoT data repository interface
 type Repository interface {
 	GetDevices(ctx context.Context) ([]model.IoTDevice, error)
-	GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, error)
+	GetDevice(ctx context.Context, deviceID string) (model.IoTDevice, error)
+	CreateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error)
+	UpdateDevice(ctx context.Context, device model.IoTDevice) (model.IoTDevice, error)
+	SetDeviceActive(ctx context.Context, deviceID string, active bool) error
+	GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, string, error)
+	CountReadings(ctx context.Context, input model.GetReadingsInput) (int64, error)
+	AggregateReadings(ctx context.Context, input model.AggregateReadingsInput) ([]model.ReadingAggregate, error)
 	GetLatestReadings(ctx context.Context) ([]model.SensorReading, error)
 	BatchInsertReadings(ctx context.Context, readings []model.SensorReading) error
 	GetBenchmarkMetrics(ctx context.Context, limit int) ([]model.BenchmarkMetrics, error)
@@ -25,15 +31,13 @@ type Repository interface {
 // New returns an implementation instance satisfying Repository
 func New(dbConn boil.ContextExecutor) Repository {
 	return impl{
-		dbConn:    dbConn,
-		tsDeduper: NewTsDeduper(),
+		dbConn: dbConn,
 	}
 
 }
 
 type impl struct {
-	dbConn    boil.ContextExecutor
-	tsDeduper *TsDeduper
+	dbConn boil.ContextExecutor
 }
 
 // GetDevices retrieves all IoT devices
@@ -46,56 +50,46 @@ func (r impl) GetDevices(ctx context.Context) ([]model.IoTDevice, error) {
 	}
 	var devices []model.IoTDevice
 	for _, row := range dbDevice {
-		devices = append(devices, model.IoTDevice{
-			ID:        row.ID,
-			DeviceID:  row.DeviceID,
-			Name:      row.Name,
-			Type:      row.Type,
-			Location:  row.Location,
-			Floor:     row.FloorID,
-			Zone:      row.ZoneID,
-			IsActive:  row.IsActive.Bool,
-			CreatedAt: row.CreatedAt.Time,
-			UpdatedAt: row.UpdatedAt.Time,
-		})
+		devices = append(devices, toModelDevice(row))
 	}
 
 	return devices, nil
 }
 
-// GetReadings retrieves sensor readings with filters
-func (r impl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, error) {
-	mods := []qm.QueryMod{}
-
-	if input.DeviceID != "" {
-		mods = append(mods, dbmodel.SensorReadingWhere.DeviceID.EQ(input.DeviceID))
-	}
-	if input.DeviceType != "" {
-		mods = append(mods, dbmodel.SensorReadingWhere.DeviceType.EQ(input.DeviceType))
-	}
-	if input.Location != "" {
-		mods = append(mods, dbmodel.SensorReadingWhere.Location.EQ(input.Location))
-	}
-	if input.Floor > 0 {
-		mods = append(mods, dbmodel.SensorReadingWhere.FloorID.EQ(input.Floor))
-	}
-	if input.Zone > 0 {
-		mods = append(mods, dbmodel.SensorReadingWhere.ZoneID.EQ(input.Zone))
-	}
-	if !input.StartTime.IsZero() {
-		mods = append(mods, dbmodel.SensorReadingWhere.Timestamp.GTE(input.StartTime))
-	}
-	if !input.EndTime.IsZero() {
-		mods = append(mods, dbmodel.SensorReadingWhere.Timestamp.LTE(input.EndTime))
+// GetReadings retrieves sensor readings with filters, one page at a time.
+// Pages are walked by seeking on (timestamp, device_id, id) rather than with OFFSET,
+// the returned cursor is empty once the last page is reached.
+func (r impl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]model.SensorReading, string, error) {
+	mods := readingFilterMods(input)
+	if input.Cursor != "" {
+		cursor, err := DecodeReadingCursor(input.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if input.DeviceID != "" {
+			// Within one device the seek is served by idx_sensor_readings_device_timestamp,
+			// the id only breaks the ties of readings sharing a timestamp
+			mods = append(mods, qm.Where("(timestamp, id) < (?, ?)", cursor.Timestamp, cursor.ID))
+		} else {
+			mods = append(mods, qm.Where("(timestamp, device_id, id) < (?, ?, ?)", cursor.Timestamp, cursor.DeviceID, cursor.ID))
+		}
 	}
-	mods = append(mods, qm.OrderBy("timestamp DESC"))
+	mods = append(mods, qm.OrderBy("timestamp DESC, device_id DESC, id DESC"))
 	if input.Limit > 0 {
-		mods = append(mods, qm.Limit(input.Limit))
+		// Fetch one extra row to know whether there is a next page
+		mods = append(mods, qm.Limit(input.Limit+1))
 	}
 
 	dbReadings, err := dbmodel.SensorReadings(mods...).All(ctx, r.dbConn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query readings: %w", err)
+		return nil, "", fmt.Errorf("failed to query readings: %w", err)
+	}
+
+	var nextCursor string
+	if input.Limit > 0 && len(dbReadings) > input.Limit {
+		dbReadings = dbReadings[:input.Limit]
+		last := dbReadings[len(dbReadings)-1]
+		nextCursor = EncodeReadingCursor(ReadingCursor{Timestamp: last.Timestamp, DeviceID: last.DeviceID, ID: last.ID})
 	}
 
 	var readings []model.SensorReading
@@ -115,14 +109,52 @@ func (r impl) GetReadings(ctx context.Context, input model.GetReadingsInput) ([]
 			CreatedAt:   row.CreatedAt.Time,
 		})
 	}
-	return readings, nil
+	return readings, nextCursor, nil
+}
+
+// CountReadings counts the sensor readings matching the filters, ignoring limit and cursor
+func (r impl) CountReadings(ctx context.Context, input model.GetReadingsInput) (int64, error) {
+	count, err := dbmodel.SensorReadings(readingFilterMods(input)...).Count(ctx, r.dbConn)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count readings: %w", err)
+	}
+	return count, nil
+}
+
+// readingFilterMods converts the filters of input into query mods
+func readingFilterMods(input model.GetReadingsInput) []qm.QueryMod {
+	mods := []qm.QueryMod{}
+
+	if input.DeviceID != "" {
+		mods = append(mods, dbmodel.SensorReadingWhere.DeviceID.EQ(input.DeviceID))
+	}
+	if input.DeviceType != "" {
+		mods = append(mods, dbmodel.SensorReadingWhere.DeviceType.EQ(input.DeviceType))
+	}
+	if input.Location != "" {
+		mods = append(mods, dbmodel.SensorReadingWhere.Location.EQ(input.Location))
+	}
+	if input.Floor > 0 {
+		mods = append(mods, dbmodel.SensorReadingWhere.FloorID.EQ(input.Floor))
+	}
+	if input.Zone > 0 {
+		mods = append(mods, dbmodel.SensorReadingWhere.ZoneID.EQ(input.Zone))
+	}
+	if !input.StartTime.IsZero() {
+		mods = append(mods, dbmodel.SensorReadingWhere.Timestamp.GTE(input.StartTime))
+	}
+	if !input.EndTime.IsZero() {
+		mods = append(mods, dbmodel.SensorReadingWhere.Timestamp.LTE(input.EndTime))
+	}
+
+	return mods
 }
 
 // GetLatestReadings retrieves the latest reading for each device
 func (r impl) GetLatestReadings(ctx context.Context) ([]model.SensorReading, error) {
 	query := `
 		SELECT DISTINCT ON (device_id) 
-		       id, device_id, device_name, device_type, location, floor, zone,
+		       id, device_id, device_name, device_type, location, floor_id, zone_id,
 		       temperature, humidity, co2, timestamp, created_at
 		FROM sensor_readings
 		ORDER BY device_id, timestamp DESC
@@ -161,7 +193,8 @@ func (r impl) GetLatestReadings(ctx context.Context) ([]model.SensorReading, err
 }
 
 // Do this inTX
-// BatchInsertReadings inserts multiple sensor readings in a batch
+// BatchInsertReadings inserts multiple sensor readings in a batch keeping their timestamps,
+// readings of an already stored message are skipped so redeliveries are idempotent
 func (r impl) BatchInsertReadings(ctx context.Context, readings []model.SensorReading) error {
 	if len(readings) == 0 {
 		return nil
@@ -171,7 +204,7 @@ func (r impl) BatchInsertReadings(ctx context.Context, readings []model.SensorRe
 	query := `
 		INSERT INTO sensor_readings 
 		(device_id, device_name, device_type, location, floor_id, zone_id, 
-		 temperature, humidity, co2, timestamp, created_at)
+		 temperature, humidity, co2, timestamp, created_at, message_id)
 		VALUES 
 	`
 
@@ -180,11 +213,9 @@ func (r impl) BatchInsertReadings(ctx context.Context, readings []model.SensorRe
 	argCount := 0
 
 	for _, reading := range readings {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
 			argCount+1, argCount+2, argCount+3, argCount+4, argCount+5, argCount+6,
-			argCount+7, argCount+8, argCount+9, argCount+10, argCount+11))
-
-		ts := r.tsDeduper.Next(reading.DeviceID, reading.Timestamp)
+			argCount+7, argCount+8, argCount+9, argCount+10, argCount+11, argCount+12))
 
 		valueArgs = append(valueArgs,
 			reading.DeviceID,
@@ -196,15 +227,16 @@ func (r impl) BatchInsertReadings(ctx context.Context, readings []model.SensorRe
 			reading.Temperature,
 			reading.Humidity,
 			reading.CO2,
-			ts,
+			reading.Timestamp,
 			reading.CreatedAt,
+			reading.MessageID,
 		)
-		argCount += 11
+		argCount += 12
 	}
 
 	query += strings.Join(valueStrings, ",")
 	query += `
-        ON CONFLICT ON CONSTRAINT uq_device_ts 
+        ON CONFLICT ON CONSTRAINT uq_message_ts 
 		DO NOTHING`
 
 	// Execute batch insert
@@ -239,9 +271,11 @@ func (r impl) GetBenchmarkMetrics(ctx context.Context, limit int) ([]model.Bench
 			StartTime:        row.StartTime,
 			EndTime:          row.EndTime,
 			AverageLatency:   row.AverageLatency,
+			EndToEndLatency:  row.EndToEndLatency,
 			Throughput:       row.Throughput,
 			BatchSize:        row.BatchSize,
 			DatabaseType:     row.DatabaseType,
+			CreatedAt:        row.CreatedAt.Time,
 		})
 	}
 
@@ -263,6 +297,7 @@ func (r impl) SaveBenchmarkMetrics(ctx context.Context, metrics model.BenchmarkM
 		BatchSize:        metrics.BatchSize,
 		DatabaseType:     metrics.DatabaseType,
 		EndToEndLatency:  metrics.EndToEndLatency, // New field
+		CreatedAt:        null.NewTime(metrics.CreatedAt, !metrics.CreatedAt.IsZero()),
 	}
 
 	err := metricsDb.Insert(ctx, r.dbConn, boil.Infer())
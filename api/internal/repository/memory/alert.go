@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+)
+
+// NewAlert returns the alert rules and alerts repo of the store
+func NewAlert(s *Store) alert.Repository {
+	return alertImpl{s: s}
+}
+
+type alertImpl struct {
+	s *Store
+}
+
+// GetRules retrieves the alert rules, oldest first
+func (r alertImpl) GetRules(_ context.Context, enabledOnly bool) ([]model.AlertRule, error) {
+	var rules []model.AlertRule
+	err := r.s.read(func(t *tables) error {
+		for _, rule := range t.rules.rows {
+			if rule.Enabled || !enabledOnly {
+				rules = append(rules, rule)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+// GetRule retrieves an alert rule by id
+func (r alertImpl) GetRule(_ context.Context, id int64) (model.AlertRule, error) {
+	var rule model.AlertRule
+	err := r.s.read(func(t *tables) error {
+		var ok bool
+		if rule, ok = t.rules.get(id); !ok {
+			return alert.ErrNotFound
+		}
+		return nil
+	})
+	return rule, err
+}
+
+// CreateRule inserts an alert rule, the id must be set by the caller
+func (r alertImpl) CreateRule(_ context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	err := r.s.write(func(t *tables) error {
+		if _, ok := t.rules.get(rule.ID); ok {
+			return fmt.Errorf("failed to insert alert rule %s: id %d already exists", rule.Name, rule.ID)
+		}
+		rule.CreatedAt = now()
+		rule.UpdatedAt = rule.CreatedAt
+		t.rules.put(rule.ID, rule)
+		return nil
+	})
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule overwrites everything but the id and creation time of an alert rule
+func (r alertImpl) UpdateRule(_ context.Context, rule model.AlertRule) (model.AlertRule, error) {
+	err := r.s.write(func(t *tables) error {
+		stored, ok := t.rules.get(rule.ID)
+		if !ok {
+			return alert.ErrNotFound
+		}
+		rule.CreatedAt = stored.CreatedAt
+		rule.UpdatedAt = now()
+		t.rules.put(rule.ID, rule)
+		return nil
+	})
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+// GetAlerts retrieves the alerts matching the filters, newest first
+func (r alertImpl) GetAlerts(_ context.Context, input model.GetAlertsInput) ([]model.Alert, error) {
+	var alerts []model.Alert
+	err := r.s.read(func(t *tables) error {
+		for _, a := range t.alerts.rows {
+			if (len(input.Statuses) == 0 || slices.Contains(input.Statuses, a.Status)) &&
+				(input.RuleID == 0 || a.RuleID == input.RuleID) &&
+				(input.DeviceID == "" || a.DeviceID == input.DeviceID) &&
+				(len(input.DeviceIDs) == 0 || slices.Contains(input.DeviceIDs, a.DeviceID)) {
+				alerts = append(alerts, a)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID > alerts[j].ID })
+	if input.Limit > 0 && len(alerts) > input.Limit {
+		alerts = alerts[:input.Limit]
+	}
+	return alerts, nil
+}
+
+// GetAlert retrieves an alert by id
+func (r alertImpl) GetAlert(_ context.Context, id int64) (model.Alert, error) {
+	var a model.Alert
+	err := r.s.read(func(t *tables) error {
+		var ok bool
+		if a, ok = t.alerts.get(id); !ok {
+			return alert.ErrNotFound
+		}
+		return nil
+	})
+	return a, err
+}
+
+// CreateAlerts inserts the alerts all or none, the ids must be set by the caller
+func (r alertImpl) CreateAlerts(_ context.Context, alerts []model.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	return r.s.write(func(t *tables) error {
+		for _, a := range alerts {
+			if _, ok := t.alerts.get(a.ID); ok {
+				return fmt.Errorf("failed to insert alerts: id %d already exists", a.ID)
+			}
+			t.alerts.put(a.ID, a)
+		}
+		return nil
+	})
+}
+
+// UpdateAlertStatus saves the status and the state timestamps of an alert
+func (r alertImpl) UpdateAlertStatus(_ context.Context, a model.Alert) error {
+	return r.s.write(func(t *tables) error {
+		stored, ok := t.alerts.get(a.ID)
+		if !ok {
+			return alert.ErrNotFound
+		}
+		stored.Status = a.Status
+		stored.AcknowledgedAt = a.AcknowledgedAt
+		stored.ResolvedAt = a.ResolvedAt
+		stored.UpdatedAt = now()
+		t.alerts.put(a.ID, stored)
+		return nil
+	})
+}
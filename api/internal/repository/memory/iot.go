@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+)
+
+// NewIoT returns the devices and readings repo of the store
+func NewIoT(s *Store) iotsystem.Repository {
+	return iotImpl{s: s}
+}
+
+type iotImpl struct {
+	s *Store
+}
+
+// readingKey is the unique key of a reading, the uq_message_ts constraint of postgres
+func readingKey(r model.SensorReading) string {
+	return strconv.FormatInt(r.Timestamp.UnixNano(), 10) + ":" + r.MessageID
+}
+
+// GetDevices retrieves the active devices ordered by device id
+func (r iotImpl) GetDevices(_ context.Context) ([]model.IoTDevice, error) {
+	var devices []model.IoTDevice
+	err := r.s.read(func(t *tables) error {
+		for _, d := range t.devices.rows {
+			if d.IsActive {
+				devices = append(devices, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortDevices(devices)
+	return devices, nil
+}
+
+// GetDevice retrieves a device, active or not, by its device id
+func (r iotImpl) GetDevice(_ context.Context, deviceID string) (model.IoTDevice, error) {
+	var device model.IoTDevice
+	err := r.s.read(func(t *tables) error {
+		var ok bool
+		if device, ok = t.devices.get(deviceID); !ok {
+			return iotsystem.ErrNotFound
+		}
+		return nil
+	})
+	return device, err
+}
+
+// CreateDevice registers a new active device
+func (r iotImpl) CreateDevice(_ context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	err := r.s.write(func(t *tables) error {
+		if _, ok := t.devices.get(device.DeviceID); ok {
+			return iotsystem.ErrAlreadyExists
+		}
+		t.seq.device++
+		device.ID = t.seq.device
+		device.IsActive = true
+		device.CreatedAt = now()
+		device.UpdatedAt = device.CreatedAt
+		t.devices.put(device.DeviceID, device)
+		return nil
+	})
+	if err != nil {
+		return model.IoTDevice{}, err
+	}
+	return device, nil
+}
+
+// UpdateDevice overwrites the descriptive fields and placement of a device
+func (r iotImpl) UpdateDevice(_ context.Context, device model.IoTDevice) (model.IoTDevice, error) {
+	var updated model.IoTDevice
+	err := r.s.write(func(t *tables) error {
+		var ok bool
+		if updated, ok = t.devices.get(device.DeviceID); !ok {
+			return iotsystem.ErrNotFound
+		}
+		updated.Name = device.Name
+		updated.Type = device.Type
+		updated.Location = device.Location
+		updated.Floor = device.Floor
+		updated.Zone = device.Zone
+		updated.UpdatedAt = now()
+		t.devices.put(updated.DeviceID, updated)
+		return nil
+	})
+	if err != nil {
+		return model.IoTDevice{}, err
+	}
+	return updated, nil
+}
+
+// SetDeviceActive activates or deactivates a device
+func (r iotImpl) SetDeviceActive(_ context.Context, deviceID string, active bool) error {
+	return r.s.write(func(t *tables) error {
+		device, ok := t.devices.get(deviceID)
+		if !ok {
+			return iotsystem.ErrNotFound
+		}
+		device.IsActive = active
+		device.UpdatedAt = now()
+		t.devices.put(deviceID, device)
+		return nil
+	})
+}
+
+// GetReadings retrieves sensor readings with filters, one page at a time.
+// Pages are ordered and walked by (timestamp, device_id, id) like the postgres keyset.
+func (r iotImpl) GetReadings(_ context.Context, input model.GetReadingsInput) ([]model.SensorReading, string, error) {
+	var after *iotsystem.ReadingCursor
+	if input.Cursor != "" {
+		cursor, err := iotsystem.DecodeReadingCursor(input.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &cursor
+	}
+
+	var readings []model.SensorReading
+	err := r.s.read(func(t *tables) error {
+		t.readings.each(func(reading model.SensorReading) {
+			if matchReading(input, reading) && (after == nil || readingAfter(reading, *after)) {
+				readings = append(readings, reading)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Slice(readings, func(i, j int) bool {
+		return readingAfter(readings[j], cursorOf(readings[i]))
+	})
+
+	var nextCursor string
+	if input.Limit > 0 && len(readings) > input.Limit {
+		readings = readings[:input.Limit]
+		nextCursor = iotsystem.EncodeReadingCursor(cursorOf(readings[len(readings)-1]))
+	}
+	for i := range readings {
+		// like postgres, the message id is only used to deduplicate
+		readings[i].MessageID = ""
+	}
+	return readings, nextCursor, nil
+}
+
+// CountReadings counts the sensor readings matching the filters, ignoring limit and cursor
+func (r iotImpl) CountReadings(_ context.Context, input model.GetReadingsInput) (int64, error) {
+	var count int64
+	err := r.s.read(func(t *tables) error {
+		t.readings.each(func(reading model.SensorReading) {
+			if matchReading(input, reading) {
+				count++
+			}
+		})
+		return nil
+	})
+	return count, err
+}
+
+// AggregateReadings buckets the readings like time_bucket and returns the avg/min/max of
+// every metric per bucket and group, ordered by bucket then group
+func (r iotImpl) AggregateReadings(_ context.Context, input model.AggregateReadingsInput) ([]model.ReadingAggregate, error) {
+	agg, err := iotsystem.NewReadingAggregator(input.Interval, input.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.s.read(func(t *tables) error {
+		t.readings.each(func(reading model.SensorReading) {
+			switch {
+			case input.DeviceID != "" && reading.DeviceID != input.DeviceID,
+				input.Floor > 0 && reading.Floor != input.Floor,
+				input.Zone > 0 && reading.Zone != input.Zone,
+				!input.StartTime.IsZero() && reading.Timestamp.Before(input.StartTime),
+				!input.EndTime.IsZero() && !reading.Timestamp.Before(input.EndTime):
+				return
+			}
+			agg.Add(reading)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return agg.Result(), nil
+}
+
+// GetLatestReadings retrieves the latest reading for each device, ordered by device id
+func (r iotImpl) GetLatestReadings(_ context.Context) ([]model.SensorReading, error) {
+	latest := map[string]model.SensorReading{}
+	err := r.s.read(func(t *tables) error {
+		t.readings.each(func(reading model.SensorReading) {
+			if l, ok := latest[reading.DeviceID]; !ok || l.Timestamp.Before(reading.Timestamp) {
+				latest[reading.DeviceID] = reading
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []model.SensorReading
+	for _, reading := range latest {
+		reading.MessageID = ""
+		readings = append(readings, reading)
+	}
+	sort.Slice(readings, func(i, j int) bool { return readings[i].DeviceID < readings[j].DeviceID })
+	return readings, nil
+}
+
+// BatchInsertReadings inserts the readings keeping their timestamps,
+// readings of an already stored message are skipped so redeliveries are idempotent
+func (r iotImpl) BatchInsertReadings(_ context.Context, readings []model.SensorReading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+
+	return r.s.write(func(t *tables) error {
+		for _, reading := range readings {
+			reading.ID = t.seq.reading + 1
+			if t.readings.insert(reading) {
+				t.seq.reading++
+			}
+		}
+		return nil
+	})
+}
+
+// GetBenchmarkMetrics retrieves the benchmark performance metrics, newest first
+func (r iotImpl) GetBenchmarkMetrics(_ context.Context, limit int) ([]model.BenchmarkMetrics, error) {
+	var metrics []model.BenchmarkMetrics
+	err := r.s.read(func(t *tables) error {
+		t.metrics.each(func(m model.BenchmarkMetrics) {
+			metrics = append(metrics, m)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the log is in insertion order, which breaks the ties of creation time
+	for i, j := 0, len(metrics)-1; i < j; i, j = i+1, j-1 {
+		metrics[i], metrics[j] = metrics[j], metrics[i]
+	}
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].CreatedAt.After(metrics[j].CreatedAt) })
+	if limit > 0 && len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics, nil
+}
+
+// SaveBenchmarkMetrics saves benchmark performance metrics
+func (r iotImpl) SaveBenchmarkMetrics(_ context.Context, metrics model.BenchmarkMetrics) error {
+	if metrics.CreatedAt.IsZero() {
+		metrics.CreatedAt = time.Now()
+	}
+	return r.s.write(func(t *tables) error {
+		t.metrics.insert(metrics)
+		return nil
+	})
+}
+
+// matchReading checks the reading against the filters of input
+func matchReading(input model.GetReadingsInput, r model.SensorReading) bool {
+	return (input.DeviceID == "" || r.DeviceID == input.DeviceID) &&
+		(input.DeviceType == "" || r.DeviceType == input.DeviceType) &&
+		(input.Location == "" || r.Location == input.Location) &&
+		(input.Floor <= 0 || r.Floor == input.Floor) &&
+		(input.Zone <= 0 || r.Zone == input.Zone) &&
+		(input.StartTime.IsZero() || !r.Timestamp.Before(input.StartTime)) &&
+		(input.EndTime.IsZero() || !r.Timestamp.After(input.EndTime))
+}
+
+func cursorOf(r model.SensorReading) iotsystem.ReadingCursor {
+	return iotsystem.ReadingCursor{Timestamp: r.Timestamp, DeviceID: r.DeviceID, ID: r.ID}
+}
+
+// readingAfter checks whether the reading comes after the cursor position in the
+// (timestamp DESC, device_id DESC, id DESC) order of the pages
+func readingAfter(r model.SensorReading, c iotsystem.ReadingCursor) bool {
+	if !r.Timestamp.Equal(c.Timestamp) {
+		return r.Timestamp.Before(c.Timestamp)
+	}
+	if cmp := strings.Compare(r.DeviceID, c.DeviceID); cmp != 0 {
+		return cmp < 0
+	}
+	return r.ID < c.ID
+}
+
+func sortDevices(devices []model.IoTDevice) {
+	sort.Slice(devices, func(i, j int) bool { return devices[i].DeviceID < devices[j].DeviceID })
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReadings(t *testing.T) {
+	ctx := context.Background()
+	ts := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	repo := NewIoT(NewStore())
+
+	// Given: two readings of a device share a timestamp, one message is delivered twice
+	require.NoError(t, repo.BatchInsertReadings(ctx, []model.SensorReading{
+		{DeviceID: "TEMP_001", Floor: 1, Timestamp: ts, MessageID: "m-1"},
+		{DeviceID: "TEMP_002", Floor: 2, Timestamp: ts, MessageID: "m-2"},
+		{DeviceID: "TEMP_001", Floor: 1, Timestamp: ts, MessageID: "m-3"},
+		{DeviceID: "TEMP_001", Floor: 1, Timestamp: ts.Add(time.Minute), MessageID: "m-4"},
+	}))
+	require.NoError(t, repo.BatchInsertReadings(ctx, []model.SensorReading{
+		{DeviceID: "TEMP_001", Floor: 1, Timestamp: ts.Add(time.Minute), MessageID: "m-4"},
+	}))
+
+	tcs := map[string]struct {
+		givenInput model.GetReadingsInput
+		expIDs     [][]int64
+		expErr     error
+	}{
+		"all_pages": {
+			givenInput: model.GetReadingsInput{Limit: 2},
+			expIDs:     [][]int64{{4, 2}, {3, 1}},
+		},
+		"device": {
+			givenInput: model.GetReadingsInput{DeviceID: "TEMP_001", Limit: 2},
+			expIDs:     [][]int64{{4, 3}, {1}},
+		},
+		"floor_and_range": {
+			givenInput: model.GetReadingsInput{Floor: 1, EndTime: ts},
+			expIDs:     [][]int64{{3, 1}},
+		},
+		"invalid_cursor": {
+			givenInput: model.GetReadingsInput{Cursor: "%%%"},
+			expErr:     iotsystem.ErrInvalidCursor,
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			input := tc.givenInput
+			var pages [][]int64
+			for {
+				// When:
+				readings, next, err := repo.GetReadings(ctx, input)
+
+				// Then:
+				if tc.expErr != nil {
+					require.Equal(t, tc.expErr, err)
+					return
+				}
+				require.NoError(t, err)
+				var ids []int64
+				for _, r := range readings {
+					require.Empty(t, r.MessageID)
+					ids = append(ids, r.ID)
+				}
+				pages = append(pages, ids)
+				if next == "" {
+					break
+				}
+				input.Cursor = next
+			}
+			require.Equal(t, tc.expIDs, pages)
+
+			count, err := repo.CountReadings(ctx, tc.givenInput)
+			require.NoError(t, err)
+			var total int
+			for _, ids := range tc.expIDs {
+				total += len(ids)
+			}
+			require.Equal(t, int64(total), count)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/offset"
+)
+
+// NewOffset returns the consumer offsets repo of the store
+func NewOffset(s *Store) offset.Repository {
+	return offsetImpl{s: s}
+}
+
+type offsetImpl struct {
+	s *Store
+}
+
+// GetOffsets retrieves the stored offsets of the group by partition of the topic
+func (r offsetImpl) GetOffsets(_ context.Context, groupID, topic string) (map[int32]int64, error) {
+	offsets := make(map[int32]int64)
+	err := r.s.read(func(t *tables) error {
+		for k, o := range t.offsets.rows {
+			if k.groupID == groupID && k.topic == topic {
+				offsets[k.partition] = o
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// SaveOffsets upserts the offsets, meant to be called in the transaction writing the consumed data
+func (r offsetImpl) SaveOffsets(_ context.Context, offsets []model.ConsumerOffset) error {
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	return r.s.write(func(t *tables) error {
+		for _, o := range offsets {
+			t.offsets.put(offsetKey{groupID: o.GroupID, topic: o.Topic, partition: o.Partition}, o.Offset)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// seedFloors, seedZones and seedDevices mirror the sample building of data/migrations/0002_seed_data.up.sql
+var (
+	seedFloors = []model.Floor{
+		{FloorNumber: 1, Description: "Ground Floor", TotalArea: 1000},
+		{FloorNumber: 2, Description: "First Floor", TotalArea: 1000},
+	}
+	seedZones = []model.Zone{
+		{FloorID: 1, Name: "Zone A", Type: "office", Description: "Main Office Space", Area: 400},
+		{FloorID: 1, Name: "Zone B", Type: "meeting", Description: "Meeting Rooms", Area: 300},
+		{FloorID: 2, Name: "Zone A", Type: "laboratory", Description: "Research Lab", Area: 500},
+		{FloorID: 2, Name: "Zone B", Type: "storage", Description: "Storage Area", Area: 200},
+	}
+	seedDevices = []model.IoTDevice{
+		{DeviceID: "TEMP_001", Name: "Temperature Sensor 1", Type: "temperature", Location: "Main Building", Floor: 1, Zone: 1},
+		{DeviceID: "HUM_001", Name: "Humidity Sensor 1", Type: "humidity", Location: "Main Building", Floor: 1, Zone: 1},
+		{DeviceID: "CO2_001", Name: "CO2 Sensor 1", Type: "co2", Location: "Main Building", Floor: 1, Zone: 1},
+		{DeviceID: "MULTI_001", Name: "Multi Sensor 1", Type: "multi", Location: "Main Building", Floor: 1, Zone: 1},
+		{DeviceID: "TEMP_002", Name: "Temperature Sensor 2", Type: "temperature", Location: "Main Building", Floor: 1, Zone: 2},
+		{DeviceID: "HUM_101", Name: "Humidity Sensor 1.5", Type: "humidity", Location: "Main Building", Floor: 1, Zone: 2},
+		{DeviceID: "HUM_002", Name: "Humidity Sensor 2", Type: "humidity", Location: "Main Building", Floor: 2, Zone: 3},
+		{DeviceID: "CO2_002", Name: "CO2 Sensor 2", Type: "co2", Location: "Main Building", Floor: 2, Zone: 3},
+		{DeviceID: "MULTI_002", Name: "Multi Sensor 2", Type: "multi", Location: "Main Building", Floor: 2, Zone: 4},
+		{DeviceID: "CO2_003", Name: "CO2 Sensor 3", Type: "co2", Location: "Main Building", Floor: 2, Zone: 4},
+	}
+)
+
+// Seed adds the sample floors, zones and devices the postgres migrations seed,
+// so processes sharing no storage still agree on the building
+func Seed(s *Store) error {
+	return s.write(func(t *tables) error {
+		for _, f := range seedFloors {
+			t.seq.floor++
+			f.ID = int(t.seq.floor)
+			f.CreatedAt, f.UpdatedAt = now(), now()
+			t.floors.put(f.ID, f)
+		}
+		for _, z := range seedZones {
+			t.seq.zone++
+			z.ID = int(t.seq.zone)
+			z.CreatedAt, z.UpdatedAt = now(), now()
+			t.zones.put(z.ID, z)
+		}
+		for _, d := range seedDevices {
+			t.seq.device++
+			d.ID = t.seq.device
+			d.IsActive = true
+			d.CreatedAt, d.UpdatedAt = now(), now()
+			t.devices.put(d.DeviceID, d)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,274 @@
+// Package memory is the in-process backend, selected with DB_BACKEND=memory.
+// It keeps nothing once the process exits.
+package memory
+
+import (
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// ErrTxDone means the transaction was already committed or rolled back
+var ErrTxDone = errors.New("memory transaction already done")
+
+// Store holds the tables of the in-process backend, it is safe for concurrent use.
+// Reads see the committed data only, the writers are serialized so a transaction
+// works on a snapshot nobody else writes to until it commits or rolls back.
+type Store struct {
+	writeMu sync.Mutex // held by a write outside of a transaction, or by a transaction until it ends
+	mu      sync.RWMutex
+	data    *tables
+
+	parent *Store // the store a transaction commits to, nil outside of a transaction
+	done   bool
+}
+
+// NewStore returns an empty store
+func NewStore() *Store {
+	return &Store{data: newTables()}
+}
+
+// Begin starts a transaction, the other writers wait until it is committed or rolled back
+func (s *Store) Begin() *Store {
+	s.writeMu.Lock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &Store{data: s.data.snapshot(), parent: s}
+}
+
+// Commit publishes the writes of the transaction to its parent store
+func (s *Store) Commit() error {
+	if s.parent == nil {
+		return errors.New("commit outside of a memory transaction")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return ErrTxDone
+	}
+	s.done = true
+
+	s.parent.mu.Lock()
+	s.parent.data.commit(s.data)
+	s.parent.mu.Unlock()
+	s.parent.writeMu.Unlock()
+	return nil
+}
+
+// Rollback drops the writes of the transaction, it is a no-op once the transaction is done
+func (s *Store) Rollback() {
+	if s.parent == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.parent.writeMu.Unlock()
+}
+
+// read runs fn with the tables, fn must not keep references to them
+func (s *Store) read(fn func(t *tables) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.done {
+		return ErrTxDone
+	}
+	return fn(s.data)
+}
+
+// write runs fn with the tables, the tables are left as they were when fn fails
+func (s *Store) write(fn func(t *tables) error) error {
+	if s.parent == nil {
+		// the writes of a transaction are already serialized by the lock it holds
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return ErrTxDone
+	}
+
+	snapshot := s.data.snapshot()
+	if err := fn(snapshot); err != nil {
+		return err
+	}
+	s.data.commit(snapshot)
+	return nil
+}
+
+// tables is the data of the store
+type tables struct {
+	users    table[string, model.User] // by email
+	devices  table[string, model.IoTDevice]
+	floors   table[int, model.Floor]
+	zones    table[int, model.Zone]
+	rules    table[int64, model.AlertRule]
+	alerts   table[int64, model.Alert]
+	offsets  table[offsetKey, int64]
+	readings *rowLog[model.SensorReading] // unique by message and timestamp
+	metrics  *rowLog[model.BenchmarkMetrics]
+	seq      sequences
+}
+
+// sequences generate the ids the database would assign
+type sequences struct {
+	user, device, floor, zone, reading int64
+}
+
+type offsetKey struct {
+	groupID   string
+	topic     string
+	partition int32
+}
+
+func newTables() *tables {
+	return &tables{
+		users:    newTable[string, model.User](),
+		devices:  newTable[string, model.IoTDevice](),
+		floors:   newTable[int, model.Floor](),
+		zones:    newTable[int, model.Zone](),
+		rules:    newTable[int64, model.AlertRule](),
+		alerts:   newTable[int64, model.Alert](),
+		offsets:  newTable[offsetKey, int64](),
+		readings: newRowLog(readingKey),
+		metrics:  newRowLog[model.BenchmarkMetrics](nil),
+	}
+}
+
+// snapshot returns tables sharing the data of t until they are written to
+func (t *tables) snapshot() *tables {
+	return &tables{
+		users:    t.users.snapshot(),
+		devices:  t.devices.snapshot(),
+		floors:   t.floors.snapshot(),
+		zones:    t.zones.snapshot(),
+		rules:    t.rules.snapshot(),
+		alerts:   t.alerts.snapshot(),
+		offsets:  t.offsets.snapshot(),
+		readings: t.readings.snapshot(),
+		metrics:  t.metrics.snapshot(),
+		seq:      t.seq,
+	}
+}
+
+// commit takes over the data of a snapshot of t
+func (t *tables) commit(snapshot *tables) {
+	t.users = t.users.commit(snapshot.users)
+	t.devices = t.devices.commit(snapshot.devices)
+	t.floors = t.floors.commit(snapshot.floors)
+	t.zones = t.zones.commit(snapshot.zones)
+	t.rules = t.rules.commit(snapshot.rules)
+	t.alerts = t.alerts.commit(snapshot.alerts)
+	t.offsets = t.offsets.commit(snapshot.offsets)
+	t.readings.commit(snapshot.readings)
+	t.metrics.commit(snapshot.metrics)
+	t.seq = snapshot.seq
+}
+
+// table is a map shared with the snapshots taken of it, it is copied on the first write of a snapshot
+type table[K comparable, V any] struct {
+	rows   map[K]V
+	shared bool
+}
+
+func newTable[K comparable, V any]() table[K, V] {
+	return table[K, V]{rows: map[K]V{}}
+}
+
+func (t table[K, V]) snapshot() table[K, V] {
+	return table[K, V]{rows: t.rows, shared: true}
+}
+
+// commit returns the table to replace t with, t itself if the snapshot wasn't written to
+func (t table[K, V]) commit(snapshot table[K, V]) table[K, V] {
+	if snapshot.shared {
+		return t
+	}
+	return snapshot
+}
+
+func (t table[K, V]) get(k K) (V, bool) {
+	v, ok := t.rows[k]
+	return v, ok
+}
+
+func (t *table[K, V]) put(k K, v V) {
+	if t.shared {
+		t.rows = maps.Clone(t.rows)
+		t.shared = false
+	}
+	t.rows[k] = v
+}
+
+// rowLog is an append-only table. The log of a snapshot only holds the rows appended
+// to it, the committed ones are read from its base. The rows of a keyed log are unique.
+type rowLog[T any] struct {
+	base *rowLog[T]
+	rows []T
+	key  func(T) string
+	keys map[string]struct{}
+}
+
+func newRowLog[T any](key func(T) string) *rowLog[T] {
+	l := &rowLog[T]{key: key}
+	if key != nil {
+		l.keys = map[string]struct{}{}
+	}
+	return l
+}
+
+func (l *rowLog[T]) snapshot() *rowLog[T] {
+	s := newRowLog(l.key)
+	s.base = l
+	return s
+}
+
+func (l *rowLog[T]) has(k string) bool {
+	if _, ok := l.keys[k]; ok {
+		return true
+	}
+	return l.base != nil && l.base.has(k)
+}
+
+// insert appends the row, a row of a keyed log is skipped if its key is already stored
+func (l *rowLog[T]) insert(r T) bool {
+	if l.key != nil {
+		k := l.key(r)
+		if l.has(k) {
+			return false
+		}
+		l.keys[k] = struct{}{}
+	}
+	l.rows = append(l.rows, r)
+	return true
+}
+
+// each calls fn with every row, in insertion order
+func (l *rowLog[T]) each(fn func(r T)) {
+	if l.base != nil {
+		l.base.each(fn)
+	}
+	for _, r := range l.rows {
+		fn(r)
+	}
+}
+
+// commit appends the rows appended to a snapshot of l
+func (l *rowLog[T]) commit(snapshot *rowLog[T]) {
+	for _, r := range snapshot.rows {
+		l.insert(r)
+	}
+}
+
+// now is the time the rows are created or updated at, in the precision postgres keeps
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
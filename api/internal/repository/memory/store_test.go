@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTx(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tcs := map[string]struct {
+		givenCommit bool
+		expDevice   error
+		expReadings int64
+		expOffsets  map[int32]int64
+	}{
+		"commit": {
+			givenCommit: true,
+			expReadings: 2,
+			expOffsets:  map[int32]int64{0: 10},
+		},
+		"rollback": {
+			expDevice:   iotsystem.ErrNotFound,
+			expReadings: 1,
+			expOffsets:  map[int32]int64{},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewStore()
+
+			// Given:
+			require.NoError(t, NewIoT(s).BatchInsertReadings(ctx, []model.SensorReading{
+				{DeviceID: "TEMP_001", Timestamp: ts, MessageID: "m-1"},
+			}))
+
+			// When:
+			tx := s.Begin()
+			_, err := NewIoT(tx).CreateDevice(ctx, model.IoTDevice{DeviceID: "TEMP_001"})
+			require.NoError(t, err)
+			require.NoError(t, NewIoT(tx).BatchInsertReadings(ctx, []model.SensorReading{
+				{DeviceID: "TEMP_001", Timestamp: ts, MessageID: "m-1"},
+				{DeviceID: "TEMP_001", Timestamp: ts.Add(time.Second), MessageID: "m-2"},
+			}))
+			require.NoError(t, NewOffset(tx).SaveOffsets(ctx, []model.ConsumerOffset{
+				{GroupID: "g", Topic: "iot.sensor", Partition: 0, Offset: 10},
+			}))
+
+			// Then: the writes of the transaction are only seen by it until it commits
+			count, err := NewIoT(tx).CountReadings(ctx, model.GetReadingsInput{})
+			require.NoError(t, err)
+			require.Equal(t, int64(2), count)
+			count, err = NewIoT(s).CountReadings(ctx, model.GetReadingsInput{})
+			require.NoError(t, err)
+			require.Equal(t, int64(1), count)
+
+			if tc.givenCommit {
+				require.NoError(t, tx.Commit())
+			} else {
+				tx.Rollback()
+			}
+
+			_, err = NewIoT(s).GetDevice(ctx, "TEMP_001")
+			require.Equal(t, tc.expDevice, err)
+			count, err = NewIoT(s).CountReadings(ctx, model.GetReadingsInput{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expReadings, count)
+			offsets, err := NewOffset(s).GetOffsets(ctx, "g", "iot.sensor")
+			require.NoError(t, err)
+			require.Equal(t, tc.expOffsets, offsets)
+
+			_, err = NewIoT(tx).GetDevices(ctx)
+			require.ErrorIs(t, err, ErrTxDone)
+			require.ErrorIs(t, tx.Commit(), ErrTxDone)
+
+			// the store takes writes again
+			require.NoError(t, NewOffset(s).SaveOffsets(ctx, []model.ConsumerOffset{{GroupID: "g", Topic: "iot.sensor"}}))
+		})
+	}
+}
+
+func TestStoreWriteAtomic(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+	alerts := NewAlert(s)
+
+	// Given:
+	require.NoError(t, alerts.CreateAlerts(ctx, []model.Alert{{ID: 2}}))
+
+	// When:
+	err := alerts.CreateAlerts(ctx, []model.Alert{{ID: 1}, {ID: 2}})
+
+	// Then: a failed write leaves nothing behind
+	require.EqualError(t, err, "failed to insert alerts: id 2 already exists")
+	_, err = alerts.GetAlert(ctx, 1)
+	require.Equal(t, alert.ErrNotFound, err)
+}
+
+func TestStoreConcurrentTx(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+	ts := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// When: transactions and plain writes race
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tx := s.Begin()
+			defer tx.Rollback()
+			_ = NewIoT(tx).BatchInsertReadings(ctx, []model.SensorReading{
+				{DeviceID: "TEMP_001", Timestamp: ts, MessageID: fmt.Sprintf("tx-%d", i)},
+			})
+			if i%2 == 0 {
+				_ = tx.Commit()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = NewIoT(s).BatchInsertReadings(ctx, []model.SensorReading{
+				{DeviceID: "TEMP_002", Timestamp: ts, MessageID: fmt.Sprintf("w-%d", i)},
+			})
+			_, _ = NewIoT(s).GetLatestReadings(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// Then: only the committed transactions are kept, each reading got its own id
+	count, err := NewIoT(s).CountReadings(ctx, model.GetReadingsInput{})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), count)
+	readings, _, err := NewIoT(s).GetReadings(ctx, model.GetReadingsInput{})
+	require.NoError(t, err)
+	ids := map[int64]bool{}
+	for _, r := range readings {
+		ids[r.ID] = true
+	}
+	require.Len(t, ids, 30)
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
+)
+
+// NewTopology returns the floors and zones repo of the store
+func NewTopology(s *Store) topology.Repository {
+	return topologyImpl{s: s}
+}
+
+type topologyImpl struct {
+	s *Store
+}
+
+// GetFloors retrieves all floors ordered by floor number
+func (r topologyImpl) GetFloors(_ context.Context) ([]model.Floor, error) {
+	var floors []model.Floor
+	err := r.s.read(func(t *tables) error {
+		for _, f := range t.floors.rows {
+			floors = append(floors, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(floors, func(i, j int) bool { return floors[i].FloorNumber < floors[j].FloorNumber })
+	return floors, nil
+}
+
+// GetFloor retrieves a floor by id
+func (r topologyImpl) GetFloor(_ context.Context, floorID int) (model.Floor, error) {
+	var floor model.Floor
+	err := r.s.read(func(t *tables) error {
+		var ok bool
+		if floor, ok = t.floors.get(floorID); !ok {
+			return topology.ErrNotFound
+		}
+		return nil
+	})
+	return floor, err
+}
+
+// CreateFloor inserts a floor, the floor number must be unique
+func (r topologyImpl) CreateFloor(_ context.Context, floor model.Floor) (model.Floor, error) {
+	err := r.s.write(func(t *tables) error {
+		if floorNumberTaken(t, floor) {
+			return topology.ErrAlreadyExists
+		}
+		t.seq.floor++
+		floor.ID = int(t.seq.floor)
+		floor.CreatedAt = now()
+		floor.UpdatedAt = floor.CreatedAt
+		t.floors.put(floor.ID, floor)
+		return nil
+	})
+	if err != nil {
+		return model.Floor{}, err
+	}
+	return floor, nil
+}
+
+// UpdateFloor overwrites the number, description and area of a floor
+func (r topologyImpl) UpdateFloor(_ context.Context, floor model.Floor) (model.Floor, error) {
+	var updated model.Floor
+	err := r.s.write(func(t *tables) error {
+		var ok bool
+		if updated, ok = t.floors.get(floor.ID); !ok {
+			return topology.ErrNotFound
+		}
+		if floorNumberTaken(t, floor) {
+			return topology.ErrAlreadyExists
+		}
+		updated.FloorNumber = floor.FloorNumber
+		updated.Description = floor.Description
+		updated.TotalArea = floor.TotalArea
+		updated.UpdatedAt = now()
+		t.floors.put(updated.ID, updated)
+		return nil
+	})
+	if err != nil {
+		return model.Floor{}, err
+	}
+	return updated, nil
+}
+
+// GetFloorDevices retrieves all devices of a floor, active or not
+func (r topologyImpl) GetFloorDevices(_ context.Context, floorID int) ([]model.IoTDevice, error) {
+	var devices []model.IoTDevice
+	err := r.s.read(func(t *tables) error {
+		for _, d := range t.devices.rows {
+			if d.Floor == floorID {
+				devices = append(devices, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortDevices(devices)
+	return devices, nil
+}
+
+// GetZones retrieves the zones of a floor
+func (r topologyImpl) GetZones(_ context.Context, floorID int) ([]model.Zone, error) {
+	var zones []model.Zone
+	err := r.s.read(func(t *tables) error {
+		for _, z := range t.zones.rows {
+			if z.FloorID == floorID {
+				zones = append(zones, z)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(zones, func(i, j int) bool { return zones[i].ID < zones[j].ID })
+	return zones, nil
+}
+
+// GetZone retrieves a zone, it is not found if it does not belong to the floor
+func (r topologyImpl) GetZone(_ context.Context, floorID, zoneID int) (model.Zone, error) {
+	var zone model.Zone
+	err := r.s.read(func(t *tables) error {
+		var ok bool
+		if zone, ok = t.zones.get(zoneID); !ok || zone.FloorID != floorID {
+			return topology.ErrNotFound
+		}
+		return nil
+	})
+	return zone, err
+}
+
+// CreateZone inserts a zone, the zone name must be unique within its floor
+func (r topologyImpl) CreateZone(_ context.Context, zone model.Zone) (model.Zone, error) {
+	err := r.s.write(func(t *tables) error {
+		if _, ok := t.floors.get(zone.FloorID); !ok {
+			return fmt.Errorf("failed to insert zone %s of floor %d: unknown floor", zone.Name, zone.FloorID)
+		}
+		if zoneNameTaken(t, zone) {
+			return topology.ErrAlreadyExists
+		}
+		t.seq.zone++
+		zone.ID = int(t.seq.zone)
+		zone.CreatedAt = now()
+		zone.UpdatedAt = zone.CreatedAt
+		t.zones.put(zone.ID, zone)
+		return nil
+	})
+	if err != nil {
+		return model.Zone{}, err
+	}
+	return zone, nil
+}
+
+// UpdateZone overwrites the name, type, description and area of a zone, zones can't move between floors
+func (r topologyImpl) UpdateZone(_ context.Context, zone model.Zone) (model.Zone, error) {
+	var updated model.Zone
+	err := r.s.write(func(t *tables) error {
+		var ok bool
+		if updated, ok = t.zones.get(zone.ID); !ok || updated.FloorID != zone.FloorID {
+			return topology.ErrNotFound
+		}
+		if zoneNameTaken(t, zone) {
+			return topology.ErrAlreadyExists
+		}
+		updated.Name = zone.Name
+		updated.Type = zone.Type
+		updated.Description = zone.Description
+		updated.Area = zone.Area
+		updated.UpdatedAt = now()
+		t.zones.put(updated.ID, updated)
+		return nil
+	})
+	if err != nil {
+		return model.Zone{}, err
+	}
+	return updated, nil
+}
+
+// floorNumberTaken checks whether another floor has the number of floor
+func floorNumberTaken(t *tables, floor model.Floor) bool {
+	for _, f := range t.floors.rows {
+		if f.FloorNumber == floor.FloorNumber && f.ID != floor.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneNameTaken checks whether another zone of the floor of zone has its name
+func zoneNameTaken(t *tables, zone model.Zone) bool {
+	for _, z := range t.zones.rows {
+		if z.FloorID == zone.FloorID && z.Name == zone.Name && z.ID != zone.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/user"
+)
+
+// NewUser returns the users repo of the store
+func NewUser(s *Store) user.Repository {
+	return userImpl{s: s}
+}
+
+type userImpl struct {
+	s *Store
+}
+
+// GetByEmail retrieves a user by email, the email is looked up in lower case like on postgres
+func (r userImpl) GetByEmail(_ context.Context, inp user.GetUserInput) (model.User, error) {
+	var u model.User
+	err := r.s.read(func(t *tables) error {
+		var ok bool
+		if u, ok = t.users.get(strings.ToLower(inp.Email)); !ok {
+			return user.ErrNotFound
+		}
+		return nil
+	})
+	return u, err
+}
+
+// Create stores a user, the email must be unique
+func (r userImpl) Create(_ context.Context, u *model.User) error {
+	return r.s.write(func(t *tables) error {
+		if _, ok := t.users.get(u.Email); ok {
+			return user.ErrAlreadyExists
+		}
+		t.seq.user++
+		u.ID = t.seq.user
+		t.users.put(u.Email, *u)
+		return nil
+	})
+}
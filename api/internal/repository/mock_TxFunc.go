@@ -2,20 +2,24 @@
 
 package repository
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockTxFunc is an autogenerated mock type for the TxFunc type
 type MockTxFunc struct {
 	mock.Mock
 }
 
-// Execute provides a mock function with given fields: txRegistry
-func (_m *MockTxFunc) Execute(txRegistry Registry) error {
-	ret := _m.Called(txRegistry)
+// Execute provides a mock function with given fields: ctx, txRegistry
+func (_m *MockTxFunc) Execute(ctx context.Context, txRegistry Registry) error {
+	ret := _m.Called(ctx, txRegistry)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(Registry) error); ok {
-		r0 = rf(txRegistry)
+	if rf, ok := ret.Get(0).(func(context.Context, Registry) error); ok {
+		r0 = rf(ctx, txRegistry)
 	} else {
 		r0 = ret.Error(0)
 	}
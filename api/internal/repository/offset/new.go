@@ -0,0 +1,25 @@
+package offset
+
+import (
+	"context"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// Repository provides the specification of the consumer offsets storage
+type Repository interface {
+	GetOffsets(ctx context.Context, groupID, topic string) (map[int32]int64, error)
+	SaveOffsets(ctx context.Context, offsets []model.ConsumerOffset) error
+}
+
+// New returns an implementation instance satisfying Repository
+func New(dbConn boil.ContextExecutor) Repository {
+	return impl{
+		dbConn: dbConn,
+	}
+}
+
+type impl struct {
+	dbConn boil.ContextExecutor
+}
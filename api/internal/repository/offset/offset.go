@@ -0,0 +1,49 @@
+package offset
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+// GetOffsets retrieves the stored offsets of the group by partition of the topic
+func (r impl) GetOffsets(ctx context.Context, groupID, topic string) (map[int32]int64, error) {
+	rows, err := dbmodel.ConsumerOffsets(
+		dbmodel.ConsumerOffsetWhere.GroupID.EQ(groupID),
+		dbmodel.ConsumerOffsetWhere.Topic.EQ(topic),
+	).All(ctx, r.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consumer offsets: %w", err)
+	}
+
+	offsets := make(map[int32]int64, len(rows))
+	for _, o := range rows {
+		offsets[int32(o.Partition)] = o.NextOffset
+	}
+
+	return offsets, nil
+}
+
+// SaveOffsets upserts the offsets, meant to be called in the transaction writing the consumed data
+func (r impl) SaveOffsets(ctx context.Context, offsets []model.ConsumerOffset) error {
+	for _, o := range offsets {
+		row := dbmodel.ConsumerOffset{
+			GroupID:    o.GroupID,
+			Topic:      o.Topic,
+			Partition:  int(o.Partition),
+			NextOffset: o.Offset,
+		}
+		if err := row.Upsert(ctx, r.dbConn, true,
+			[]string{dbmodel.ConsumerOffsetColumns.GroupID, dbmodel.ConsumerOffsetColumns.Topic, dbmodel.ConsumerOffsetColumns.Partition},
+			boil.Whitelist(dbmodel.ConsumerOffsetColumns.NextOffset, dbmodel.ConsumerOffsetColumns.UpdatedAt),
+			boil.Infer(),
+		); err != nil {
+			return fmt.Errorf("failed to save consumer offset of %s[%d]: %w", o.Topic, o.Partition, err)
+		}
+	}
+
+	return nil
+}
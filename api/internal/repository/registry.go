@@ -7,8 +7,16 @@ import (
 	"fmt"
 
 	"github.com/gocql/gocql"
+	"github.com/nhan1603/IoTsystem/api/internal/pkg/cassandra"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/alert"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/cassalert"
 	"github.com/nhan1603/IoTsystem/api/internal/repository/cassiot"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/casstopology"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/cassuser"
 	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/memory"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/offset"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/topology"
 	"github.com/nhan1603/IoTsystem/api/internal/repository/user"
 	pkgerrors "github.com/pkg/errors"
 	"github.com/scylladb/gocqlx/v2"
@@ -18,27 +26,50 @@ import (
 type Registry interface {
 	User() user.Repository
 	IoT() iotsystem.Repository
+	Topology() topology.Repository
+	Alert() alert.Repository
+	Offset() offset.Repository
 	DoInTx(ctx context.Context, txFunc TxFunc) error
 }
 
 // newPostGres returns an implementation instance which satisfying Registry
 func newPostGres(pgConn *sql.DB) Registry {
 	return impl{
-		Backend: BackendPostgres,
-		user:    user.New(pgConn),
-		iot:     iotsystem.New(pgConn),
-		pgConn:  pgConn,
+		Backend:  BackendPostgres,
+		user:     user.New(pgConn),
+		iot:      iotsystem.New(pgConn),
+		topology: topology.New(pgConn),
+		alert:    alert.New(pgConn),
+		offset:   offset.New(pgConn),
+		pgConn:   pgConn,
 	}
 }
 
-// newCassandraImpl builds a Cassandra registry (session-bound).
-func newCassandraImpl(sess *gocql.Session) impl {
+// newCassandraImpl builds a Cassandra registry (session-bound) storing the readings in the tables of layout.
+func newCassandraImpl(sess *gocql.Session, layout cassiot.Layout) impl {
 	return impl{
 		Backend:     BackendCassandra,
 		cassSession: sess,
 		// Instantiate repos bound to the session
-		user: nil,
-		iot:  cassiot.NewCassandra(gocqlx.NewSession(sess)),
+		user:     cassuser.NewCassandra(gocqlx.NewSession(sess)),
+		iot:      cassiot.NewCassandra(gocqlx.NewSession(sess), layout),
+		topology: casstopology.NewCassandra(gocqlx.NewSession(sess)),
+		alert:    cassalert.NewCassandra(gocqlx.NewSession(sess)),
+		// No transactions to store consumer offsets atomically with the readings
+		offset: nil,
+	}
+}
+
+// newMemory builds a registry over the tables of the store
+func newMemory(store *memory.Store) impl {
+	return impl{
+		Backend:  BackendMemory,
+		user:     memory.NewUser(store),
+		iot:      memory.NewIoT(store),
+		topology: memory.NewTopology(store),
+		alert:    memory.NewAlert(store),
+		offset:   memory.NewOffset(store),
+		memStore: store,
 	}
 }
 
@@ -46,17 +77,26 @@ type impl struct {
 	Backend     Backend
 	user        user.Repository
 	iot         iotsystem.Repository
+	topology    topology.Repository
+	alert       alert.Repository
+	offset      offset.Repository
 	txExec      boil.Transactor
 	pgConn      *sql.DB
 	cassSession *gocql.Session
 	inCassBatch bool
+	memStore    *memory.Store
+	inMemTx     bool
 }
 
-// TxFunc is a function that can be executed in a transaction
-type TxFunc func(txRegistry Registry) error
+// TxFunc is a function that can be executed in a transaction, the repos of txRegistry
+// must be called with ctx for their writes to join it
+type TxFunc func(ctx context.Context, txRegistry Registry) error
 
-// Add new type for Cassandra batch operations
-type CassandraBatchFunc func(session *gocqlx.Session) error
+// WithCassandraConsistency sets the consistency level ("ONE", "QUORUM", ...) of the writes
+// of the Cassandra DoInTx scopes started with ctx, other backends ignore it
+func WithCassandraConsistency(ctx context.Context, level string) context.Context {
+	return cassandra.WithConsistency(ctx, parseConsistency(level))
+}
 
 // User returns user repo
 func (i impl) User() user.Repository {
@@ -67,6 +107,21 @@ func (i impl) IoT() iotsystem.Repository {
 	return i.iot
 }
 
+// Topology returns floors and zones repo
+func (i impl) Topology() topology.Repository {
+	return i.topology
+}
+
+// Alert returns alert rules and alerts repo
+func (i impl) Alert() alert.Repository {
+	return i.alert
+}
+
+// Offset returns consumer offsets repo, nil when the backend can't store them transactionally
+func (i impl) Offset() offset.Repository {
+	return i.offset
+}
+
 // DoInTx handles db operations in a transaction
 func (i impl) DoInTx(ctx context.Context, txFunc TxFunc) error {
 	switch i.Backend {
@@ -90,12 +145,15 @@ func (i impl) DoInTx(ctx context.Context, txFunc TxFunc) error {
 		}()
 
 		newI := impl{
-			user:   user.New(tx),
-			iot:    iotsystem.New(tx),
-			txExec: tx,
+			user:     user.New(tx),
+			iot:      iotsystem.New(tx),
+			topology: topology.New(tx),
+			alert:    alert.New(tx),
+			offset:   offset.New(tx),
+			txExec:   tx,
 		}
 
-		if err = txFunc(newI); err != nil {
+		if err = txFunc(ctx, newI); err != nil {
 			return err
 		}
 
@@ -108,35 +166,55 @@ func (i impl) DoInTx(ctx context.Context, txFunc TxFunc) error {
 		return nil
 	case BackendCassandra:
 		// prevent nested batch scopes
-		if i.inCassBatch {
+		if _, ok := cassandra.BatchFrom(ctx); ok || i.inCassBatch {
 			return errors.New("cassandra batch nested in cassandra batch")
 		}
 		if i.cassSession == nil {
 			return errors.New("cassandra session not initialized")
 		}
 
-		batch := i.cassSession.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+		batch := cassandra.NewBatch()
+		if c, ok := cassandra.ConsistencyFrom(ctx); ok {
+			batch.SetConsistency(c)
+		}
 
-		// child impl carries the "batch in ctx"
-		child := impl{
-			Backend:     i.Backend,
-			pgConn:      i.pgConn,
-			txExec:      nil,
-			cassSession: i.cassSession,
-			inCassBatch: true,
+		// The repos add their writes to the batch of the ctx, nothing is written
+		// before the scope ends
+		child := i
+		child.inCassBatch = true
 
-			// Cassandra repos can stay the same instance if they read ctx,
-			// or you can re-new them if you keep per-impl state. Example keeps them:
-			user: i.user,
-			iot:  i.iot,
+		if err := txFunc(cassandra.WithBatch(ctx, batch), child); err != nil {
+			return err
+		}
+		return batch.Exec(ctx, i.cassSession)
+	case BackendMemory:
+		if i.inMemTx {
+			return errors.New("memory tx nested in memory tx")
 		}
 
-		if err := txFunc(child); err != nil {
+		tx := i.memStore.Begin()
+		var committed bool
+		defer func() {
+			if committed {
+				return
+			}
+
+			tx.Rollback()
+		}()
+
+		child := newMemory(tx)
+		child.inMemTx = true
+
+		if err := txFunc(ctx, child); err != nil {
 			return err
 		}
-		if err := i.cassSession.ExecuteBatch(batch); err != nil {
-			return fmt.Errorf("execute cassandra batch: %w", err)
+
+		if err := tx.Commit(); err != nil {
+			return err
 		}
+
+		committed = true
+
 		return nil
 	default:
 		return fmt.Errorf("unknown backend: %s", i.Backend)
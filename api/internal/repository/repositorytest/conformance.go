@@ -0,0 +1,95 @@
+// Package repositorytest checks that a backend behaves like the others behind repository.Registry.
+// Every backend runs the same suite, see Run.
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+)
+
+// Factory returns a registry of the backend under test. The backend must hold the sample
+// building of the seed migration (floors 1 and 2, zones 1 to 4), the rest of its data is
+// left alone: the suite only writes and looks up rows of its own namespace.
+type Factory func(t *testing.T) repository.Registry
+
+// Run runs the conformance suite against the backend of newRegistry
+func Run(t *testing.T, newRegistry Factory) {
+	tests := map[string]func(t *testing.T, s suite){
+		"devices":           testDevices,
+		"readings_filters":  testReadingsFilters,
+		"readings_pages":    testReadingsPages,
+		"readings_dedupe":   testReadingsDedupe,
+		"latest_readings":   testLatestReadings,
+		"aggregate":         testAggregateReadings,
+		"benchmark_metrics": testBenchmarkMetrics,
+		"users":             testUsers,
+		"tx_commit":         testTxCommit,
+		"tx_rollback":       testTxRollback,
+	}
+	names := make([]string, 0, len(tests))
+	for name := range tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			tests[name](t, suite{
+				repo: newRegistry(t),
+				ns:   fmt.Sprintf("conf%d", time.Now().UnixNano()),
+			})
+		})
+	}
+}
+
+// suite is the state of one conformance test
+type suite struct {
+	repo repository.Registry
+	// ns prefixes the keys of the rows written by the test, so it only sees its own
+	ns string
+}
+
+// id returns the key in the namespace of the test
+func (s suite) id(key string) string {
+	return s.ns + "_" + key
+}
+
+// baseTime is the time of the readings, at millisecond precision like Cassandra timestamps
+var baseTime = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// reading builds a temperature reading of the device of the namespace, taken offset after baseTime
+// in zone 1 of floor 1. The humidity and CO2 are derived from the temperature.
+func (s suite) reading(device string, offset time.Duration, temperature float64) model.SensorReading {
+	return model.SensorReading{
+		DeviceID:    s.id(device),
+		DeviceName:  device,
+		DeviceType:  s.id("temp"),
+		Location:    s.id("north"),
+		Floor:       1,
+		Zone:        1,
+		Temperature: temperature,
+		Humidity:    temperature * 2,
+		CO2:         temperature * 20,
+		Timestamp:   baseTime.Add(offset),
+		CreatedAt:   baseTime.Add(offset),
+		MessageID:   s.id(fmt.Sprintf("%s-%d", device, offset.Milliseconds())),
+	}
+}
+
+// normalized strips what a backend may generate or leave out from readings, to compare them by value
+func normalized(readings []model.SensorReading) []model.SensorReading {
+	res := make([]model.SensorReading, len(readings))
+	for i, r := range readings {
+		r.ID = 0
+		r.MessageID = ""
+		r.CreatedAt = time.Time{}
+		r.Timestamp = r.Timestamp.UTC()
+		res[i] = r
+	}
+	return res
+}
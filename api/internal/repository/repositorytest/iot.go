@@ -0,0 +1,334 @@
+package repositorytest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/stretchr/testify/require"
+)
+
+func testDevices(t *testing.T, s suite) {
+	ctx := context.Background()
+	repo := s.repo.IoT()
+	a := model.IoTDevice{DeviceID: s.id("A"), Name: "A", Type: "humidity", Location: "Main Building", Floor: 2, Zone: 3}
+	b := model.IoTDevice{DeviceID: s.id("B"), Name: "B", Type: "temperature", Location: "Main Building", Floor: 1, Zone: 1}
+	missing := model.IoTDevice{DeviceID: s.id("missing"), Floor: 1, Zone: 1}
+
+	// When:
+	for _, d := range []model.IoTDevice{b, a} {
+		created, err := repo.CreateDevice(ctx, d)
+		require.NoError(t, err)
+		d.IsActive = true
+		require.Equal(t, d, deviceFields(created))
+	}
+	_, err := repo.CreateDevice(ctx, b)
+	require.ErrorIs(t, err, iotsystem.ErrAlreadyExists)
+
+	b.Name, b.Floor, b.Zone = "B2", 2, 4
+	updated, err := repo.UpdateDevice(ctx, b)
+	require.NoError(t, err)
+	b.IsActive = true
+	require.Equal(t, b, deviceFields(updated))
+	_, err = repo.UpdateDevice(ctx, missing)
+	require.ErrorIs(t, err, iotsystem.ErrNotFound)
+
+	require.NoError(t, repo.SetDeviceActive(ctx, a.DeviceID, false))
+	require.ErrorIs(t, repo.SetDeviceActive(ctx, missing.DeviceID, false), iotsystem.ErrNotFound)
+
+	// Then: inactive devices are only listed when asked for by id
+	got, err := repo.GetDevice(ctx, a.DeviceID)
+	require.NoError(t, err)
+	require.False(t, got.IsActive)
+	got, err = repo.GetDevice(ctx, b.DeviceID)
+	require.NoError(t, err)
+	require.Equal(t, b, deviceFields(got))
+	_, err = repo.GetDevice(ctx, missing.DeviceID)
+	require.ErrorIs(t, err, iotsystem.ErrNotFound)
+	require.Equal(t, []string{b.DeviceID}, s.activeDeviceIDs(t))
+
+	require.NoError(t, repo.SetDeviceActive(ctx, a.DeviceID, true))
+	require.Equal(t, []string{a.DeviceID, b.DeviceID}, s.activeDeviceIDs(t))
+}
+
+// activeDeviceIDs lists the active devices of the namespace in the order they are returned
+func (s suite) activeDeviceIDs(t *testing.T) []string {
+	devices, err := s.repo.IoT().GetDevices(context.Background())
+	require.NoError(t, err)
+
+	var ids []string
+	for _, d := range devices {
+		require.True(t, d.IsActive)
+		if strings.HasPrefix(d.DeviceID, s.ns) {
+			ids = append(ids, d.DeviceID)
+		}
+	}
+	return ids
+}
+
+// deviceFields strips what the backend generates from a device
+func deviceFields(d model.IoTDevice) model.IoTDevice {
+	d.ID = 0
+	d.CreatedAt = time.Time{}
+	d.UpdatedAt = time.Time{}
+	return d
+}
+
+func testReadingsFilters(t *testing.T, s suite) {
+	ctx := context.Background()
+	a := s.reading("T1", 0, 20)
+	b := s.reading("T1", time.Minute, 21)
+	c := s.reading("H1", 30*time.Second, 22)
+	c.DeviceType, c.Zone = s.id("hum"), 2
+	d := s.reading("H2", 2*time.Minute, 23)
+	d.DeviceType, d.Location, d.Floor, d.Zone = s.id("hum"), s.id("south"), 2, 3
+
+	// Given:
+	require.NoError(t, s.repo.IoT().BatchInsertReadings(ctx, []model.SensorReading{a, b, c, d}))
+
+	tcs := map[string]struct {
+		givenInput model.GetReadingsInput
+		expResult  []model.SensorReading
+	}{
+		"device": {
+			givenInput: model.GetReadingsInput{DeviceID: s.id("T1")},
+			expResult:  []model.SensorReading{b, a},
+		},
+		"device_type": {
+			givenInput: model.GetReadingsInput{DeviceType: s.id("hum")},
+			expResult:  []model.SensorReading{d, c},
+		},
+		"location": {
+			givenInput: model.GetReadingsInput{Location: s.id("north")},
+			expResult:  []model.SensorReading{b, c, a},
+		},
+		"floor": {
+			givenInput: model.GetReadingsInput{DeviceType: s.id("hum"), Floor: 2},
+			expResult:  []model.SensorReading{d},
+		},
+		"zone": {
+			givenInput: model.GetReadingsInput{Location: s.id("north"), Zone: 2},
+			expResult:  []model.SensorReading{c},
+		},
+		"time_range_inclusive": {
+			givenInput: model.GetReadingsInput{Location: s.id("north"), StartTime: c.Timestamp, EndTime: b.Timestamp},
+			expResult:  []model.SensorReading{b, c},
+		},
+		"no_match": {
+			givenInput: model.GetReadingsInput{DeviceID: s.id("T1"), Floor: 2},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			// When:
+			readings, next, err := s.repo.IoT().GetReadings(ctx, tc.givenInput)
+			require.NoError(t, err)
+			count, err := s.repo.IoT().CountReadings(ctx, tc.givenInput)
+			require.NoError(t, err)
+
+			// Then:
+			require.Empty(t, next)
+			require.Equal(t, normalized(tc.expResult), normalized(readings))
+			require.Equal(t, int64(len(tc.expResult)), count)
+		})
+	}
+}
+
+func testReadingsPages(t *testing.T, s suite) {
+	ctx := context.Background()
+	readings := []model.SensorReading{
+		s.reading("T1", 0, 20),
+		s.reading("T1", time.Minute, 21),
+		s.reading("T2", time.Minute, 22),
+		s.reading("T2", 2*time.Minute, 23),
+		s.reading("T1", 3*time.Minute, 24),
+	}
+
+	// Given:
+	require.NoError(t, s.repo.IoT().BatchInsertReadings(ctx, readings))
+
+	tcs := map[string]struct {
+		givenLimit int
+		expPages   [][]model.SensorReading
+	}{
+		"unlimited": {
+			expPages: [][]model.SensorReading{{readings[4], readings[3], readings[2], readings[1], readings[0]}},
+		},
+		"partial_last_page": {
+			givenLimit: 2,
+			expPages: [][]model.SensorReading{
+				{readings[4], readings[3]},
+				{readings[2], readings[1]},
+				{readings[0]},
+			},
+		},
+		"full_last_page": {
+			givenLimit: 5,
+			expPages:   [][]model.SensorReading{{readings[4], readings[3], readings[2], readings[1], readings[0]}},
+		},
+	}
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			input := model.GetReadingsInput{DeviceType: s.id("temp"), Limit: tc.givenLimit}
+			var pages [][]model.SensorReading
+			for len(pages) <= len(tc.expPages) {
+				// When:
+				page, next, err := s.repo.IoT().GetReadings(ctx, input)
+				require.NoError(t, err)
+				pages = append(pages, normalized(page))
+				if next == "" {
+					break
+				}
+				input.Cursor = next
+			}
+
+			// Then: newest first, ties broken by device id
+			require.Len(t, pages, len(tc.expPages))
+			for i := range tc.expPages {
+				require.Equal(t, normalized(tc.expPages[i]), pages[i], "page %d", i)
+			}
+		})
+	}
+
+	_, _, err := s.repo.IoT().GetReadings(ctx, model.GetReadingsInput{DeviceType: s.id("temp"), Cursor: "%%%"})
+	require.ErrorIs(t, err, iotsystem.ErrInvalidCursor)
+}
+
+func testReadingsDedupe(t *testing.T, s suite) {
+	ctx := context.Background()
+	repo := s.repo.IoT()
+	a := s.reading("T1", 0, 20)
+	sameTime := s.reading("T1", 0, 21)
+	sameTime.MessageID = s.id("other")
+	otherTime := a
+	otherTime.Timestamp = a.Timestamp.Add(time.Second)
+
+	// When: a message is delivered twice within a batch and again in the next one
+	require.NoError(t, repo.BatchInsertReadings(ctx, []model.SensorReading{a, a, sameTime}))
+	require.NoError(t, repo.BatchInsertReadings(ctx, []model.SensorReading{a, otherTime}))
+	require.NoError(t, repo.BatchInsertReadings(ctx, nil))
+
+	// Then: a reading is unique by message and timestamp
+	readings, _, err := repo.GetReadings(ctx, model.GetReadingsInput{DeviceID: a.DeviceID})
+	require.NoError(t, err)
+	require.Len(t, readings, 3)
+	require.Equal(t, normalized([]model.SensorReading{otherTime}), normalized(readings[:1]))
+	require.ElementsMatch(t, normalized([]model.SensorReading{a, sameTime}), normalized(readings[1:]))
+}
+
+func testLatestReadings(t *testing.T, s suite) {
+	ctx := context.Background()
+	t1 := s.reading("T1", time.Minute, 21)
+	h1 := s.reading("H1", 30*time.Second, 22)
+	h1.DeviceType, h1.Floor, h1.Zone = s.id("hum"), 2, 3
+
+	// Given:
+	require.NoError(t, s.repo.IoT().BatchInsertReadings(ctx, []model.SensorReading{
+		s.reading("T1", 0, 20), t1, h1,
+	}))
+
+	// When:
+	readings, err := s.repo.IoT().GetLatestReadings(ctx)
+	require.NoError(t, err)
+
+	// Then: one reading per device ordered by device id, with all its fields
+	var own []model.SensorReading
+	for _, r := range readings {
+		if strings.HasPrefix(r.DeviceID, s.ns) {
+			own = append(own, r)
+		}
+	}
+	require.Equal(t, normalized([]model.SensorReading{h1, t1}), normalized(own))
+}
+
+func testAggregateReadings(t *testing.T, s suite) {
+	ctx := context.Background()
+
+	// Given:
+	require.NoError(t, s.repo.IoT().BatchInsertReadings(ctx, []model.SensorReading{
+		s.reading("T1", 0, 20),
+		s.reading("T1", 30*time.Second, 22),
+		s.reading("T1", time.Minute, 30),
+		s.reading("T1", 2*time.Minute, 40),
+	}))
+
+	// When:
+	aggs, err := s.repo.IoT().AggregateReadings(ctx, model.AggregateReadingsInput{
+		Interval:  model.AggregateIntervalMinute,
+		GroupBy:   model.AggregateGroupByDevice,
+		DeviceID:  s.id("T1"),
+		StartTime: baseTime,
+		EndTime:   baseTime.Add(2 * time.Minute),
+	})
+	require.NoError(t, err)
+	_, err = s.repo.IoT().AggregateReadings(ctx, model.AggregateReadingsInput{
+		Interval: "2m",
+		GroupBy:  model.AggregateGroupByDevice,
+	})
+	require.Error(t, err)
+
+	// Then: the end of the range is excluded
+	for i := range aggs {
+		aggs[i].Bucket = aggs[i].Bucket.UTC()
+	}
+	require.Equal(t, []model.ReadingAggregate{
+		{
+			Bucket: baseTime, DeviceID: s.id("T1"), Count: 2,
+			Temperature: model.MetricAggregate{Avg: 21, Min: 20, Max: 22},
+			Humidity:    model.MetricAggregate{Avg: 42, Min: 40, Max: 44},
+			CO2:         model.MetricAggregate{Avg: 420, Min: 400, Max: 440},
+		},
+		{
+			Bucket: baseTime.Add(time.Minute), DeviceID: s.id("T1"), Count: 1,
+			Temperature: model.MetricAggregate{Avg: 30, Min: 30, Max: 30},
+			Humidity:    model.MetricAggregate{Avg: 60, Min: 60, Max: 60},
+			CO2:         model.MetricAggregate{Avg: 600, Min: 600, Max: 600},
+		},
+	}, aggs)
+}
+
+func testBenchmarkMetrics(t *testing.T, s suite) {
+	ctx := context.Background()
+	metrics := make([]model.BenchmarkMetrics, 3)
+	for i := range metrics {
+		metrics[i] = model.BenchmarkMetrics{
+			TotalRecords:     int64(100 * (i + 1)),
+			ProcessedRecords: int64(90 * (i + 1)),
+			FailedRecords:    int64(10 * (i + 1)),
+			StartTime:        baseTime.Add(time.Duration(i) * time.Minute),
+			EndTime:          baseTime.Add(time.Duration(i+1) * time.Minute),
+			AverageLatency:   1.5 * float64(i+1),
+			EndToEndLatency:  2.5 * float64(i+1),
+			Throughput:       100.25 * float64(i+1),
+			BatchSize:        50 * (i + 1),
+			DatabaseType:     s.ns,
+			CreatedAt:        baseTime.Add(time.Duration(i+1) * time.Minute),
+		}
+	}
+
+	// When:
+	for _, m := range metrics {
+		require.NoError(t, s.repo.IoT().SaveBenchmarkMetrics(ctx, m))
+	}
+	all, err := s.repo.IoT().GetBenchmarkMetrics(ctx, 0)
+	require.NoError(t, err)
+	limited, err := s.repo.IoT().GetBenchmarkMetrics(ctx, 1)
+	require.NoError(t, err)
+
+	// Then: newest first, the runs of the other tests aside
+	var got []model.BenchmarkMetrics
+	for _, m := range all {
+		if m.DatabaseType != s.ns {
+			continue
+		}
+		m.StartTime = m.StartTime.UTC()
+		m.EndTime = m.EndTime.UTC()
+		m.CreatedAt = m.CreatedAt.UTC()
+		got = append(got, m)
+	}
+	require.Equal(t, []model.BenchmarkMetrics{metrics[2], metrics[1], metrics[0]}, got)
+	require.Len(t, limited, 1)
+}
@@ -0,0 +1,64 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/iotsystem"
+	"github.com/stretchr/testify/require"
+)
+
+// writeInTx registers a device and stores readings of it through the registry of the transaction
+func (s suite) writeInTx(ctx context.Context, txRepo repository.Registry) error {
+	if _, err := txRepo.IoT().CreateDevice(ctx, model.IoTDevice{
+		DeviceID: s.id("T1"), Name: "T1", Type: "temperature", Location: "Main Building", Floor: 1, Zone: 1,
+	}); err != nil {
+		return err
+	}
+	return txRepo.IoT().BatchInsertReadings(ctx, []model.SensorReading{
+		s.reading("T1", 0, 20),
+		s.reading("T1", time.Minute, 21),
+	})
+}
+
+func testTxCommit(t *testing.T, s suite) {
+	ctx := context.Background()
+
+	// When:
+	err := s.repo.DoInTx(ctx, func(txCtx context.Context, txRepo repository.Registry) error {
+		return s.writeInTx(txCtx, txRepo)
+	})
+
+	// Then:
+	require.NoError(t, err)
+	_, err = s.repo.IoT().GetDevice(ctx, s.id("T1"))
+	require.NoError(t, err)
+	count, err := s.repo.IoT().CountReadings(ctx, model.GetReadingsInput{DeviceID: s.id("T1")})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
+
+func testTxRollback(t *testing.T, s suite) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	// When:
+	err := s.repo.DoInTx(ctx, func(txCtx context.Context, txRepo repository.Registry) error {
+		if err := s.writeInTx(txCtx, txRepo); err != nil {
+			return err
+		}
+		return errAbort
+	})
+
+	// Then: none of the writes of the failed transaction are kept
+	require.ErrorIs(t, err, errAbort)
+	_, err = s.repo.IoT().GetDevice(ctx, s.id("T1"))
+	require.ErrorIs(t, err, iotsystem.ErrNotFound)
+	count, err := s.repo.IoT().CountReadings(ctx, model.GetReadingsInput{DeviceID: s.id("T1")})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
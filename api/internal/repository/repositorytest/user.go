@@ -0,0 +1,34 @@
+package repositorytest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/user"
+	"github.com/stretchr/testify/require"
+)
+
+func testUsers(t *testing.T, s suite) {
+	ctx := context.Background()
+	repo := s.repo.User()
+	alice := model.User{Name: "Alice", Email: s.id("alice") + "@example.com", Password: "hash"}
+
+	// When:
+	created := alice
+	require.NoError(t, repo.Create(ctx, &created))
+	duplicate := alice
+	err := repo.Create(ctx, &duplicate)
+
+	// Then: emails are unique and looked up case insensitively
+	require.ErrorIs(t, err, user.ErrAlreadyExists)
+	got, err := repo.GetByEmail(ctx, user.GetUserInput{Email: strings.ToUpper(alice.Email)})
+	require.NoError(t, err)
+	require.NotZero(t, got.ID)
+	got.ID = 0
+	require.Equal(t, alice, got)
+
+	_, err = repo.GetByEmail(ctx, user.GetUserInput{Email: s.id("bob") + "@example.com"})
+	require.ErrorIs(t, err, user.ErrNotFound)
+}
@@ -0,0 +1,65 @@
+package topology
+
+import (
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+func toModelFloors(orms dbmodel.FloorSlice) []model.Floor {
+	floors := make([]model.Floor, len(orms))
+	for i, o := range orms {
+		floors[i] = toModelFloor(o)
+	}
+	return floors
+}
+
+func toModelFloor(o *dbmodel.Floor) model.Floor {
+	return model.Floor{
+		ID:          o.ID,
+		FloorNumber: o.FloorNumber,
+		Description: o.Description.String,
+		TotalArea:   o.TotalArea.Float64,
+		CreatedAt:   o.CreatedAt.Time,
+		UpdatedAt:   o.UpdatedAt.Time,
+	}
+}
+
+func toModelZones(orms dbmodel.ZoneSlice) []model.Zone {
+	zones := make([]model.Zone, len(orms))
+	for i, o := range orms {
+		zones[i] = toModelZone(o)
+	}
+	return zones
+}
+
+func toModelZone(o *dbmodel.Zone) model.Zone {
+	return model.Zone{
+		ID:          o.ID,
+		FloorID:     o.FloorID.Int,
+		Name:        o.ZoneName,
+		Type:        o.ZoneType,
+		Description: o.Description.String,
+		Area:        o.Area.Float64,
+		CreatedAt:   o.CreatedAt.Time,
+		UpdatedAt:   o.UpdatedAt.Time,
+	}
+}
+
+func toModelDevices(orms dbmodel.IotDeviceSlice) []model.IoTDevice {
+	devices := make([]model.IoTDevice, len(orms))
+	for i, o := range orms {
+		devices[i] = model.IoTDevice{
+			ID:        o.ID,
+			DeviceID:  o.DeviceID,
+			Name:      o.Name,
+			Type:      o.Type,
+			Location:  o.Location,
+			Floor:     o.FloorID,
+			Zone:      o.ZoneID,
+			IsActive:  o.IsActive.Bool,
+			CreatedAt: o.CreatedAt.Time,
+			UpdatedAt: o.UpdatedAt.Time,
+		}
+	}
+	return devices
+}
@@ -0,0 +1,10 @@
+package topology
+
+import "errors"
+
+var (
+	// ErrNotFound means the item was not found
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means an item with the same unique key already exists
+	ErrAlreadyExists = errors.New("already exists")
+)
@@ -0,0 +1,106 @@
+package topology
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/lib/pq"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+// pgUniqueViolation is the postgres error code of a unique constraint violation
+const pgUniqueViolation = "23505"
+
+// GetFloors retrieves all floors ordered by floor number
+func (r impl) GetFloors(ctx context.Context) ([]model.Floor, error) {
+	rows, err := dbmodel.Floors(qm.OrderBy(dbmodel.FloorColumns.FloorNumber)).All(ctx, r.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query floors: %w", err)
+	}
+
+	return toModelFloors(rows), nil
+}
+
+// GetFloor retrieves a floor by id
+func (r impl) GetFloor(ctx context.Context, floorID int) (model.Floor, error) {
+	row, err := dbmodel.FindFloor(ctx, r.dbConn, floorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Floor{}, ErrNotFound
+		}
+		return model.Floor{}, fmt.Errorf("failed to query floor %d: %w", floorID, err)
+	}
+
+	return toModelFloor(row), nil
+}
+
+// CreateFloor inserts a floor, the floor number must be unique
+func (r impl) CreateFloor(ctx context.Context, floor model.Floor) (model.Floor, error) {
+	row := dbmodel.Floor{
+		FloorNumber: floor.FloorNumber,
+		Description: null.StringFrom(floor.Description),
+		TotalArea:   null.Float64From(floor.TotalArea),
+	}
+
+	if err := row.Insert(ctx, r.dbConn, boil.Infer()); err != nil {
+		if isUniqueViolation(err) {
+			return model.Floor{}, ErrAlreadyExists
+		}
+		return model.Floor{}, fmt.Errorf("failed to insert floor %d: %w", floor.FloorNumber, err)
+	}
+
+	return toModelFloor(&row), nil
+}
+
+// UpdateFloor overwrites the number, description and area of a floor
+func (r impl) UpdateFloor(ctx context.Context, floor model.Floor) (model.Floor, error) {
+	row, err := dbmodel.FindFloor(ctx, r.dbConn, floor.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Floor{}, ErrNotFound
+		}
+		return model.Floor{}, fmt.Errorf("failed to query floor %d: %w", floor.ID, err)
+	}
+
+	row.FloorNumber = floor.FloorNumber
+	row.Description = null.StringFrom(floor.Description)
+	row.TotalArea = null.Float64From(floor.TotalArea)
+
+	if _, err := row.Update(ctx, r.dbConn, boil.Whitelist(
+		dbmodel.FloorColumns.FloorNumber,
+		dbmodel.FloorColumns.Description,
+		dbmodel.FloorColumns.TotalArea,
+		dbmodel.FloorColumns.UpdatedAt,
+	)); err != nil {
+		if isUniqueViolation(err) {
+			return model.Floor{}, ErrAlreadyExists
+		}
+		return model.Floor{}, fmt.Errorf("failed to update floor %d: %w", floor.ID, err)
+	}
+
+	return toModelFloor(row), nil
+}
+
+// GetFloorDevices retrieves all devices of a floor, active or not
+func (r impl) GetFloorDevices(ctx context.Context, floorID int) ([]model.IoTDevice, error) {
+	rows, err := dbmodel.IotDevices(
+		dbmodel.IotDeviceWhere.FloorID.EQ(floorID),
+		qm.OrderBy(dbmodel.IotDeviceColumns.DeviceID),
+	).All(ctx, r.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query devices of floor %d: %w", floorID, err)
+	}
+
+	return toModelDevices(rows), nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation
+}
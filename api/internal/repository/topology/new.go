@@ -0,0 +1,32 @@
+package topology
+
+import (
+	"context"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+)
+
+// Repository provides the specification of the building topology (floors and zones) storage
+type Repository interface {
+	GetFloors(ctx context.Context) ([]model.Floor, error)
+	GetFloor(ctx context.Context, floorID int) (model.Floor, error)
+	CreateFloor(ctx context.Context, floor model.Floor) (model.Floor, error)
+	UpdateFloor(ctx context.Context, floor model.Floor) (model.Floor, error)
+	GetZones(ctx context.Context, floorID int) ([]model.Zone, error)
+	GetZone(ctx context.Context, floorID, zoneID int) (model.Zone, error)
+	CreateZone(ctx context.Context, zone model.Zone) (model.Zone, error)
+	UpdateZone(ctx context.Context, zone model.Zone) (model.Zone, error)
+	GetFloorDevices(ctx context.Context, floorID int) ([]model.IoTDevice, error)
+}
+
+// New returns an implementation instance satisfying Repository
+func New(dbConn boil.ContextExecutor) Repository {
+	return impl{
+		dbConn: dbConn,
+	}
+}
+
+type impl struct {
+	dbConn boil.ContextExecutor
+}
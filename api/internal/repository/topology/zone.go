@@ -0,0 +1,97 @@
+package topology
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/nhan1603/IoTsystem/api/internal/model"
+	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
+)
+
+// GetZones retrieves the zones of a floor
+func (r impl) GetZones(ctx context.Context, floorID int) ([]model.Zone, error) {
+	rows, err := dbmodel.Zones(
+		dbmodel.ZoneWhere.FloorID.EQ(null.IntFrom(floorID)),
+		qm.OrderBy(dbmodel.ZoneColumns.ID),
+	).All(ctx, r.dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query zones of floor %d: %w", floorID, err)
+	}
+
+	return toModelZones(rows), nil
+}
+
+// GetZone retrieves a zone, it is not found if it does not belong to the floor
+func (r impl) GetZone(ctx context.Context, floorID, zoneID int) (model.Zone, error) {
+	row, err := dbmodel.Zones(
+		dbmodel.ZoneWhere.ID.EQ(zoneID),
+		dbmodel.ZoneWhere.FloorID.EQ(null.IntFrom(floorID)),
+	).One(ctx, r.dbConn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Zone{}, ErrNotFound
+		}
+		return model.Zone{}, fmt.Errorf("failed to query zone %d of floor %d: %w", zoneID, floorID, err)
+	}
+
+	return toModelZone(row), nil
+}
+
+// CreateZone inserts a zone, the zone name must be unique within its floor
+func (r impl) CreateZone(ctx context.Context, zone model.Zone) (model.Zone, error) {
+	row := dbmodel.Zone{
+		FloorID:     null.IntFrom(zone.FloorID),
+		ZoneName:    zone.Name,
+		ZoneType:    zone.Type,
+		Description: null.StringFrom(zone.Description),
+		Area:        null.Float64From(zone.Area),
+	}
+
+	if err := row.Insert(ctx, r.dbConn, boil.Infer()); err != nil {
+		if isUniqueViolation(err) {
+			return model.Zone{}, ErrAlreadyExists
+		}
+		return model.Zone{}, fmt.Errorf("failed to insert zone %s of floor %d: %w", zone.Name, zone.FloorID, err)
+	}
+
+	return toModelZone(&row), nil
+}
+
+// UpdateZone overwrites the name, type, description and area of a zone, zones can't move between floors
+func (r impl) UpdateZone(ctx context.Context, zone model.Zone) (model.Zone, error) {
+	row, err := dbmodel.Zones(
+		dbmodel.ZoneWhere.ID.EQ(zone.ID),
+		dbmodel.ZoneWhere.FloorID.EQ(null.IntFrom(zone.FloorID)),
+	).One(ctx, r.dbConn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Zone{}, ErrNotFound
+		}
+		return model.Zone{}, fmt.Errorf("failed to query zone %d of floor %d: %w", zone.ID, zone.FloorID, err)
+	}
+
+	row.ZoneName = zone.Name
+	row.ZoneType = zone.Type
+	row.Description = null.StringFrom(zone.Description)
+	row.Area = null.Float64From(zone.Area)
+
+	if _, err := row.Update(ctx, r.dbConn, boil.Whitelist(
+		dbmodel.ZoneColumns.ZoneName,
+		dbmodel.ZoneColumns.ZoneType,
+		dbmodel.ZoneColumns.Description,
+		dbmodel.ZoneColumns.Area,
+		dbmodel.ZoneColumns.UpdatedAt,
+	)); err != nil {
+		if isUniqueViolation(err) {
+			return model.Zone{}, ErrAlreadyExists
+		}
+		return model.Zone{}, fmt.Errorf("failed to update zone %d of floor %d: %w", zone.ID, zone.FloorID, err)
+	}
+
+	return toModelZone(row), nil
+}
@@ -16,6 +16,7 @@ func toModelUsers(orms dbmodel.UserSlice) []model.User {
 func toModelUser(user *dbmodel.User) model.User {
 	return model.User{
 		ID:       int64(user.ID),
+		Name:     user.Username,
 		Email:    user.Email,
 		Password: user.PasswordHash,
 	}
@@ -2,14 +2,19 @@ package user
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/lib/pq"
 	"github.com/nhan1603/IoTsystem/api/internal/model"
 	"github.com/nhan1603/IoTsystem/api/internal/repository/dbmodel"
 	pkgerrors "github.com/pkg/errors"
 )
 
+// pgUniqueViolation is the postgres error code of a unique constraint violation
+const pgUniqueViolation = "23505"
+
 // Create create user by input
 func (i impl) Create(ctx context.Context, user *model.User) error {
 	dbUser := dbmodel.User{
@@ -21,8 +26,14 @@ func (i impl) Create(ctx context.Context, user *model.User) error {
 	log.Println("Finish insert with values " + dbUser.Email)
 
 	if err != nil {
-		pkgerrors.WithStack(err)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return ErrAlreadyExists
+		}
+		return pkgerrors.WithStack(err)
 	}
 
+	user.ID = int64(dbUser.ID)
+
 	return nil
 }
@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrNotFound means the item was not found
 	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means a user with the same email already exists
+	ErrAlreadyExists = errors.New("already exists")
 )